@@ -54,6 +54,9 @@ func main() {
 		// Get frame time
 		deltaTime := raylib.GetFrameTime()
 
+		// Deliver the events queued during the last frame, e.g. button clicks
+		ecsManager.GetEventsManager().Flush()
+
 		currentScene := sceneManager.GetCurrentScene()
 
 		if currentScene != nil {
@@ -83,5 +86,9 @@ func main() {
 
 	}
 
+	// Cleanup the remaining scenes
+	sceneManager.Close()
+	EventsListenerSystem.Close()
+
 	// TODO: Unload All Resources
 }
@@ -11,6 +11,9 @@ type AudioSource struct {
 	Sound      raylib.Sound
 	IsLooping  bool
 	ShouldPlay bool
+
+	// PlayOnCollision plays the sound when the entity starts touching another collider
+	PlayOnCollision bool
 }
 
 // Audiolistener represents an audio listener component
@@ -9,8 +9,13 @@ type UIButton struct {
 	Text      string
 	Bounds    raylib.Rectangle
 	IsVisible bool
+
+	// Optional scene event dispatched on click by the EventsListenerSystem, e.g. "push_scene", see events.NewSceneEvent
+	Event     string
+	ScenePath string
 }
 
+// OnClick queues the click with the button's scene event, it is delivered when the events are flushed
 func (b *UIButton) OnClick(em *events.EventsManager) {
-	em.Dispatch("button_clicked", events.ButtonClickEvent{ButtonText: b.Text})
+	em.Queue(events.ButtonClickEvent{ButtonText: b.Text, Event: b.Event, ScenePath: b.ScenePath})
 }
@@ -0,0 +1,20 @@
+package components
+
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Hierarchy links an entity to its parent and children. A Parent of 0 means the entity is a root.
+type Hierarchy struct {
+	Parent   uint64
+	Children []uint64
+}
+
+// LocalTransform2D is the transform of a child relative to its parent.
+// Position is in the space of the parent, so it is rotated and scaled by the parent's Transform2D,
+// and Scale multiplies the parent's scale. The child's Transform2D is derived from it every step.
+type LocalTransform2D struct {
+	Position raylib.Vector2
+	Rotation float32
+	Scale    raylib.Vector2
+}
@@ -0,0 +1,6 @@
+package components
+
+// PrefabInstance records the prefab an entity was instantiated from so saving can keep the link
+type PrefabInstance struct {
+	Name string
+}
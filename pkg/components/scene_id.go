@@ -0,0 +1,6 @@
+package components
+
+// SceneID identifies an entity in a scene file so it keeps the same ID every time the scene is saved
+type SceneID struct {
+	ID uint64
+}
@@ -0,0 +1,7 @@
+package components
+
+// SceneTrigger dispatches a scene event, e.g. "change_scene", when a player enters the trigger collider of the entity
+type SceneTrigger struct {
+	Event     string
+	ScenePath string
+}
@@ -10,6 +10,12 @@ type UIPanel struct {
 	IsVisible bool
 }
 
+// UIOverlay covers the whole screen with a color, e.g. to dim the game behind a menu
+type UIOverlay struct {
+	Color     raylib.Color
+	IsVisible bool
+}
+
 type UILabel struct {
 	Label     string
 	Bounds    raylib.Rectangle
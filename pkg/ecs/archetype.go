@@ -0,0 +1,251 @@
+package ecs
+
+import (
+	"sort"
+)
+
+// maxComponentTypes is the maximum number of component types a ComponentMask can hold
+const maxComponentTypes = 256
+
+// ComponentMask is a bitset describing a set of component types
+type ComponentMask [maxComponentTypes / 64]uint64
+
+// NewComponentMask creates a ComponentMask containing the given component types
+func NewComponentMask(cts ...ComponentType) ComponentMask {
+	var mask ComponentMask
+	for _, ct := range cts {
+		mask.Set(ct)
+	}
+	return mask
+}
+
+// Set adds a component type to the mask
+func (m *ComponentMask) Set(ct ComponentType) {
+	m[ct/64] |= 1 << (ct % 64)
+}
+
+// Unset removes a component type from the mask
+func (m *ComponentMask) Unset(ct ComponentType) {
+	m[ct/64] &^= 1 << (ct % 64)
+}
+
+// Has checks if the mask contains a component type
+func (m ComponentMask) Has(ct ComponentType) bool {
+	return m[ct/64]&(1<<(ct%64)) != 0
+}
+
+// Contains checks if the mask contains every component type of the other mask
+func (m ComponentMask) Contains(other ComponentMask) bool {
+	for i := range m {
+		if m[i]&other[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// column stores the components of a single type, row i belongs to the i-th entity of its archetype
+type column interface {
+	len() int
+	appendEmpty()
+	get(row int) Component
+	set(row int, c Component) bool
+	accepts(c Component) bool
+	copyRow(row int, source column, sourceRow int)
+	swapRemove(row int)
+}
+
+// typedColumn stores components of type T by value in one contiguous slice, so typed queries walk
+// the components in memory order without type assertions or pointer chasing.
+// Adding a component copies it into the column, and the *T handed out by Get and the queries points into it.
+// Such a pointer is only valid until the next structural change, which can move the row or grow the slice.
+// Structural changes are deferred while queries and stages run, see ComponentsManager.
+type typedColumn[T any] struct {
+	data []T
+}
+
+func (c *typedColumn[T]) len() int {
+	return len(c.data)
+}
+
+func (c *typedColumn[T]) appendEmpty() {
+	var zero T
+	c.data = append(c.data, zero)
+}
+
+func (c *typedColumn[T]) get(row int) Component {
+	return &c.data[row]
+}
+
+func (c *typedColumn[T]) set(row int, comp Component) bool {
+	typed, ok := comp.(*T)
+	if !ok || typed == nil {
+		return false
+	}
+
+	c.data[row] = *typed
+	return true
+}
+
+func (c *typedColumn[T]) accepts(comp Component) bool {
+	typed, ok := comp.(*T)
+	return ok && typed != nil
+}
+
+func (c *typedColumn[T]) copyRow(row int, source column, sourceRow int) {
+	c.data[row] = source.(*typedColumn[T]).data[sourceRow]
+}
+
+func (c *typedColumn[T]) swapRemove(row int) {
+	last := len(c.data) - 1
+	c.data[row] = c.data[last]
+
+	// Clear the last row so whatever the component references can be garbage collected
+	var zero T
+	c.data[last] = zero
+	c.data = c.data[:last]
+}
+
+// anyColumn stores components of a type that was not registered with ComponentTypeOf
+type anyColumn struct {
+	data []Component
+}
+
+func (c *anyColumn) len() int {
+	return len(c.data)
+}
+
+func (c *anyColumn) appendEmpty() {
+	c.data = append(c.data, nil)
+}
+
+func (c *anyColumn) get(row int) Component {
+	return c.data[row]
+}
+
+func (c *anyColumn) set(row int, comp Component) bool {
+	c.data[row] = comp
+	return true
+}
+
+func (c *anyColumn) accepts(comp Component) bool {
+	return true
+}
+
+func (c *anyColumn) copyRow(row int, source column, sourceRow int) {
+	c.data[row] = source.get(sourceRow)
+}
+
+func (c *anyColumn) swapRemove(row int) {
+	last := len(c.data) - 1
+	c.data[row] = c.data[last]
+	c.data[last] = nil
+	c.data = c.data[:last]
+}
+
+// columnData returns the components of a typed column, false if the column doesn't hold components of type T
+func columnData[T any](c column) ([]T, bool) {
+	typed, ok := c.(*typedColumn[T])
+	if !ok {
+		return nil, false
+	}
+
+	return typed.data, true
+}
+
+// archetype stores all entities that share the exact same set of component types.
+// Each component type is stored in its own column, and row i of every column
+// belongs to entities[i], so iterating an archetype walks dense slices.
+type archetype struct {
+	mask        ComponentMask
+	types       []ComponentType
+	columnIndex map[ComponentType]int
+	entities    []uint64
+	columns     []column
+
+	// Cached transitions to the archetype reached by adding or removing a component type
+	addEdges    map[ComponentType]*archetypeEdge
+	removeEdges map[ComponentType]*archetypeEdge
+}
+
+// archetypeEdge is a cached transition from one archetype to another.
+// sourceColumns holds, for every column of the target, the column of the source its components
+// are copied from when an entity moves, or -1 for the column of the added type.
+type archetypeEdge struct {
+	target        *archetype
+	sourceColumns []int
+}
+
+// newArchetypeEdge initializes and returns the edge from the source to the target archetype
+func newArchetypeEdge(source *archetype, target *archetype) *archetypeEdge {
+	edge := &archetypeEdge{target: target, sourceColumns: make([]int, len(target.types))}
+
+	for i, ct := range target.types {
+		col, colExists := source.columnIndex[ct]
+		if !colExists {
+			col = -1
+		}
+		edge.sourceColumns[i] = col
+	}
+
+	return edge
+}
+
+// newArchetype initializes and returns a new archetype for the given component types
+func newArchetype(cts []ComponentType) *archetype {
+
+	// Keep the types sorted so the column layout is the same regardless of insertion order
+	types := make([]ComponentType, len(cts))
+	copy(types, cts)
+	sort.Slice(types, func(i int, j int) bool {
+		return types[i] < types[j]
+	})
+
+	a := &archetype{
+		mask:        NewComponentMask(types...),
+		types:       types,
+		columnIndex: make(map[ComponentType]int, len(types)),
+		entities:    []uint64{},
+		columns:     make([]column, len(types)),
+		addEdges:    make(map[ComponentType]*archetypeEdge),
+		removeEdges: make(map[ComponentType]*archetypeEdge),
+	}
+
+	for i, ct := range types {
+		a.columnIndex[ct] = i
+		a.columns[i] = newColumn(ct)
+	}
+
+	return a
+}
+
+// appendEntity adds a row for the entity with empty components and returns the row index
+func (a *archetype) appendEntity(eID uint64) int {
+	a.entities = append(a.entities, eID)
+	for _, col := range a.columns {
+		col.appendEmpty()
+	}
+	return len(a.entities) - 1
+}
+
+// removeRow removes a row by swapping the last row into its place.
+// It returns the entity that was moved into the row, if any.
+func (a *archetype) removeRow(row int) (uint64, bool) {
+	last := len(a.entities) - 1
+
+	moved := false
+	movedEntity := uint64(0)
+
+	if row != last {
+		a.entities[row] = a.entities[last]
+		movedEntity = a.entities[row]
+		moved = true
+	}
+
+	a.entities = a.entities[:last]
+	for _, col := range a.columns {
+		col.swapRemove(row)
+	}
+
+	return movedEntity, moved
+}
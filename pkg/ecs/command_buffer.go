@@ -0,0 +1,159 @@
+package ecs
+
+import (
+	"math"
+	"sync"
+
+	"github.com/webbelito/Fenrir/pkg/utils"
+)
+
+// commandKind identifies the structural change recorded by a command
+type commandKind int
+
+const (
+	createEntityCommand commandKind = iota
+	destroyEntityCommand
+	addComponentCommand
+	removeComponentCommand
+	addUIComponentCommand
+	addCodecComponentCommand
+	setParentCommand
+)
+
+// command is a single structural change recorded in a CommandBuffer
+type command struct {
+	kind            commandKind
+	entity          *Entity
+	componentType   ComponentType
+	component       Component
+	uiComponentType UIComponentType
+	uiComponent     UIComponent
+	codec           *ComponentCodec
+	parent          uint64
+}
+
+// Entities created through a CommandBuffer get a provisional ID with this generation until the buffer is flushed.
+// The real ID is only reserved at the flush, so IDs don't depend on the order systems running in parallel record in.
+const provisionalGeneration = math.MaxUint32
+
+// CommandBuffer records structural changes to the world so they can be applied later.
+// Systems use it to create and destroy entities or add and remove components while
+// iterating queries, and the SystemsManager flushes it after every stage.
+// Every logic system has its own buffer, see SystemsManager.GetCommandBuffer.
+type CommandBuffer struct {
+	ecsManager       *ECSManager
+	commands         []command
+	provisionalCount uint32
+	bufferMutex      sync.Mutex
+}
+
+// NewCommandBuffer initializes and returns a new CommandBuffer for the ECSManager
+func NewCommandBuffer(ecsM *ECSManager) *CommandBuffer {
+	return &CommandBuffer{
+		ecsManager: ecsM,
+		commands:   []command{},
+	}
+}
+
+// CreateEntity queues the creation of an entity and returns it with a provisional ID.
+// The provisional ID can be used in commands recorded in the same buffer, e.g. to add components or set it as a parent.
+// The flush replaces it with the real ID, also in the returned entity.
+func (cb *CommandBuffer) CreateEntity() *Entity {
+	cb.bufferMutex.Lock()
+	defer cb.bufferMutex.Unlock()
+
+	// Provisional indices start at 1 so a provisional ID is never 0
+	cb.provisionalCount++
+	entity := &Entity{ID: NewEntityID(cb.provisionalCount, provisionalGeneration)}
+
+	cb.commands = append(cb.commands, command{kind: createEntityCommand, entity: entity})
+
+	return entity
+}
+
+// DestroyEntity queues the destruction of an entity and all of its components
+func (cb *CommandBuffer) DestroyEntity(eID uint64) {
+	cb.record(command{kind: destroyEntityCommand, entity: &Entity{ID: eID}})
+}
+
+// AddComponent queues adding a component to an entity
+func (cb *CommandBuffer) AddComponent(eID uint64, ct ComponentType, c Component) {
+	cb.record(command{kind: addComponentCommand, entity: &Entity{ID: eID}, componentType: ct, component: c})
+}
+
+// RemoveComponent queues removing a component from an entity
+func (cb *CommandBuffer) RemoveComponent(eID uint64, ct ComponentType) {
+	cb.record(command{kind: removeComponentCommand, entity: &Entity{ID: eID}, componentType: ct})
+}
+
+// AddUIComponent queues adding a UI component to an entity
+func (cb *CommandBuffer) AddUIComponent(eID uint64, ct UIComponentType, c UIComponent) {
+	cb.record(command{kind: addUIComponentCommand, entity: &Entity{ID: eID}, uiComponentType: ct, uiComponent: c})
+}
+
+// SetParent queues attaching an entity to a parent, see ECSManager.SetParent
+func (cb *CommandBuffer) SetParent(eID uint64, parent uint64) {
+	cb.record(command{kind: setParentCommand, entity: &Entity{ID: eID}, parent: parent})
+}
+
+// Len returns the number of queued commands
+func (cb *CommandBuffer) Len() int {
+	cb.bufferMutex.Lock()
+	defer cb.bufferMutex.Unlock()
+
+	return len(cb.commands)
+}
+
+// Flush applies all queued commands in the order they were recorded and empties the buffer.
+// Entities are created in the same order, so the IDs they get only depend on the order buffers are flushed in.
+// Commands recorded while flushing are kept for the next flush.
+func (cb *CommandBuffer) Flush() {
+	cb.bufferMutex.Lock()
+	commands := cb.commands
+	cb.commands = make([]command, 0, len(commands))
+	cb.bufferMutex.Unlock()
+
+	// Real IDs of the entities created by this flush, by their provisional ID
+	created := make(map[uint64]uint64)
+
+	resolve := func(eID uint64) uint64 {
+		if realID, isCreated := created[eID]; isCreated {
+			return realID
+		}
+		return eID
+	}
+
+	for _, cmd := range commands {
+		eID := resolve(cmd.entity.ID)
+
+		switch cmd.kind {
+		case createEntityCommand:
+			reserved := cb.ecsManager.entitiesManager.reserveEntity()
+			created[cmd.entity.ID] = reserved.ID
+			cmd.entity.ID = reserved.ID
+			cb.ecsManager.entitiesManager.activateEntity(cmd.entity)
+		case destroyEntityCommand:
+			cb.ecsManager.DestroyEntity(eID)
+		case addComponentCommand:
+			cb.ecsManager.AddComponent(eID, cmd.componentType, cmd.component)
+		case removeComponentCommand:
+			cb.ecsManager.RemoveComponent(eID, cmd.componentType)
+		case addUIComponentCommand:
+			cb.ecsManager.AddUIComponent(eID, cmd.uiComponentType, cmd.uiComponent)
+		case addCodecComponentCommand:
+			cmd.codec.add(cb.ecsManager, eID, cmd.component)
+		case setParentCommand:
+			if err := cb.ecsManager.SetParent(eID, resolve(cmd.parent)); err != nil {
+				utils.ErrorLogger.Printf("CommandBuffer: %v", err)
+			}
+		}
+	}
+}
+
+// record appends a command to the buffer
+func (cb *CommandBuffer) record(cmd command) {
+	cb.bufferMutex.Lock()
+	defer cb.bufferMutex.Unlock()
+
+	cb.commands = append(cb.commands, cmd)
+}
@@ -0,0 +1,100 @@
+package ecs
+
+import (
+	"testing"
+)
+
+// Entities created through a CommandBuffer get a provisional ID, which the flush replaces with
+// the real ID everywhere: in the returned entity and in the commands recorded with it
+func TestFlushReplacesProvisionalIDs(t *testing.T) {
+	em := NewECSManager()
+	commandBuffer := NewCommandBuffer(em)
+
+	parent := commandBuffer.CreateEntity()
+	child := commandBuffer.CreateEntity()
+	provisionalParent, provisionalChild := parent.ID, child.ID
+
+	for _, eID := range []uint64{provisionalParent, provisionalChild} {
+		if EntityGeneration(eID) != provisionalGeneration {
+			t.Fatalf("entity %d has generation %d, want the provisional generation", eID, EntityGeneration(eID))
+		}
+		if em.IsAlive(eID) {
+			t.Fatalf("provisional entity %d is alive before the flush", eID)
+		}
+	}
+
+	AddDeferred(commandBuffer, parent.ID, &benchPosition{X: 1})
+	commandBuffer.SetParent(child.ID, parent.ID)
+	commandBuffer.Flush()
+
+	for _, eID := range []uint64{parent.ID, child.ID} {
+		if EntityGeneration(eID) == provisionalGeneration {
+			t.Fatalf("entity %d kept its provisional ID", eID)
+		}
+		if !em.IsAlive(eID) {
+			t.Fatalf("entity %d is not alive after the flush", eID)
+		}
+	}
+
+	// Entities are created in the order they were recorded
+	if EntityIndex(parent.ID) >= EntityIndex(child.ID) {
+		t.Fatalf("parent index %d, child index %d, want the parent created first", EntityIndex(parent.ID), EntityIndex(child.ID))
+	}
+
+	if position, exists := Get[benchPosition](em, parent.ID); !exists || position.X != 1 {
+		t.Fatalf("parent position = %v, %v, want the deferred component", position, exists)
+	}
+	if got, hasParent := em.GetParent(child.ID); !hasParent || got != parent.ID {
+		t.Fatalf("child parent = %d, %v, want %d", got, hasParent, parent.ID)
+	}
+
+	// The provisional IDs never refer to an entity
+	if em.IsAlive(provisionalParent) || em.IsAlive(provisionalChild) {
+		t.Fatal("a provisional ID is alive after the flush")
+	}
+}
+
+// Commands touching the same entity are applied in the order they were recorded
+func TestFlushAppliesCommandsInOrder(t *testing.T) {
+	em := NewECSManager()
+	commandBuffer := NewCommandBuffer(em)
+
+	replaced := em.CreateEntity().ID
+	AddDeferred(commandBuffer, replaced, &benchPosition{X: 1})
+	RemoveDeferred[benchPosition](commandBuffer, replaced)
+	AddDeferred(commandBuffer, replaced, &benchPosition{X: 2})
+
+	removed := em.CreateEntity().ID
+	AddDeferred(commandBuffer, removed, &benchPosition{X: 1})
+	RemoveDeferred[benchPosition](commandBuffer, removed)
+
+	destroyed := em.CreateEntity().ID
+	AddDeferred(commandBuffer, destroyed, &benchPosition{X: 1})
+	commandBuffer.DestroyEntity(destroyed)
+	AddDeferred(commandBuffer, destroyed, &benchVelocity{X: 1})
+
+	if commandBuffer.Len() != 8 {
+		t.Fatalf("buffer holds %d commands, want 8", commandBuffer.Len())
+	}
+	if Has[benchPosition](em, replaced) {
+		t.Fatal("a command was applied before the flush")
+	}
+
+	commandBuffer.Flush()
+
+	if commandBuffer.Len() != 0 {
+		t.Fatalf("buffer holds %d commands after the flush, want none", commandBuffer.Len())
+	}
+	if position, exists := Get[benchPosition](em, replaced); !exists || position.X != 2 {
+		t.Fatalf("replaced position = %v, %v, want the last one added", position, exists)
+	}
+	if Has[benchPosition](em, removed) {
+		t.Fatal("removed position is still there")
+	}
+	if em.IsAlive(destroyed) {
+		t.Fatal("destroyed entity is still alive")
+	}
+	if _, exists := em.GetComponentsManager().GetComponent(destroyed, ComponentTypeOf[benchVelocity]()); exists {
+		t.Fatal("a component was added to the destroyed entity")
+	}
+}
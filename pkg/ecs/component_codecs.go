@@ -0,0 +1,240 @@
+package ecs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/utils"
+)
+
+// ErrUnknownComponent is returned when a component name has not been registered
+var ErrUnknownComponent = errors.New("unknown component")
+
+// ComponentCodec converts a registered component to and from its scene JSON representation
+type ComponentCodec struct {
+	Name string
+	IsUI bool
+
+	decode func(em *ECSManager, data json.RawMessage) (Component, error)
+	encode func(em *ECSManager, c Component) (json.RawMessage, error)
+	add    func(em *ECSManager, eID uint64, c Component)
+	get    func(em *ECSManager, eID uint64) (Component, bool)
+}
+
+// codecRegistry holds the registered component codecs by name
+type codecRegistry struct {
+	codecs     map[string]*ComponentCodec
+	names      []string
+	codecMutex sync.RWMutex
+}
+
+var componentCodecs = &codecRegistry{codecs: make(map[string]*ComponentCodec)}
+
+// RegisterComponent registers the component T under a name used in scene JSON.
+// D is the JSON representation of T, decode and encode convert between the two.
+// Registering a name twice replaces the previous codec.
+func RegisterComponent[T any, D any](name string, decode func(data *D) *T, encode func(c *T) *D) {
+	RegisterWorldComponent(name,
+		func(em *ECSManager, d *D) (*T, error) { return decode(d), nil },
+		func(em *ECSManager, c *T) *D { return encode(c) },
+	)
+}
+
+// RegisterWorldComponent registers the component T like RegisterComponent, for components whose JSON representation
+// depends on the world they are decoded into, e.g. colliders naming the collision layers of the scene.
+// decode can reject invalid data with an error.
+func RegisterWorldComponent[T any, D any](name string, decode func(em *ECSManager, data *D) (*T, error), encode func(em *ECSManager, c *T) *D) {
+	componentCodecs.register(newComponentCodec(name, false, decode, encode,
+		func(em *ECSManager, eID uint64, c *T) { Add(em, eID, c) },
+		func(em *ECSManager, eID uint64) (*T, bool) { return Get[T](em, eID) },
+	))
+}
+
+// RegisterUIComponent registers the UI component T under a name used in scene JSON.
+// D is the JSON representation of T, decode and encode convert between the two.
+func RegisterUIComponent[T any, D any](name string, decode func(data *D) *T, encode func(c *T) *D) {
+	componentCodecs.register(newComponentCodec(name, true,
+		func(em *ECSManager, d *D) (*T, error) { return decode(d), nil },
+		func(em *ECSManager, c *T) *D { return encode(c) },
+		func(em *ECSManager, eID uint64, c *T) { AddUI(em, eID, c) },
+		func(em *ECSManager, eID uint64) (*T, bool) { return GetUI[T](em, eID) },
+	))
+}
+
+// newComponentCodec wraps the typed conversion functions of T into a ComponentCodec
+func newComponentCodec[T any, D any](
+	name string,
+	isUI bool,
+	decode func(em *ECSManager, data *D) (*T, error),
+	encode func(em *ECSManager, c *T) *D,
+	add func(em *ECSManager, eID uint64, c *T),
+	get func(em *ECSManager, eID uint64) (*T, bool),
+) *ComponentCodec {
+	return &ComponentCodec{
+		Name: name,
+		IsUI: isUI,
+		decode: func(em *ECSManager, data json.RawMessage) (Component, error) {
+			var d D
+			if err := json.Unmarshal(data, &d); err != nil {
+				return nil, err
+			}
+
+			c, err := decode(em, &d)
+			if err != nil {
+				return nil, err
+			}
+
+			return c, nil
+		},
+		encode: func(em *ECSManager, c Component) (json.RawMessage, error) {
+			typed, isType := c.(*T)
+			if !isType {
+				return nil, fmt.Errorf("component %s: expected %T, got %T", name, typed, c)
+			}
+
+			return json.Marshal(encode(em, typed))
+		},
+		add: func(em *ECSManager, eID uint64, c Component) {
+			add(em, eID, c.(*T))
+		},
+		get: func(em *ECSManager, eID uint64) (Component, bool) {
+			c, cExists := get(em, eID)
+			if !cExists {
+				return nil, false
+			}
+
+			return c, true
+		},
+	}
+}
+
+// register adds a codec to the registry, replacing any codec with the same name
+func (cr *codecRegistry) register(codec *ComponentCodec) {
+	cr.codecMutex.Lock()
+	defer cr.codecMutex.Unlock()
+
+	if _, codecExists := cr.codecs[codec.Name]; codecExists {
+		utils.WarnLogger.Printf("RegisterComponent: replacing the codec registered as %s", codec.Name)
+	} else {
+		cr.names = append(cr.names, codec.Name)
+	}
+
+	cr.codecs[codec.Name] = codec
+}
+
+// GetComponentCodec returns the codec registered under a name
+func GetComponentCodec(name string) (*ComponentCodec, bool) {
+	componentCodecs.codecMutex.RLock()
+	defer componentCodecs.codecMutex.RUnlock()
+
+	codec, codecExists := componentCodecs.codecs[name]
+	return codec, codecExists
+}
+
+// GetComponentCodecs returns all registered codecs in registration order
+func GetComponentCodecs() []*ComponentCodec {
+	componentCodecs.codecMutex.RLock()
+	defer componentCodecs.codecMutex.RUnlock()
+
+	codecs := make([]*ComponentCodec, 0, len(componentCodecs.names))
+	for _, name := range componentCodecs.names {
+		codecs = append(codecs, componentCodecs.codecs[name])
+	}
+
+	return codecs
+}
+
+// Decode converts the JSON representation of the component into a new component for the world of em
+func (cc *ComponentCodec) Decode(em *ECSManager, data json.RawMessage) (Component, error) {
+	return cc.decode(em, data)
+}
+
+// Encode converts a component of the world of em into its JSON representation
+func (cc *ComponentCodec) Encode(em *ECSManager, c Component) (json.RawMessage, error) {
+	return cc.encode(em, c)
+}
+
+// AddComponentFromJSON decodes a component registered under name and adds it to an entity.
+// It returns ErrUnknownComponent if nothing is registered under name.
+func (em *ECSManager) AddComponentFromJSON(eID uint64, name string, data json.RawMessage) error {
+	codec, codecExists := GetComponentCodec(name)
+	if !codecExists {
+		return fmt.Errorf("%w: %s", ErrUnknownComponent, name)
+	}
+
+	c, err := codec.Decode(em, data)
+	if err != nil {
+		return err
+	}
+
+	codec.add(em, eID, c)
+
+	return nil
+}
+
+// EncodeComponents encodes every registered component of an entity, keyed by the name it was registered with
+func (em *ECSManager) EncodeComponents(eID uint64) (map[string]json.RawMessage, error) {
+	encoded := make(map[string]json.RawMessage)
+
+	for _, codec := range GetComponentCodecs() {
+		c, cExists := codec.get(em, eID)
+		if !cExists {
+			continue
+		}
+
+		data, err := codec.Encode(em, c)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded[codec.Name] = data
+	}
+
+	return encoded, nil
+}
+
+// * JSON representations of common raylib types
+
+// Vector2Data is the JSON representation of a raylib.Vector2
+type Vector2Data struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// RectangleData is the JSON representation of a raylib.Rectangle
+type RectangleData struct {
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+}
+
+// ColorData is the JSON representation of a raylib.Color, either a color name or #RRGGBBAA
+type ColorData string
+
+func NewVector2Data(v raylib.Vector2) Vector2Data {
+	return Vector2Data{X: v.X, Y: v.Y}
+}
+
+func (vd Vector2Data) Vector2() raylib.Vector2 {
+	return raylib.NewVector2(vd.X, vd.Y)
+}
+
+func NewRectangleData(r raylib.Rectangle) RectangleData {
+	return RectangleData{X: r.X, Y: r.Y, Width: r.Width, Height: r.Height}
+}
+
+func (rd RectangleData) Rectangle() raylib.Rectangle {
+	return raylib.NewRectangle(rd.X, rd.Y, rd.Width, rd.Height)
+}
+
+func NewColorData(c raylib.Color) ColorData {
+	return ColorData(utils.GetStringFromColor(c))
+}
+
+func (cd ColorData) Color() raylib.Color {
+	return utils.GetColorFromString(string(cd))
+}
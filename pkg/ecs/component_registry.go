@@ -0,0 +1,114 @@
+package ecs
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/webbelito/Fenrir/pkg/utils"
+)
+
+// typeRegistry assigns a stable numeric ID to every Go type registered with it.
+// Typed component access looks up the ID of its type on every call, so the IDs are kept in a map
+// that is replaced instead of modified when a type is registered, and read with a single atomic load.
+type typeRegistry struct {
+	types         atomic.Pointer[map[reflect.Type]uint64]
+	nextType      uint64
+	registryMutex sync.Mutex
+}
+
+var (
+	componentTypeRegistry   = newTypeRegistry()
+	uiComponentTypeRegistry = newTypeRegistry()
+)
+
+// newTypeRegistry initializes and returns an empty typeRegistry
+func newTypeRegistry() *typeRegistry {
+	tr := &typeRegistry{}
+	tr.types.Store(&map[reflect.Type]uint64{})
+	return tr
+}
+
+// typeID returns the ID registered for a Go type, registering it if needed.
+// onRegister, if set, is called with the ID while the type is registered.
+func (tr *typeRegistry) typeID(t reflect.Type, onRegister func(id uint64)) uint64 {
+	if id, idExists := (*tr.types.Load())[t]; idExists {
+		return id
+	}
+
+	tr.registryMutex.Lock()
+	defer tr.registryMutex.Unlock()
+
+	// Another goroutine may have registered the type while we waited for the lock
+	types := *tr.types.Load()
+	if id, idExists := types[t]; idExists {
+		return id
+	}
+
+	id := tr.nextType
+	tr.nextType++
+
+	if onRegister != nil {
+		onRegister(id)
+	}
+
+	registered := maps.Clone(types)
+	registered[t] = id
+	tr.types.Store(&registered)
+
+	return id
+}
+
+// columnRegistry creates the typed column of every component type registered with ComponentTypeOf
+type columnRegistry struct {
+	factories     map[ComponentType]func() column
+	registryMutex sync.RWMutex
+}
+
+var componentColumns = &columnRegistry{factories: make(map[ComponentType]func() column)}
+
+// register sets the column factory of a component type
+func (cr *columnRegistry) register(ct ComponentType, factory func() column) {
+	cr.registryMutex.Lock()
+	defer cr.registryMutex.Unlock()
+
+	cr.factories[ct] = factory
+}
+
+// newColumn returns an empty column for a component type.
+// Types not registered with ComponentTypeOf get an untyped column.
+func newColumn(ct ComponentType) column {
+	componentColumns.registryMutex.RLock()
+	factory, factoryExists := componentColumns.factories[ct]
+	componentColumns.registryMutex.RUnlock()
+
+	if !factoryExists {
+		return &anyColumn{}
+	}
+
+	return factory()
+}
+
+// ComponentTypeOf returns the ComponentType of the component struct T.
+// The type is registered the first time it is requested. Components of type T are stored by value
+// in a typed column and handed out as *T.
+func ComponentTypeOf[T any]() ComponentType {
+	ct := ComponentType(componentTypeRegistry.typeID(reflect.TypeFor[T](), func(id uint64) {
+		componentColumns.register(ComponentType(id), func() column {
+			return &typedColumn[T]{}
+		})
+	}))
+
+	if ct >= maxComponentTypes {
+		utils.ErrorLogger.Printf("ComponentTypeOf: %s exceeds the maximum of %d component types", reflect.TypeFor[T](), maxComponentTypes)
+	}
+
+	return ct
+}
+
+// UIComponentTypeOf returns the UIComponentType of the UI component struct T.
+// The type is registered the first time it is requested. UI components of type T are stored as *T.
+func UIComponentTypeOf[T any]() UIComponentType {
+	return UIComponentType(uiComponentTypeRegistry.typeID(reflect.TypeFor[T](), nil))
+}
@@ -2,6 +2,8 @@ package ecs
 
 import (
 	"sync"
+
+	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
 // ComponentType represent the type of a component
@@ -10,33 +12,106 @@ type ComponentType uint64
 // Component is the interface that all components should implement
 type Component interface{}
 
-// ComponentsManager manages all components associated with entities
-type ComponentsManager struct {
+// entityRecord points to the archetype row holding an entity's components
+type entityRecord struct {
+	id        uint64
+	archetype *archetype
+	row       int
+}
 
-	// TODO: Rename Components to components, ensure that the map is private
-	Components map[ComponentType]map[uint64]Component
-	compMutex  sync.RWMutex
+// pendingChange is a structural change requested while a query or stage was running
+type pendingChange struct {
+	kind          commandKind
+	eID           uint64
+	componentType ComponentType
+	component     Component
+}
+
+// ComponentsManager manages all components associated with entities.
+// Components are stored in archetypes, grouping entities with the same set of components together.
+//
+// Queries walk the archetype columns in place, so adding or removing components while a query
+// or a stage of logic systems is running would move rows under the iteration. Such changes are
+// deferred instead and applied, in the order they were made, once the last running query or stage ends.
+type ComponentsManager struct {
+	root           *archetype
+	archetypes     []*archetype
+	archetypeIndex map[ComponentMask]*archetype
+	records        []entityRecord
+	iterations     int
+	pending        []pendingChange
+	compMutex      sync.RWMutex
 }
 
 // NewComponentsManager initliazes and returns a new ComponentsManager
 func NewComponentsManager() *ComponentsManager {
+	root := newArchetype(nil)
+
 	return &ComponentsManager{
-		Components: make(map[ComponentType]map[uint64]Component),
+		root:           root,
+		archetypes:     []*archetype{root},
+		archetypeIndex: map[ComponentMask]*archetype{root.mask: root},
+		records:        []entityRecord{},
 	}
 }
 
-// AddComponent adds a component to a specific entity.
-// It initializes the compopnent map for the type if it doesn't exist.
+// AddComponent adds a copy of a component to a specific entity.
+// If the entity already has a component of the type it is replaced,
+// otherwise the entity is moved to the archetype that includes the type.
+// While a query or stage is running the change is deferred until it ends.
 func (cm *ComponentsManager) AddComponent(eID uint64, ct ComponentType, c Component) {
 
+	if ct >= maxComponentTypes {
+		utils.ErrorLogger.Printf("ComponentsManager: component type %d exceeds the maximum of %d", ct, maxComponentTypes)
+		return
+	}
+
 	cm.compMutex.Lock()
 	defer cm.compMutex.Unlock()
 
-	if _, exists := cm.Components[ct]; !exists {
-		cm.Components[ct] = make(map[uint64]Component)
+	if cm.iterations > 0 {
+		cm.pending = append(cm.pending, pendingChange{kind: addComponentCommand, eID: eID, componentType: ct, component: c})
+		return
+	}
+
+	cm.addComponent(eID, ct, c)
+}
+
+// addComponent adds a component to an entity. The caller must hold the compMutex write lock.
+func (cm *ComponentsManager) addComponent(eID uint64, ct ComponentType, c Component) {
+	record, recordExists := cm.getRecord(eID)
+
+	source := cm.root
+	if recordExists {
+		source = record.archetype
+	}
+
+	var edge *archetypeEdge
+	target := source
+	if !source.mask.Has(ct) {
+		edge = cm.getAddEdge(source, ct)
+		target = edge.target
+	}
+
+	// Typed columns only hold components of their own type
+	col := target.columns[target.columnIndex[ct]]
+	if !col.accepts(c) {
+		utils.ErrorLogger.Printf("ComponentsManager: %T is not a component of type %d", c, ct)
+		return
+	}
+
+	if !recordExists {
+		record = entityRecord{id: eID, archetype: cm.root, row: cm.root.appendEntity(eID)}
+	}
+
+	// Replace the component in place if the entity already has the type
+	if edge == nil {
+		col.set(record.row, c)
+		return
 	}
 
-	cm.Components[ct][eID] = c
+	row := cm.moveEntity(eID, record, edge)
+	col.set(row, c)
 }
 
 // GetComponent retrieves a component of a specific type for a given entity.
@@ -46,15 +121,17 @@ func (cm *ComponentsManager) GetComponent(eID uint64, ct ComponentType) (Compone
 	cm.compMutex.RLock()
 	defer cm.compMutex.RUnlock()
 
-	comps, compsExists := cm.Components[ct]
-
-	if !compsExists {
+	record, recordExists := cm.getRecord(eID)
+	if !recordExists {
 		return nil, false
 	}
 
-	comp, compExists := comps[eID]
-	return comp, compExists
+	col, colExists := record.archetype.columnIndex[ct]
+	if !colExists {
+		return nil, false
+	}
 
+	return record.archetype.columns[col].get(record.row), true
 }
 
 // GetComponentsOfType retrieves all components of a specific type.
@@ -63,15 +140,27 @@ func (cm *ComponentsManager) GetComponentsOfType(ct ComponentType) (map[uint64]C
 	cm.compMutex.RLock()
 	defer cm.compMutex.RUnlock()
 
-	comps, compsExists := cm.Components[ct]
+	comps := make(map[uint64]Component)
 
-	if !compsExists {
+	for _, a := range cm.archetypes {
+		col, colExists := a.columnIndex[ct]
+		if !colExists {
+			continue
+		}
+
+		for row, eID := range a.entities {
+			comps[eID] = a.columns[col].get(row)
+		}
+	}
+
+	if len(comps) == 0 {
 		return nil, false
 	}
 
-	return comps, compsExists
+	return comps, true
 }
 
+// GetEntitiesWithComponents retrieves all entity IDs that have all the specified component types.
 func (cm *ComponentsManager) GetEntitiesWithComponents(cts []ComponentType) []uint64 {
 	cm.compMutex.RLock()
 	defer cm.compMutex.RUnlock()
@@ -80,60 +169,303 @@ func (cm *ComponentsManager) GetEntitiesWithComponents(cts []ComponentType) []ui
 		return nil
 	}
 
-	// Initialize with the smallest component type set to optimize the search.
-	minIndex := 0
-	minCount := len(cm.Components[cts[0]])
+	mask := NewComponentMask(cts...)
 
-	for i, ct := range cts {
-		if comps, exists := cm.Components[ct]; exists {
-			if len(comps) < minCount {
-				minCount = len(comps)
-				minIndex = i
-			}
-		} else {
-			// If a component type is not found, return nil
-			return nil
+	// Count the matching entities first to allocate the slice once
+	count := 0
+	for _, a := range cm.archetypes {
+		if a.mask.Contains(mask) {
+			count += len(a.entities)
 		}
 	}
 
-	// Start with entities having the least common component
-	smallestComps := cm.Components[cts[minIndex]]
-	entities := make([]uint64, 0, minCount)
+	if count == 0 {
+		return nil
+	}
+
+	entities := make([]uint64, 0, count)
 
-	for entity := range smallestComps {
-		hasAll := true
+	for _, a := range cm.archetypes {
+		if a.mask.Contains(mask) {
+			entities = append(entities, a.entities...)
+		}
+	}
+
+	return entities
+}
+
+// Query calls fn for every entity that has all the specified component types.
+// The components are passed in the same order as cts, and the slice is reused between calls.
+// Components added or removed by fn are only added or removed once the query ends.
+func (cm *ComponentsManager) Query(cts []ComponentType, fn func(eID uint64, comps []Component)) {
+	cm.query(cts, func(eID uint64, comps []Component) bool {
+		fn(eID, comps)
+		return true
+	})
+}
+
+// archetypeView is the rows of an archetype matched by a query
+type archetypeView struct {
+	entities []uint64
+	columns  []column
+}
+
+// matchArchetypes returns a view of every non-empty archetype with all of cts, with the columns in the order of cts.
+// The views are read after the lock is released, so the callbacks of a query can call GetComponent
+// without locking the manager again, which deadlocks a RWMutex as soon as a writer is waiting.
+// The caller must have called beginIteration, so no structural change touches the views while they are read.
+func (cm *ComponentsManager) matchArchetypes(cts []ComponentType) []archetypeView {
+	cm.compMutex.RLock()
+	defer cm.compMutex.RUnlock()
+
+	if len(cts) == 0 {
+		return nil
+	}
+
+	mask := NewComponentMask(cts...)
+	views := []archetypeView{}
+
+	for _, a := range cm.archetypes {
+		if !a.mask.Contains(mask) || len(a.entities) == 0 {
+			continue
+		}
+
+		view := archetypeView{entities: a.entities, columns: make([]column, len(cts))}
 		for i, ct := range cts {
-			if i == minIndex {
-				continue
+			view.columns[i] = a.columns[a.columnIndex[ct]]
+		}
+
+		views = append(views, view)
+	}
+
+	return views
+}
+
+// query walks the archetypes matching cts and stops as soon as fn returns false
+func (cm *ComponentsManager) query(cts []ComponentType, fn func(eID uint64, comps []Component) bool) {
+	cm.beginIteration()
+	defer cm.endIteration()
+
+	comps := make([]Component, len(cts))
+
+	for _, view := range cm.matchArchetypes(cts) {
+		for row, eID := range view.entities {
+			for i, col := range view.columns {
+				comps[i] = col.get(row)
 			}
-			if _, exists := cm.Components[ct][entity]; !exists {
-				hasAll = false
-				break
+			if !fn(eID, comps) {
+				return
 			}
 		}
-		if hasAll {
-			entities = append(entities, entity)
+	}
+}
+
+// queryColumns calls fn with the entities and the columns of cts of every archetype matching cts,
+// typed queries read the columns directly. It stops as soon as fn returns false.
+func (cm *ComponentsManager) queryColumns(cts []ComponentType, fn func(entities []uint64, cols []column) bool) {
+	cm.beginIteration()
+	defer cm.endIteration()
+
+	for _, view := range cm.matchArchetypes(cts) {
+		if !fn(view.entities, view.columns) {
+			return
 		}
 	}
+}
 
-	return entities
+// deferStructuralChanges runs fn, deferring the structural changes made while it runs until it returns.
+// The SystemsManager runs every stage of logic systems with it.
+func (cm *ComponentsManager) deferStructuralChanges(fn func()) {
+	cm.beginIteration()
+	defer cm.endIteration()
 
+	fn()
 }
 
+// beginIteration marks a query or stage as running, structural changes are deferred until it ends
+func (cm *ComponentsManager) beginIteration() {
+	cm.compMutex.Lock()
+	defer cm.compMutex.Unlock()
+
+	cm.iterations++
+}
+
+// endIteration marks a query or stage as ended.
+// Once no query or stage is running anymore the deferred structural changes are applied in order.
+func (cm *ComponentsManager) endIteration() {
+	cm.compMutex.Lock()
+	defer cm.compMutex.Unlock()
+
+	cm.iterations--
+	if cm.iterations > 0 {
+		return
+	}
+
+	pending := cm.pending
+	cm.pending = nil
+
+	for _, change := range pending {
+		switch change.kind {
+		case addComponentCommand:
+			cm.addComponent(change.eID, change.componentType, change.component)
+		case removeComponentCommand:
+			cm.removeComponent(change.eID, change.componentType)
+		case destroyEntityCommand:
+			cm.destroyEntityComponents(change.eID)
+		}
+	}
+}
+
+// RemoveComponent removes a component of a specific type from an entity.
+// The entity is moved to the archetype without the type.
+// While a query or stage is running the change is deferred until it ends.
 func (cm *ComponentsManager) RemoveComponent(eID uint64, ct ComponentType) {
 
 	cm.compMutex.Lock()
-	delete(cm.Components[ct], eID)
 	defer cm.compMutex.Unlock()
 
+	if cm.iterations > 0 {
+		cm.pending = append(cm.pending, pendingChange{kind: removeComponentCommand, eID: eID, componentType: ct})
+		return
+	}
+
+	cm.removeComponent(eID, ct)
 }
 
+// removeComponent removes a component from an entity. The caller must hold the compMutex write lock.
+func (cm *ComponentsManager) removeComponent(eID uint64, ct ComponentType) {
+	record, recordExists := cm.getRecord(eID)
+	if !recordExists || !record.archetype.mask.Has(ct) {
+		return
+	}
+
+	cm.moveEntity(eID, record, cm.getRemoveEdge(record.archetype, ct))
+}
+
+// DestroyEntityComponents removes all components associated with an entity.
+// While a query or stage is running the change is deferred until it ends.
 func (cm *ComponentsManager) DestroyEntityComponents(id uint64) {
 
 	cm.compMutex.Lock()
 	defer cm.compMutex.Unlock()
 
-	for _, components := range cm.Components {
-		delete(components, id)
+	if cm.iterations > 0 {
+		cm.pending = append(cm.pending, pendingChange{kind: destroyEntityCommand, eID: id})
+		return
+	}
+
+	cm.destroyEntityComponents(id)
+}
+
+// destroyEntityComponents removes all components of an entity. The caller must hold the compMutex write lock.
+func (cm *ComponentsManager) destroyEntityComponents(id uint64) {
+	record, recordExists := cm.getRecord(id)
+	if !recordExists {
+		return
+	}
+
+	cm.removeRecord(record)
+	cm.records[EntityIndex(id)] = entityRecord{}
+}
+
+// GetArchetypeCount returns the number of archetypes created so far
+func (cm *ComponentsManager) GetArchetypeCount() int {
+	cm.compMutex.RLock()
+	defer cm.compMutex.RUnlock()
+
+	return len(cm.archetypes)
+}
+
+// getAddEdge returns the edge to the archetype reached by adding a component type to an archetype
+func (cm *ComponentsManager) getAddEdge(a *archetype, ct ComponentType) *archetypeEdge {
+	if edge, edgeExists := a.addEdges[ct]; edgeExists {
+		return edge
 	}
+
+	types := append(append([]ComponentType{}, a.types...), ct)
+	edge := newArchetypeEdge(a, cm.getOrCreateArchetype(types))
+	a.addEdges[ct] = edge
+
+	return edge
+}
+
+// getRemoveEdge returns the edge to the archetype reached by removing a component type from an archetype
+func (cm *ComponentsManager) getRemoveEdge(a *archetype, ct ComponentType) *archetypeEdge {
+	if edge, edgeExists := a.removeEdges[ct]; edgeExists {
+		return edge
+	}
+
+	types := make([]ComponentType, 0, len(a.types)-1)
+	for _, t := range a.types {
+		if t != ct {
+			types = append(types, t)
+		}
+	}
+	edge := newArchetypeEdge(a, cm.getOrCreateArchetype(types))
+	a.removeEdges[ct] = edge
+
+	return edge
+}
+
+// getOrCreateArchetype returns the archetype for the set of component types, creating it if needed
+func (cm *ComponentsManager) getOrCreateArchetype(cts []ComponentType) *archetype {
+	mask := NewComponentMask(cts...)
+
+	if a, archetypeExists := cm.archetypeIndex[mask]; archetypeExists {
+		return a
+	}
+
+	a := newArchetype(cts)
+	cm.archetypes = append(cm.archetypes, a)
+	cm.archetypeIndex[mask] = a
+
+	return a
+}
+
+// moveEntity moves an entity and its shared components along an edge to another archetype and returns the new row
+func (cm *ComponentsManager) moveEntity(eID uint64, record entityRecord, edge *archetypeEdge) int {
+	source := record.archetype
+	target := edge.target
+	row := target.appendEntity(eID)
+
+	// Copy over every component the two archetypes have in common
+	for i, col := range edge.sourceColumns {
+		if col >= 0 {
+			target.columns[i].copyRow(row, source.columns[col], record.row)
+		}
+	}
+
+	cm.removeRecord(record)
+	cm.setRecord(entityRecord{id: eID, archetype: target, row: row})
+
+	return row
+}
+
+// removeRecord removes an entity row from its archetype and fixes the record of the entity swapped into it
+func (cm *ComponentsManager) removeRecord(record entityRecord) {
+	movedEntity, moved := record.archetype.removeRow(record.row)
+	if moved {
+		cm.setRecord(entityRecord{id: movedEntity, archetype: record.archetype, row: record.row})
+	}
+}
+
+// getRecord returns the record of an entity.
+// Records are indexed by the entity index, a record of another generation of the index doesn't match the ID.
+func (cm *ComponentsManager) getRecord(eID uint64) (entityRecord, bool) {
+	index := int(EntityIndex(eID))
+	if index >= len(cm.records) || cm.records[index].archetype == nil || cm.records[index].id != eID {
+		return entityRecord{}, false
+	}
+
+	return cm.records[index], true
+}
+
+// setRecord stores the record of an entity, growing the records to its index if needed
+func (cm *ComponentsManager) setRecord(record entityRecord) {
+	index := int(EntityIndex(record.id))
+	if index >= len(cm.records) {
+		cm.records = append(cm.records, make([]entityRecord, index+1-len(cm.records))...)
+	}
+
+	cm.records[index] = record
 }
@@ -0,0 +1,345 @@
+package ecs
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type benchPosition struct {
+	X, Y float32
+}
+
+type benchVelocity struct {
+	X, Y float32
+}
+
+type benchTag struct {
+	Value int
+}
+
+// mapComponentsManager is the map-of-maps storage the archetypes replaced, kept as the benchmark baseline
+type mapComponentsManager struct {
+	components map[ComponentType]map[uint64]Component
+	compMutex  sync.RWMutex
+}
+
+func newMapComponentsManager() *mapComponentsManager {
+	return &mapComponentsManager{components: make(map[ComponentType]map[uint64]Component)}
+}
+
+func (cm *mapComponentsManager) AddComponent(eID uint64, ct ComponentType, c Component) {
+	cm.compMutex.Lock()
+	defer cm.compMutex.Unlock()
+
+	if _, exists := cm.components[ct]; !exists {
+		cm.components[ct] = make(map[uint64]Component)
+	}
+
+	cm.components[ct][eID] = c
+}
+
+func (cm *mapComponentsManager) GetComponent(eID uint64, ct ComponentType) (Component, bool) {
+	cm.compMutex.RLock()
+	defer cm.compMutex.RUnlock()
+
+	comp, compExists := cm.components[ct][eID]
+	return comp, compExists
+}
+
+func (cm *mapComponentsManager) RemoveComponent(eID uint64, ct ComponentType) {
+	cm.compMutex.Lock()
+	defer cm.compMutex.Unlock()
+
+	delete(cm.components[ct], eID)
+}
+
+func (cm *mapComponentsManager) GetEntitiesWithComponents(cts []ComponentType) []uint64 {
+	cm.compMutex.RLock()
+	defer cm.compMutex.RUnlock()
+
+	entities := []uint64{}
+	for eID := range cm.components[cts[0]] {
+		hasAll := true
+		for _, ct := range cts[1:] {
+			if _, exists := cm.components[ct][eID]; !exists {
+				hasAll = false
+				break
+			}
+		}
+		if hasAll {
+			entities = append(entities, eID)
+		}
+	}
+
+	return entities
+}
+
+var benchEntityCounts = []int{10_000, 100_000}
+
+// newBenchWorld creates count entities with a position and a velocity, every other one also has a tag
+// so the entities are split over two archetypes
+func newBenchWorld(count int) (*ECSManager, *mapComponentsManager) {
+	em := NewECSManager()
+	baseline := newMapComponentsManager()
+
+	for i := 0; i < count; i++ {
+		eID := em.CreateEntity().ID
+
+		position := &benchPosition{X: float32(i)}
+		velocity := &benchVelocity{X: 1, Y: 1}
+		Add(em, eID, position)
+		Add(em, eID, velocity)
+		baseline.AddComponent(eID, ComponentTypeOf[benchPosition](), position)
+		baseline.AddComponent(eID, ComponentTypeOf[benchVelocity](), velocity)
+
+		if i%2 == 0 {
+			tag := &benchTag{Value: i}
+			Add(em, eID, tag)
+			baseline.AddComponent(eID, ComponentTypeOf[benchTag](), tag)
+		}
+	}
+
+	return em, baseline
+}
+
+// BenchmarkIterate moves every entity with a position and a velocity
+func BenchmarkIterate(b *testing.B) {
+	positionType := ComponentTypeOf[benchPosition]()
+	velocityType := ComponentTypeOf[benchVelocity]()
+
+	for _, count := range benchEntityCounts {
+		em, baseline := newBenchWorld(count)
+
+		b.Run(fmt.Sprintf("MapOfMaps/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, eID := range baseline.GetEntitiesWithComponents([]ComponentType{positionType, velocityType}) {
+					p, _ := baseline.GetComponent(eID, positionType)
+					v, _ := baseline.GetComponent(eID, velocityType)
+					position, velocity := p.(*benchPosition), v.(*benchVelocity)
+					position.X += velocity.X
+					position.Y += velocity.Y
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("ArchetypesUntyped/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				em.Query([]ComponentType{positionType, velocityType}, func(eID uint64, comps []Component) {
+					position, velocity := comps[0].(*benchPosition), comps[1].(*benchVelocity)
+					position.X += velocity.X
+					position.Y += velocity.Y
+				})
+			}
+		})
+
+		b.Run(fmt.Sprintf("ArchetypesTyped/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, row := range Query2[benchPosition, benchVelocity](em) {
+					row.A.X += row.B.X
+					row.A.Y += row.B.Y
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGet looks up the position of every entity by ID
+func BenchmarkGet(b *testing.B) {
+	positionType := ComponentTypeOf[benchPosition]()
+
+	for _, count := range benchEntityCounts {
+		em, baseline := newBenchWorld(count)
+		entities := em.GetComponentsManager().GetEntitiesWithComponents([]ComponentType{positionType})
+
+		b.Run(fmt.Sprintf("MapOfMaps/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, eID := range entities {
+					baseline.GetComponent(eID, positionType)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Archetypes/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, eID := range entities {
+					em.GetComponentsManager().GetComponent(eID, positionType)
+				}
+			}
+		})
+
+		// Get also checks that the entity is alive and looks up the component type
+		b.Run(fmt.Sprintf("ArchetypesTyped/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, eID := range entities {
+					Get[benchPosition](em, eID)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkAddRemove adds and removes the tag of every entity, moving it between archetypes.
+// This is what archetypes trade for iteration: every move copies the other components of the entity
+// to the next archetype, where the map-of-maps only touches the map of the tag.
+// Add and Remove also check that the entity is alive, which the baseline doesn't.
+func BenchmarkAddRemove(b *testing.B) {
+	positionType := ComponentTypeOf[benchPosition]()
+	tagType := ComponentTypeOf[benchTag]()
+
+	for _, count := range benchEntityCounts {
+		em, baseline := newBenchWorld(count)
+		entities := em.GetComponentsManager().GetEntitiesWithComponents([]ComponentType{positionType})
+		tag := &benchTag{}
+
+		b.Run(fmt.Sprintf("MapOfMaps/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, eID := range entities {
+					baseline.RemoveComponent(eID, tagType)
+					baseline.AddComponent(eID, tagType, tag)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Archetypes/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, eID := range entities {
+					Remove[benchTag](em, eID)
+					Add(em, eID, tag)
+				}
+			}
+		})
+	}
+}
+
+func TestComponentsMoveBetweenArchetypes(t *testing.T) {
+	em := NewECSManager()
+
+	eID := em.CreateEntity().ID
+	Add(em, eID, &benchPosition{X: 1, Y: 2})
+	Add(em, eID, &benchVelocity{X: 3})
+	Add(em, eID, &benchTag{Value: 4})
+	Remove[benchVelocity](em, eID)
+
+	if got, exists := Get[benchPosition](em, eID); !exists || *got != (benchPosition{X: 1, Y: 2}) {
+		t.Fatalf("position = %v, %v, want the added component", got, exists)
+	}
+	if Has[benchVelocity](em, eID) {
+		t.Fatal("velocity was not removed")
+	}
+	if tag, exists := Get[benchTag](em, eID); !exists || tag.Value != 4 {
+		t.Fatalf("tag = %v, %v, want value 4", tag, exists)
+	}
+
+	// A component of the wrong type is refused instead of stored in the typed column
+	em.AddComponent(eID, ComponentTypeOf[benchVelocity](), &benchTag{})
+	if Has[benchVelocity](em, eID) {
+		t.Fatal("component of the wrong type was stored")
+	}
+}
+
+// Looking up components while a query runs must not lock the manager again,
+// a recursive read lock deadlocks as soon as a writer is waiting for it
+func TestGetInsideQueryWithWaitingWriter(t *testing.T) {
+	em := NewECSManager()
+
+	for i := 0; i < 4; i++ {
+		eID := em.CreateEntity().ID
+		Add(em, eID, &benchPosition{X: float32(i)})
+		Add(em, eID, &benchTag{Value: i})
+	}
+	writer := em.CreateEntity().ID
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		once := sync.Once{}
+		for eID := range Query1[benchPosition](em) {
+			once.Do(func() {
+				go Add(em, writer, &benchVelocity{})
+				time.Sleep(10 * time.Millisecond)
+			})
+
+			if !Has[benchTag](em, eID) {
+				t.Errorf("entity %d lost its tag", eID)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Get inside a query deadlocked with a waiting writer")
+	}
+}
+
+// Removing components inside a query is deferred until the query ends, so the query still visits
+// every entity once with its own components instead of rows swapped or cleared under it
+func TestRemoveComponentsInsideQuery(t *testing.T) {
+	em := NewECSManager()
+
+	const count = 100
+	for i := 0; i < count; i++ {
+		eID := em.CreateEntity().ID
+		Add(em, eID, &benchPosition{X: float32(i)})
+		Add(em, eID, &benchVelocity{X: float32(i)})
+	}
+
+	visited := make(map[uint64]bool)
+	for eID, row := range Query2[benchPosition, benchVelocity](em) {
+		if row.A == nil || row.B == nil {
+			t.Fatalf("entity %d: position %v, velocity %v, want both", eID, row.A, row.B)
+		}
+		if row.A.X != row.B.X {
+			t.Fatalf("entity %d: position %v and velocity %v belong to different entities", eID, row.A.X, row.B.X)
+		}
+		if visited[eID] {
+			t.Fatalf("entity %d visited twice", eID)
+		}
+		visited[eID] = true
+
+		Remove[benchVelocity](em, eID)
+		if !Has[benchVelocity](em, eID) {
+			t.Fatalf("entity %d lost its velocity before the query ended", eID)
+		}
+
+		// Moving the entity to another archetype must not move the rows still to be visited either
+		Add(em, eID, &benchTag{Value: int(row.A.X)})
+	}
+
+	if len(visited) != count {
+		t.Fatalf("visited %d entities, want %d", len(visited), count)
+	}
+	for eID := range visited {
+		if Has[benchVelocity](em, eID) {
+			t.Fatalf("entity %d kept its velocity after the query", eID)
+		}
+		position, _ := Get[benchPosition](em, eID)
+		if tag, tagExists := Get[benchTag](em, eID); !tagExists || float32(tag.Value) != position.X {
+			t.Fatalf("entity %d: tag %v, want %v", eID, tag, position.X)
+		}
+	}
+}
+
+// Components are stored by value, the component passed to Add is copied into the world
+func TestAddCopiesComponent(t *testing.T) {
+	em := NewECSManager()
+
+	eID := em.CreateEntity().ID
+	position := &benchPosition{X: 1}
+	Add(em, eID, position)
+	position.X = 2
+
+	stored, _ := Get[benchPosition](em, eID)
+	if stored.X != 1 {
+		t.Fatalf("stored position = %v, want the value it was added with", stored.X)
+	}
+
+	// Writes through the pointer returned by Get change the stored component
+	stored.X = 3
+	if got, _ := Get[benchPosition](em, eID); got.X != 3 {
+		t.Fatalf("position = %v, want 3", got.X)
+	}
+}
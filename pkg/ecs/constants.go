@@ -1,24 +1,40 @@
 package ecs
 
-// Component types for the ECS
-const (
-	Transform2DComponent = iota
-	RigidBodyComponent
-	BoxColliderComponent
-	ColorComponent
-	SpeedComponent
-	PlayerComponent
-	VelocityComponent
-	SpriteComponent
-	ParticleComponent
-	ParticleEmitterComponent
-	AnimationComponent
-	AudioSourceComponent
+import (
+	"github.com/webbelito/Fenrir/pkg/components"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
 )
 
-// Component types for UI components with an offset of 100
-const (
-	UILabelComponent = iota + 100
-	UIPanelComponent
-	UIButtonComponent
+// Component types for the ECS, registered by their Go type
+var (
+	Transform2DComponent      = ComponentTypeOf[components.Transform2D]()
+	RigidBodyComponent        = ComponentTypeOf[physicscomponents.RigidBody]()
+	BoxColliderComponent      = ComponentTypeOf[physicscomponents.BoxCollider]()
+	CircleColliderComponent   = ComponentTypeOf[physicscomponents.CircleCollider]()
+	CapsuleColliderComponent  = ComponentTypeOf[physicscomponents.CapsuleCollider]()
+	PolygonColliderComponent  = ComponentTypeOf[physicscomponents.PolygonCollider]()
+	ContactsComponent         = ComponentTypeOf[physicscomponents.Contacts]()
+	JointsComponent           = ComponentTypeOf[physicscomponents.Joints]()
+	ColorComponent            = ComponentTypeOf[components.Color]()
+	SpeedComponent            = ComponentTypeOf[components.Speed]()
+	PlayerComponent           = ComponentTypeOf[components.Player]()
+	VelocityComponent         = ComponentTypeOf[components.Velocity]()
+	SpriteComponent           = ComponentTypeOf[components.Sprite]()
+	ParticleComponent         = ComponentTypeOf[components.Particle]()
+	ParticleEmitterComponent  = ComponentTypeOf[components.ParticleEmitter]()
+	AnimationComponent        = ComponentTypeOf[components.Animation]()
+	AudioSourceComponent      = ComponentTypeOf[components.AudioSource]()
+	SceneIDComponent          = ComponentTypeOf[components.SceneID]()
+	PrefabInstanceComponent   = ComponentTypeOf[components.PrefabInstance]()
+	HierarchyComponent        = ComponentTypeOf[components.Hierarchy]()
+	LocalTransform2DComponent = ComponentTypeOf[components.LocalTransform2D]()
+	SceneTriggerComponent     = ComponentTypeOf[components.SceneTrigger]()
+)
+
+// Component types for UI components, registered by their Go type
+var (
+	UILabelComponent   = UIComponentTypeOf[components.UILabel]()
+	UIPanelComponent   = UIComponentTypeOf[components.UIPanel]()
+	UIButtonComponent  = UIComponentTypeOf[components.UIButton]()
+	UIOverlayComponent = UIComponentTypeOf[components.UIOverlay]()
 )
@@ -1,11 +1,15 @@
 package ecs
 
 import (
+	"sync"
 	"time"
 
+	"github.com/webbelito/Fenrir/pkg/engine"
 	"github.com/webbelito/Fenrir/pkg/events"
 	metricinterfaces "github.com/webbelito/Fenrir/pkg/interfaces/metricinterfaces"
 	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+	"github.com/webbelito/Fenrir/pkg/platform"
 	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
@@ -15,6 +19,14 @@ type ECSManager struct {
 	uiComponentsManager *UIComponentsManager
 	systemsManager      *SystemsManager
 	eventsManager       *events.EventsManager
+	commandBuffer       *CommandBuffer
+	prefabsManager      *PrefabsManager
+	hierarchyMutex      sync.RWMutex
+	transformHistory    *transformHistory
+	loop                *engine.Loop
+	platform            platform.Platform
+	input               *platform.InputBuffer
+	collisionLayers     *physicscomponents.CollisionLayers
 
 	performanceMetrics metricinterfaces.PerformanceMetrics
 }
@@ -27,15 +39,51 @@ func NewECSManager() *ECSManager {
 		componentsManager:   NewComponentsManager(),
 		uiComponentsManager: NewUIComponentsManager(),
 		eventsManager:       events.NewEventsManager(),
+		prefabsManager:      NewPrefabsManager(DefaultPrefabDirectory),
+		platform:            platform.NewRaylibPlatform(),
+		collisionLayers:     physicscomponents.NewCollisionLayers(),
 	}
 
 	ecsManager.systemsManager = NewSystemsManager(ecsManager)
+	ecsManager.commandBuffer = NewCommandBuffer(ecsManager)
+	ecsManager.transformHistory = newTransformHistory()
+	ecsManager.loop = engine.NewLoop(engine.DefaultTickRate, engine.DefaultMaxSteps)
+	ecsManager.input = platform.NewInputBuffer(ecsManager.platform)
 
 	ecsManager.performanceMetrics = metricinterfaces.PerformanceMetrics{}
 
 	return ecsManager
 }
 
+// * Platform methods
+
+// GetPlatform returns the platform used for screen, input and time queries.
+// Logic systems read the keyboard through GetInput instead, it is sampled once per frame.
+func (em *ECSManager) GetPlatform() platform.Platform {
+	return em.platform
+}
+
+// SetPlatform replaces the platform, e.g. with a NullPlatform to run without a window.
+// It should be set before any scene or system is initialized.
+func (em *ECSManager) SetPlatform(p platform.Platform) {
+	em.platform = p
+	em.input = platform.NewInputBuffer(p)
+}
+
+// GetInput returns the keyboard input of the current frame for the logic systems.
+// Key presses are kept until a logic step has run, so they aren't lost on frames without a step.
+func (em *ECSManager) GetInput() *platform.InputBuffer {
+	return em.input
+}
+
+// * Collision layer methods
+
+// GetCollisionLayers returns the names of the collision layers of the world, declared by the scene it was loaded from.
+// Collider components are decoded and encoded with them.
+func (em *ECSManager) GetCollisionLayers() *physicscomponents.CollisionLayers {
+	return em.collisionLayers
+}
+
 // * EntitiesManager methods
 
 func (em *ECSManager) GetEntitiesManager() *EntitiesManager {
@@ -47,6 +95,15 @@ func (em *ECSManager) CreateEntity() *Entity {
 }
 
 func (em *ECSManager) DestroyEntity(id uint64) {
+
+	// Ignore stale handles so the entity now using the index is left untouched
+	if !em.entitiesManager.IsAlive(id) {
+		return
+	}
+
+	// Destroy the children with their parent
+	em.destroyHierarchy(id)
+
 	em.entitiesManager.DestroyEntity(id)
 	em.componentsManager.DestroyEntityComponents(id)
 	em.uiComponentsManager.DestroyEntityComponents(id)
@@ -56,6 +113,11 @@ func (em *ECSManager) GetAllEntities() []*Entity {
 	return em.entitiesManager.GetAllEntities()
 }
 
+// IsAlive checks if the entity ID refers to an entity that has not been destroyed
+func (em *ECSManager) IsAlive(id uint64) bool {
+	return em.entitiesManager.IsAlive(id)
+}
+
 // * ComponentsManager methods
 
 func (em *ECSManager) GetComponentsManager() *ComponentsManager {
@@ -67,23 +129,74 @@ func (em *ECSManager) GetEntityCount() int {
 }
 
 func (em *ECSManager) AddComponent(eID uint64, ct ComponentType, c Component) {
+	if !em.entitiesManager.IsAlive(eID) {
+		utils.WarnLogger.Printf("ECSManager: cannot add component %d to dead entity %d", ct, eID)
+		return
+	}
+
 	em.componentsManager.AddComponent(eID, ct, c)
 }
 
+// GetComponent retrieves a component for an entity. Stale entity IDs never return a component.
 func (em *ECSManager) GetComponent(eID uint64, ct ComponentType) (Component, bool) {
+	if !em.entitiesManager.IsAlive(eID) {
+		return nil, false
+	}
+
 	return em.componentsManager.GetComponent(eID, ct)
 }
 
+// RemoveComponent removes a component from an entity.
+// Stale entity IDs are ignored, so the entity now using the index is left untouched.
+func (em *ECSManager) RemoveComponent(eID uint64, ct ComponentType) {
+	if !em.entitiesManager.IsAlive(eID) {
+		return
+	}
+
+	em.componentsManager.RemoveComponent(eID, ct)
+}
+
+func (em *ECSManager) Query(cts []ComponentType, fn func(eID uint64, comps []Component)) {
+	em.componentsManager.Query(cts, fn)
+}
+
+// * CommandBuffer methods
+
+// GetCommandBuffer returns the shared CommandBuffer, used to queue structural changes outside of logic systems, e.g. in event handlers.
+// Logic systems use their own buffer, see GetSystemCommandBuffer.
+func (em *ECSManager) GetCommandBuffer() *CommandBuffer {
+	return em.commandBuffer
+}
+
+// GetSystemCommandBuffer returns the CommandBuffer a logic system queues its structural changes in
+func (em *ECSManager) GetSystemCommandBuffer(system systeminterfaces.UpdatableSystemInterface) *CommandBuffer {
+	return em.systemsManager.GetCommandBuffer(system)
+}
+
+// FlushCommands applies all structural changes queued in the CommandBuffer
+func (em *ECSManager) FlushCommands() {
+	em.commandBuffer.Flush()
+}
+
 // * UIComponentsManager methods
 func (em *ECSManager) GetUIComponentsManager() *UIComponentsManager {
 	return em.uiComponentsManager
 }
 
 func (em *ECSManager) AddUIComponent(eID uint64, ct UIComponentType, c UIComponent) {
+	if !em.entitiesManager.IsAlive(eID) {
+		utils.WarnLogger.Printf("ECSManager: cannot add UI component %d to dead entity %d", ct, eID)
+		return
+	}
+
 	em.uiComponentsManager.AddComponent(eID, ct, c)
 }
 
 func (em *ECSManager) GetUIComponent(eID uint64, ct UIComponentType) (UIComponent, bool) {
+	if !em.entitiesManager.IsAlive(eID) {
+		return nil, false
+	}
+
 	return em.uiComponentsManager.GetComponent(eID, ct)
 }
 
@@ -125,12 +238,31 @@ func (em *ECSManager) RemoveUIRenderSystem(system systeminterfaces.UIRenderableS
 	em.systemsManager.RemoveUIRenderSystem(system, priority)
 }
 
-func (em *ECSManager) UpdateLogicSystems(dt float64) {
+// UpdateLogicSystems advances the logic systems by the frame time in fixed steps.
+// Rendering can interpolate between the last two steps with GetInterpolatedTransform.
+// Input is sampled once per frame before the steps run.
+func (em *ECSManager) UpdateLogicSystems(frameTime float64) {
 	em.performanceMetrics.UpdateStartTime = time.Now()
-	em.systemsManager.Update(dt)
+	em.input.Sample()
+	em.loop.Advance(frameTime, em.StepLogicSystems)
 	em.performanceMetrics.UpdateDuration = time.Since(em.performanceMetrics.UpdateStartTime)
 }
 
+// StepLogicSystems runs the logic systems for a single fixed step of dt seconds.
+// The Transform2D state before the step is kept so rendering can interpolate.
+func (em *ECSManager) StepLogicSystems(dt float64) {
+	em.SnapshotTransforms()
+	em.systemsManager.Update(dt)
+
+	// The sampled key presses have been seen by a step
+	em.input.EndStep()
+}
+
+// GetLoop returns the fixed timestep loop driving the logic systems
+func (em *ECSManager) GetLoop() *engine.Loop {
+	return em.loop
+}
+
 func (em *ECSManager) UpdateRenderSystems() {
 	em.performanceMetrics.RenderStartTime = time.Now()
 	em.systemsManager.Render()
@@ -181,3 +313,13 @@ func (ecsM *ECSManager) GetPlayerEntity() *Entity {
 func (ecsM *ECSManager) GetCameraSystem() (systeminterfaces.CameraSystemInterface, bool) {
 	return ecsM.systemsManager.GetCameraSystem()
 }
+
+// GetPhysicsQueries returns the physics queries of the scene, if a system provides them
+func (ecsM *ECSManager) GetPhysicsQueries() (systeminterfaces.PhysicsQueriesInterface, bool) {
+	return ecsM.systemsManager.GetPhysicsQueries()
+}
+
+// GetPhysicsDebugRenderer returns the system drawing the debug views of the physics world, if the scene has one
+func (ecsM *ECSManager) GetPhysicsDebugRenderer() (systeminterfaces.PhysicsDebugRendererInterface, bool) {
+	return ecsM.systemsManager.GetPhysicsDebugRenderer()
+}
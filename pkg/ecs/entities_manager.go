@@ -2,7 +2,14 @@ package ecs
 
 import (
 	"sync"
-	"sync/atomic"
+)
+
+// Entity IDs pack an index into the lower 32 bits and a generation into the upper 32 bits.
+// The generation is bumped every time an index is recycled, so IDs held after the entity
+// was destroyed no longer match and can be detected as stale.
+const (
+	entityIndexBits = 32
+	entityIndexMask = 1<<entityIndexBits - 1
 )
 
 // Entity represents an unique entity with an unique ID
@@ -10,39 +17,106 @@ type Entity struct {
 	ID uint64
 }
 
+// NewEntityID combines an index and a generation into an entity ID
+func NewEntityID(index uint32, generation uint32) uint64 {
+	return uint64(generation)<<entityIndexBits | uint64(index)
+}
+
+// EntityIndex returns the index part of an entity ID
+func EntityIndex(id uint64) uint32 {
+	return uint32(id & entityIndexMask)
+}
+
+// EntityGeneration returns the generation part of an entity ID
+func EntityGeneration(id uint64) uint32 {
+	return uint32(id >> entityIndexBits)
+}
+
+// Index returns the index part of the entity ID
+func (e *Entity) Index() uint32 {
+	return EntityIndex(e.ID)
+}
+
+// Generation returns the generation part of the entity ID
+func (e *Entity) Generation() uint32 {
+	return EntityGeneration(e.ID)
+}
+
 // EntitiesManager manages the creation and destruction of entities
 type EntitiesManager struct {
-	nextEntityID uint64
-	entities     map[uint64]*Entity
-	entityMutex  sync.RWMutex
+	generations []uint32
+	alive       []bool
+	freeIndices []uint32
+	entities    map[uint64]*Entity
+	entityMutex sync.RWMutex
 }
 
 // NewEntitiesManager initializes and returns a new EntitiesManager
 func NewEntitiesManager() *EntitiesManager {
 	return &EntitiesManager{
-		nextEntityID: 1,
-		entities:     make(map[uint64]*Entity),
+		// Index 0 is reserved so that an entity ID of 0 always means "no entity"
+		generations: []uint32{0},
+		alive:       []bool{false},
+		freeIndices: []uint32{},
+		entities:    make(map[uint64]*Entity),
 	}
 }
 
-// CreateEntity creates a new entity with a unique ID and adds it to the entities map
+// CreateEntity creates a new entity with a unique ID and adds it to the entities map.
+// Indices of destroyed entities are recycled with a new generation.
 func (em *EntitiesManager) CreateEntity() *Entity {
-	id := atomic.AddUint64(&em.nextEntityID, 1)
-	entity := &Entity{ID: id}
+	entity := em.reserveEntity()
+	em.activateEntity(entity)
+	return entity
+}
+
+// reserveEntity allocates an entity ID without making the entity alive yet.
+// The index is taken off the free list so it can't be handed out twice.
+func (em *EntitiesManager) reserveEntity() *Entity {
+	em.entityMutex.Lock()
+	defer em.entityMutex.Unlock()
+
+	var index uint32
+
+	if len(em.freeIndices) > 0 {
+		// Reuse the oldest free index
+		index = em.freeIndices[0]
+		em.freeIndices = em.freeIndices[1:]
+	} else {
+		index = uint32(len(em.generations))
+		em.generations = append(em.generations, 0)
+		em.alive = append(em.alive, false)
+	}
+
+	return &Entity{ID: NewEntityID(index, em.generations[index])}
+}
 
+// activateEntity adds a reserved entity to the entities map, making it alive
+func (em *EntitiesManager) activateEntity(entity *Entity) {
 	em.entityMutex.Lock()
 	defer em.entityMutex.Unlock()
 
 	em.entities[entity.ID] = entity
-	return entity
+	em.alive[EntityIndex(entity.ID)] = true
 }
 
-// DestroyEntity removes an entity from the manager.
-// It safely handles attempts to remove non-existent entities.
+// DestroyEntity removes an entity from the manager and frees its index.
+// It safely handles attempts to remove non-existent or stale entities.
 func (em *EntitiesManager) DestroyEntity(id uint64) {
 	em.entityMutex.Lock()
+	defer em.entityMutex.Unlock()
+
+	if _, exists := em.entities[id]; !exists {
+		return
+	}
+
 	delete(em.entities, id)
-	em.entityMutex.Unlock()
+
+	// Bump the generation so any handle to the destroyed entity becomes stale
+	index := EntityIndex(id)
+	em.alive[index] = false
+	em.generations[index]++
+	em.freeIndices = append(em.freeIndices, index)
 }
 
 func (em *EntitiesManager) GetAllEntities() []*Entity {
@@ -77,9 +151,16 @@ func (em *EntitiesManager) GetEntityCount() int {
 }
 
 func (em *EntitiesManager) EntityExists(id uint64) bool {
+	return em.IsAlive(id)
+}
+
+// IsAlive checks if the entity ID refers to an entity that has not been destroyed.
+// IDs whose index has since been recycled have an older generation and are not alive.
+// It is called by every component lookup, so it only reads the slices indexed by the entity index.
+func (em *EntitiesManager) IsAlive(id uint64) bool {
 	em.entityMutex.RLock()
 	defer em.entityMutex.RUnlock()
 
-	_, exists := em.entities[id]
-	return exists
+	index := EntityIndex(id)
+	return int(index) < len(em.generations) && em.generations[index] == EntityGeneration(id) && em.alive[index]
 }
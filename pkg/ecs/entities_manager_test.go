@@ -0,0 +1,78 @@
+package ecs
+
+import (
+	"testing"
+)
+
+// Destroying an entity bumps the generation of its index, so every recycled ID is new
+func TestRecycledIndexGetsNewGeneration(t *testing.T) {
+	em := NewEntitiesManager()
+
+	first := em.CreateEntity().ID
+	previous := first
+
+	for i := 0; i < 5; i++ {
+		em.DestroyEntity(previous)
+		recycled := em.CreateEntity().ID
+
+		if EntityIndex(recycled) != EntityIndex(first) {
+			t.Fatalf("entity got index %d, want the recycled index %d", EntityIndex(recycled), EntityIndex(first))
+		}
+		if EntityGeneration(recycled) <= EntityGeneration(previous) {
+			t.Fatalf("recycled generation %d, want more than %d", EntityGeneration(recycled), EntityGeneration(previous))
+		}
+		if em.IsAlive(previous) {
+			t.Fatalf("destroyed entity %d is still alive", previous)
+		}
+		if !em.IsAlive(recycled) {
+			t.Fatalf("recycled entity %d is not alive", recycled)
+		}
+
+		previous = recycled
+	}
+}
+
+// A reserved entity is not alive until it is activated, e.g. by a CommandBuffer flush
+func TestReservedEntityIsNotAlive(t *testing.T) {
+	em := NewEntitiesManager()
+
+	entity := em.reserveEntity()
+	if em.IsAlive(entity.ID) {
+		t.Fatal("reserved entity is alive before it was activated")
+	}
+
+	em.activateEntity(entity)
+	if !em.IsAlive(entity.ID) {
+		t.Fatal("activated entity is not alive")
+	}
+}
+
+// A stale handle to a destroyed entity must not reach the entity now using its index
+func TestStaleHandleLeavesRecycledEntityAlone(t *testing.T) {
+	em := NewECSManager()
+
+	stale := em.CreateEntity().ID
+	Add(em, stale, &benchPosition{X: 1})
+	em.DestroyEntity(stale)
+
+	recycled := em.CreateEntity().ID
+	if EntityIndex(recycled) != EntityIndex(stale) {
+		t.Fatalf("entity got index %d, want the recycled index %d", EntityIndex(recycled), EntityIndex(stale))
+	}
+	Add(em, recycled, &benchPosition{X: 2})
+
+	if Has[benchPosition](em, stale) {
+		t.Fatal("stale handle still finds a component")
+	}
+
+	Add(em, stale, &benchPosition{X: 3})
+	Remove[benchPosition](em, stale)
+	em.DestroyEntity(stale)
+
+	if !em.IsAlive(recycled) {
+		t.Fatal("destroying the stale handle destroyed the recycled entity")
+	}
+	if position, exists := Get[benchPosition](em, recycled); !exists || position.X != 2 {
+		t.Fatalf("recycled entity position = %v, %v, want 2", position, exists)
+	}
+}
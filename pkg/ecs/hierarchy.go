@@ -0,0 +1,249 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/components"
+)
+
+// SetParent attaches an entity to a parent, detaching it from its previous parent first.
+// If the child has no LocalTransform2D one is computed from the current transforms, so the child stays where it is.
+// A child without a Transform2D is given one at its place under the parent, so propagating the transforms
+// never has to add components.
+// Passing a parent of 0 detaches the entity, see RemoveParent.
+func (em *ECSManager) SetParent(child uint64, parent uint64) error {
+	if parent == 0 {
+		em.RemoveParent(child)
+		return nil
+	}
+
+	if !em.IsAlive(child) || !em.IsAlive(parent) {
+		return fmt.Errorf("SetParent: entity %d or parent %d is not alive", child, parent)
+	}
+
+	em.hierarchyMutex.Lock()
+	defer em.hierarchyMutex.Unlock()
+
+	// A parent can't be the child itself or one of its descendants
+	for ancestor := parent; ancestor != 0; ancestor = em.getParent(ancestor) {
+		if ancestor == child {
+			return fmt.Errorf("SetParent: entity %d can't be a child of its descendant %d", child, parent)
+		}
+	}
+
+	em.detach(child)
+
+	// Adding a Hierarchy moves an entity to another archetype, which can move the other one's
+	// Hierarchy too, so both are looked up once they exist
+	em.getOrAddHierarchy(child)
+	em.getOrAddHierarchy(parent)
+
+	childHierarchy, _ := Get[components.Hierarchy](em, child)
+	childHierarchy.Parent = parent
+
+	parentHierarchy, _ := Get[components.Hierarchy](em, parent)
+	parentHierarchy.Children = append(parentHierarchy.Children, child)
+
+	// Keep the child in place by deriving its local transform from the world transforms
+	if !Has[components.LocalTransform2D](em, child) {
+		local := &components.LocalTransform2D{Scale: raylib.NewVector2(1, 1)}
+
+		childTransform, childTransformExists := Get[components.Transform2D](em, child)
+		parentTransform, parentTransformExists := Get[components.Transform2D](em, parent)
+		if childTransformExists && parentTransformExists {
+			local = toLocalTransform(parentTransform, childTransform)
+		}
+
+		Add(em, child, local)
+	}
+
+	if !Has[components.Transform2D](em, child) {
+		world := components.Transform2D{Scale: raylib.NewVector2(1, 1)}
+
+		local, _ := Get[components.LocalTransform2D](em, child)
+		if parentTransform, parentTransformExists := Get[components.Transform2D](em, parent); parentTransformExists {
+			world = toWorldTransform(parentTransform, local)
+		}
+
+		Add(em, child, &world)
+	}
+
+	return nil
+}
+
+// RemoveParent detaches an entity from its parent. The entity keeps its current Transform2D
+// and its LocalTransform2D is removed, so it is a root again.
+func (em *ECSManager) RemoveParent(child uint64) {
+	em.hierarchyMutex.Lock()
+	defer em.hierarchyMutex.Unlock()
+
+	if em.getParent(child) == 0 {
+		return
+	}
+
+	em.detach(child)
+	Remove[components.LocalTransform2D](em, child)
+}
+
+// GetParent returns the parent of an entity, or false if it is a root
+func (em *ECSManager) GetParent(eID uint64) (uint64, bool) {
+	em.hierarchyMutex.RLock()
+	defer em.hierarchyMutex.RUnlock()
+
+	parent := em.getParent(eID)
+	return parent, parent != 0
+}
+
+// GetChildren returns a copy of the children of an entity in the order they were attached
+func (em *ECSManager) GetChildren(eID uint64) []uint64 {
+	em.hierarchyMutex.RLock()
+	defer em.hierarchyMutex.RUnlock()
+
+	hierarchy, hierarchyExists := Get[components.Hierarchy](em, eID)
+	if !hierarchyExists {
+		return nil
+	}
+
+	return slices.Clone(hierarchy.Children)
+}
+
+// PropagateTransforms derives the Transform2D of every child from its parent's Transform2D and its LocalTransform2D.
+// Parents are always updated before their children. It only writes the transforms and never adds or removes
+// components, so it is safe to call from a system.
+func (em *ECSManager) PropagateTransforms() {
+	em.hierarchyMutex.RLock()
+	defer em.hierarchyMutex.RUnlock()
+
+	roots := []uint64{}
+	for eID, hierarchy := range Query1[components.Hierarchy](em) {
+		if hierarchy.Parent == 0 && len(hierarchy.Children) > 0 {
+			roots = append(roots, eID)
+		}
+	}
+
+	for _, root := range roots {
+		rootTransform, rootTransformExists := Get[components.Transform2D](em, root)
+		if !rootTransformExists {
+			continue
+		}
+
+		em.propagateChildren(root, rootTransform)
+	}
+}
+
+// propagateChildren updates the children of an entity from its world transform, recursively
+func (em *ECSManager) propagateChildren(eID uint64, world *components.Transform2D) {
+	hierarchy, hierarchyExists := Get[components.Hierarchy](em, eID)
+	if !hierarchyExists {
+		return
+	}
+
+	for _, child := range hierarchy.Children {
+
+		// SetParent gives every child a Transform2D, one removed since can't be placed
+		childWorld, childWorldExists := Get[components.Transform2D](em, child)
+		if !childWorldExists {
+			continue
+		}
+
+		// Children without a local transform keep their own world transform
+		if local, localExists := Get[components.LocalTransform2D](em, child); localExists {
+			*childWorld = toWorldTransform(world, local)
+		}
+
+		em.propagateChildren(child, childWorld)
+	}
+}
+
+// destroyHierarchy destroys the children of an entity and detaches it from its parent.
+// It is called by DestroyEntity before the entity itself is destroyed.
+func (em *ECSManager) destroyHierarchy(eID uint64) {
+	hierarchy, hierarchyExists := Get[components.Hierarchy](em, eID)
+	if !hierarchyExists {
+		return
+	}
+
+	em.hierarchyMutex.Lock()
+	em.detach(eID)
+	children := slices.Clone(hierarchy.Children)
+	hierarchy.Children = nil
+	em.hierarchyMutex.Unlock()
+
+	for _, child := range children {
+		em.DestroyEntity(child)
+	}
+}
+
+// getParent returns the parent of an entity, or 0 if it has none
+func (em *ECSManager) getParent(eID uint64) uint64 {
+	hierarchy, hierarchyExists := Get[components.Hierarchy](em, eID)
+	if !hierarchyExists {
+		return 0
+	}
+
+	return hierarchy.Parent
+}
+
+// detach removes an entity from the children of its parent
+func (em *ECSManager) detach(child uint64) {
+	childHierarchy, childHierarchyExists := Get[components.Hierarchy](em, child)
+	if !childHierarchyExists || childHierarchy.Parent == 0 {
+		return
+	}
+
+	if parentHierarchy, parentHierarchyExists := Get[components.Hierarchy](em, childHierarchy.Parent); parentHierarchyExists {
+		parentHierarchy.Children = slices.DeleteFunc(parentHierarchy.Children, func(eID uint64) bool {
+			return eID == child
+		})
+	}
+
+	childHierarchy.Parent = 0
+}
+
+// getOrAddHierarchy returns the Hierarchy of an entity, adding an empty one if it has none
+func (em *ECSManager) getOrAddHierarchy(eID uint64) *components.Hierarchy {
+	hierarchy, hierarchyExists := Get[components.Hierarchy](em, eID)
+	if !hierarchyExists {
+		Add(em, eID, &components.Hierarchy{})
+
+		// Components are copied into their column when added
+		hierarchy, _ = Get[components.Hierarchy](em, eID)
+	}
+
+	return hierarchy
+}
+
+// toWorldTransform applies the parent's world transform to a local transform
+func toWorldTransform(parent *components.Transform2D, local *components.LocalTransform2D) components.Transform2D {
+	offset := raylib.Vector2Multiply(local.Position, parent.Scale)
+	offset = raylib.Vector2Rotate(offset, parent.Rotation*raylib.Deg2rad)
+
+	return components.Transform2D{
+		Position: raylib.Vector2Add(parent.Position, offset),
+		Rotation: parent.Rotation + local.Rotation,
+		Scale:    raylib.Vector2Multiply(parent.Scale, local.Scale),
+	}
+}
+
+// toLocalTransform returns the local transform that places a child at its world transform under the parent
+func toLocalTransform(parent *components.Transform2D, world *components.Transform2D) *components.LocalTransform2D {
+	offset := raylib.Vector2Subtract(world.Position, parent.Position)
+	offset = raylib.Vector2Rotate(offset, -parent.Rotation*raylib.Deg2rad)
+
+	return &components.LocalTransform2D{
+		Position: raylib.NewVector2(safeDivide(offset.X, parent.Scale.X), safeDivide(offset.Y, parent.Scale.Y)),
+		Rotation: world.Rotation - parent.Rotation,
+		Scale:    raylib.NewVector2(safeDivide(world.Scale.X, parent.Scale.X), safeDivide(world.Scale.Y, parent.Scale.Y)),
+	}
+}
+
+// safeDivide divides a by b, returning a when b is 0
+func safeDivide(a float32, b float32) float32 {
+	if b == 0 {
+		return a
+	}
+
+	return a / b
+}
@@ -0,0 +1,48 @@
+package ecs
+
+import (
+	"testing"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/components"
+)
+
+// A child with only a local transform gets its Transform2D from SetParent, propagating never adds one
+func TestSetParentGivesChildATransform(t *testing.T) {
+	em := NewECSManager()
+
+	parent := em.CreateEntity().ID
+	Add(em, parent, &components.Transform2D{Position: raylib.NewVector2(100, 50), Scale: raylib.NewVector2(1, 1)})
+
+	child := em.CreateEntity().ID
+	Add(em, child, &components.LocalTransform2D{Position: raylib.NewVector2(10, 0), Scale: raylib.NewVector2(1, 1)})
+
+	if err := em.SetParent(child, parent); err != nil {
+		t.Fatalf("SetParent: %v", err)
+	}
+
+	childTransform, childTransformExists := Get[components.Transform2D](em, child)
+	if !childTransformExists {
+		t.Fatal("SetParent did not give the child a Transform2D")
+	}
+	if childTransform.Position != raylib.NewVector2(110, 50) {
+		t.Fatalf("child position = %v, want {110 50}", childTransform.Position)
+	}
+
+	// Move the parent, the child follows
+	parentTransform, _ := Get[components.Transform2D](em, parent)
+	parentTransform.Position = raylib.NewVector2(0, 0)
+	em.PropagateTransforms()
+
+	if childTransform.Position != raylib.NewVector2(10, 0) {
+		t.Fatalf("child position after moving the parent = %v, want {10 0}", childTransform.Position)
+	}
+
+	// A child whose Transform2D was removed is skipped instead of being given a new one
+	Remove[components.Transform2D](em, child)
+	em.PropagateTransforms()
+
+	if Has[components.Transform2D](em, child) {
+		t.Fatal("PropagateTransforms added a Transform2D")
+	}
+}
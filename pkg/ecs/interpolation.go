@@ -0,0 +1,83 @@
+package ecs
+
+import (
+	"math"
+	"sync"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/components"
+)
+
+// transformState is the position and rotation of a Transform2D at the start of a logic step
+type transformState struct {
+	position raylib.Vector2
+	rotation float32
+}
+
+// transformHistory keeps the previous state of every Transform2D so rendering
+// can interpolate between the last two logic steps
+type transformHistory struct {
+	previous     map[uint64]transformState
+	historyMutex sync.RWMutex
+}
+
+// newTransformHistory initializes and returns a new transformHistory
+func newTransformHistory() *transformHistory {
+	return &transformHistory{
+		previous: make(map[uint64]transformState),
+	}
+}
+
+// SnapshotTransforms stores the current state of every Transform2D as the previous state.
+// It is called at the start of every logic step.
+func (em *ECSManager) SnapshotTransforms() {
+	em.transformHistory.historyMutex.Lock()
+	defer em.transformHistory.historyMutex.Unlock()
+
+	clear(em.transformHistory.previous)
+
+	for eID, transform := range Query1[components.Transform2D](em) {
+		em.transformHistory.previous[eID] = transformState{
+			position: transform.Position,
+			rotation: transform.Rotation,
+		}
+	}
+}
+
+// GetInterpolationAlpha returns how far rendering is between the last two logic steps, from 0 to 1
+func (em *ECSManager) GetInterpolationAlpha() float64 {
+	return em.loop.GetAlpha()
+}
+
+// GetInterpolatedTransform returns the position and rotation of an entity's Transform2D
+// interpolated between the previous and the current logic step.
+// Entities created or teleported during the last step are returned as is.
+func (em *ECSManager) GetInterpolatedTransform(eID uint64, t *components.Transform2D) (raylib.Vector2, float32) {
+	em.transformHistory.historyMutex.RLock()
+	defer em.transformHistory.historyMutex.RUnlock()
+
+	previous, previousExists := em.transformHistory.previous[eID]
+	if !previousExists {
+		return t.Position, t.Rotation
+	}
+
+	alpha := float32(em.loop.GetAlpha())
+
+	position := raylib.Vector2Lerp(previous.position, t.Position, alpha)
+
+	// Turn the short way round, 359 to 1 degrees is 2 degrees and not -358
+	turn := float32(math.Remainder(float64(t.Rotation-previous.rotation), 360))
+	rotation := previous.rotation + turn*alpha
+
+	return position, rotation
+}
+
+// SkipInterpolation renders an entity at its current Transform2D until the next logic step.
+// It is used when an entity jumps, e.g. when it wraps around the world bounds, so rendering doesn't
+// smear it across everything between the two positions.
+func (em *ECSManager) SkipInterpolation(eID uint64) {
+	em.transformHistory.historyMutex.Lock()
+	defer em.transformHistory.historyMutex.Unlock()
+
+	delete(em.transformHistory.previous, eID)
+}
@@ -0,0 +1,72 @@
+package ecs
+
+import (
+	"math"
+	"testing"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/components"
+)
+
+// stepTransform runs one logic step moving an entity to a new Transform2D, then advances
+// rendering halfway to the next step
+func stepTransform(em *ECSManager, eID uint64, position raylib.Vector2, rotation float32) *components.Transform2D {
+	em.SnapshotTransforms()
+
+	transform, _ := Get[components.Transform2D](em, eID)
+	transform.Position = position
+	transform.Rotation = rotation
+
+	em.GetLoop().Reset()
+	em.GetLoop().Advance(em.GetLoop().GetFixedDelta()/2, func(dt float64) {})
+
+	return transform
+}
+
+// Rotations turn the short way round when they cross 0 degrees
+func TestInterpolatedRotationTakesShortestTurn(t *testing.T) {
+	em := NewECSManager()
+	eID := em.CreateEntity().ID
+	Add(em, eID, &components.Transform2D{Rotation: 350, Scale: raylib.NewVector2(1, 1)})
+
+	for _, test := range []struct {
+		from, to, want float32
+	}{
+		{from: 350, to: 10, want: 360},
+		{from: 10, to: 350, want: 0},
+		{from: 90, to: 100, want: 95},
+		{from: 300, to: 100, want: 380},
+	} {
+		transform, _ := Get[components.Transform2D](em, eID)
+		transform.Rotation = test.from
+		transform = stepTransform(em, eID, raylib.Vector2{}, test.to)
+
+		_, rotation := em.GetInterpolatedTransform(eID, transform)
+		if math.Abs(float64(rotation-test.want)) > 1e-3 {
+			t.Fatalf("%v to %v interpolated to %v, want %v", test.from, test.to, rotation, test.want)
+		}
+	}
+}
+
+// An entity skipping interpolation is rendered where it is until the next step
+func TestSkipInterpolationRendersCurrentTransform(t *testing.T) {
+	em := NewECSManager()
+	eID := em.CreateEntity().ID
+	Add(em, eID, &components.Transform2D{Position: raylib.NewVector2(1270, 0), Scale: raylib.NewVector2(1, 1)})
+
+	transform := stepTransform(em, eID, raylib.NewVector2(10, 0), 0)
+	if position, _ := em.GetInterpolatedTransform(eID, transform); position.X != 640 {
+		t.Fatalf("interpolated position = %v, want halfway at 640", position)
+	}
+
+	em.SkipInterpolation(eID)
+	if position, _ := em.GetInterpolatedTransform(eID, transform); position != transform.Position {
+		t.Fatalf("interpolated position = %v, want the current position %v", position, transform.Position)
+	}
+
+	// The next step interpolates again
+	transform = stepTransform(em, eID, raylib.NewVector2(20, 0), 0)
+	if position, _ := em.GetInterpolatedTransform(eID, transform); position.X != 15 {
+		t.Fatalf("interpolated position = %v, want halfway at 15", position)
+	}
+}
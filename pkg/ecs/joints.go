@@ -0,0 +1,97 @@
+package ecs
+
+import (
+	"fmt"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/components"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+)
+
+// AddJoint adds a joint between an entity and the entity it connects to, or the world if Connected is 0.
+// Welds keep the rotation the bodies have when the joint is added.
+func (em *ECSManager) AddJoint(eID uint64, joint physicscomponents.Joint) error {
+	if !joint.Type.IsValid() {
+		return fmt.Errorf("AddJoint: unknown joint type %q", joint.Type)
+	}
+
+	if !em.IsAlive(eID) {
+		return fmt.Errorf("AddJoint: entity %d is not alive", eID)
+	}
+
+	if joint.Connected == eID {
+		return fmt.Errorf("AddJoint: entity %d can't be connected to itself", eID)
+	}
+
+	if joint.Connected != 0 && !em.IsAlive(joint.Connected) {
+		return fmt.Errorf("AddJoint: connected entity %d is not alive", joint.Connected)
+	}
+
+	if joint.Length < 0 {
+		return fmt.Errorf("AddJoint: length %v is negative", joint.Length)
+	}
+
+	if joint.Type == physicscomponents.WeldJoint {
+		joint.ReferenceRotation = em.getJointRotation(joint.Connected) - em.getJointRotation(eID)
+	}
+
+	joints, jointsExists := Get[physicscomponents.Joints](em, eID)
+	if !jointsExists {
+		Add(em, eID, &physicscomponents.Joints{Entries: []physicscomponents.Joint{joint}})
+		return nil
+	}
+
+	joints.Entries = append(joints.Entries, joint)
+
+	return nil
+}
+
+// RemoveJoints removes every joint of an entity, joints of other entities connected to it are kept
+func (em *ECSManager) RemoveJoints(eID uint64) {
+	Remove[physicscomponents.Joints](em, eID)
+}
+
+// GetJointAnchors returns the anchors of a joint of an entity in world space,
+// or false if the entity or the connected entity has no Transform2D
+func (em *ECSManager) GetJointAnchors(eID uint64, joint *physicscomponents.Joint) (raylib.Vector2, raylib.Vector2, bool) {
+	anchor, anchorExists := em.getJointAnchor(eID, joint.Anchor)
+	if !anchorExists {
+		return raylib.Vector2{}, raylib.Vector2{}, false
+	}
+
+	// The world anchor is already in world space
+	if joint.Connected == 0 {
+		return anchor, joint.ConnectedAnchor, true
+	}
+
+	connectedAnchor, connectedAnchorExists := em.getJointAnchor(joint.Connected, joint.ConnectedAnchor)
+	if !connectedAnchorExists {
+		return raylib.Vector2{}, raylib.Vector2{}, false
+	}
+
+	return anchor, connectedAnchor, true
+}
+
+// getJointAnchor turns an anchor relative to an entity into world space
+func (em *ECSManager) getJointAnchor(eID uint64, anchor raylib.Vector2) (raylib.Vector2, bool) {
+	transform, transformExists := Get[components.Transform2D](em, eID)
+	if !transformExists {
+		return raylib.Vector2{}, false
+	}
+
+	return raylib.Vector2Add(transform.Position, raylib.Vector2Rotate(anchor, transform.Rotation*raylib.Deg2rad)), true
+}
+
+// getJointRotation returns the rotation of an entity, the world has none
+func (em *ECSManager) getJointRotation(eID uint64) float32 {
+	if eID == 0 {
+		return 0
+	}
+
+	transform, transformExists := Get[components.Transform2D](em, eID)
+	if !transformExists {
+		return 0
+	}
+
+	return transform.Rotation
+}
@@ -0,0 +1,414 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+)
+
+// DefaultPrefabDirectory is the directory prefab files are loaded from
+const DefaultPrefabDirectory = "assets/prefabs"
+
+// ErrUnknownPrefab is returned when a prefab is neither registered nor found in the prefab directory
+var ErrUnknownPrefab = errors.New("unknown prefab")
+
+// PrefabData is a named set of components that entities can be instantiated from.
+// A prefab can be based on another prefab, its components are then applied as overrides on top of the base.
+type PrefabData struct {
+	Name       string                     `json:"name"`
+	Prefab     string                     `json:"prefab,omitempty"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// PrefabsManager loads prefabs by name from the prefab directory and keeps them for later instantiations
+type PrefabsManager struct {
+	directory   string
+	prefabs     map[string]*PrefabData
+	prefabMutex sync.RWMutex
+}
+
+// NewPrefabsManager initializes and returns a new PrefabsManager loading prefabs from a directory
+func NewPrefabsManager(directory string) *PrefabsManager {
+	return &PrefabsManager{
+		directory: directory,
+		prefabs:   make(map[string]*PrefabData),
+	}
+}
+
+// SetDirectory changes the directory prefab files are loaded from and forgets the prefabs loaded so far
+func (pm *PrefabsManager) SetDirectory(directory string) {
+	pm.prefabMutex.Lock()
+	defer pm.prefabMutex.Unlock()
+
+	pm.directory = directory
+	clear(pm.prefabs)
+}
+
+// RegisterPrefab registers a prefab by its name, replacing any prefab loaded under the same name
+func (pm *PrefabsManager) RegisterPrefab(pd *PrefabData) {
+	pm.prefabMutex.Lock()
+	defer pm.prefabMutex.Unlock()
+
+	pm.prefabs[pd.Name] = pd
+}
+
+// GetPrefab returns a registered prefab, loading it from <directory>/<name>.json the first time it is requested
+func (pm *PrefabsManager) GetPrefab(name string) (*PrefabData, error) {
+	pm.prefabMutex.RLock()
+	pd, prefabExists := pm.prefabs[name]
+	directory := pm.directory
+	pm.prefabMutex.RUnlock()
+
+	if prefabExists {
+		return pd, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(directory, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPrefab, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pd = &PrefabData{}
+	if err := json.Unmarshal(data, pd); err != nil {
+		return nil, fmt.Errorf("prefab %s: %w", name, err)
+	}
+
+	// The file name is the name the prefab is referenced by
+	pd.Name = name
+	pm.RegisterPrefab(pd)
+
+	return pd, nil
+}
+
+// ResolveComponents returns the components of a prefab with its base prefabs and the overrides applied.
+// Overrides are merged into the prefab components field by field, a null override removes the component.
+func (pm *PrefabsManager) ResolveComponents(name string, overrides map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	comps, err := pm.resolve(name, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeComponents(comps, overrides)
+}
+
+// resolve walks the chain of base prefabs, chain holds the prefabs being resolved to detect cycles
+func (pm *PrefabsManager) resolve(name string, chain []string) (map[string]json.RawMessage, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return nil, fmt.Errorf("prefab %s: cycle through %s", name, strings.Join(append(chain, name), " -> "))
+		}
+	}
+
+	pd, err := pm.GetPrefab(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A prefab without a base starts from an empty set of components
+	if pd.Prefab == "" {
+		return mergeComponents(map[string]json.RawMessage{}, pd.Components)
+	}
+
+	base, err := pm.resolve(pd.Prefab, append(chain, name))
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeComponents(base, pd.Components)
+}
+
+// * Instantiation
+
+// Instantiate creates an entity from a prefab with the overrides applied.
+// Every component is decoded before the entity is created, so nothing is created if one of them fails.
+func (em *ECSManager) Instantiate(prefab string, overrides map[string]json.RawMessage) (*Entity, error) {
+	decoded, err := em.decodePrefab(prefab, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := em.CreateEntity()
+
+	for _, dc := range decoded {
+		dc.codec.add(em, entity.ID, dc.component)
+	}
+	Add(em, entity.ID, &components.PrefabInstance{Name: prefab})
+
+	return entity, nil
+}
+
+// Instantiate queues the creation of an entity from a prefab with the overrides applied.
+// The components are decoded right away so errors are returned to the caller instead of the flush.
+func (cb *CommandBuffer) Instantiate(prefab string, overrides map[string]json.RawMessage) (*Entity, error) {
+	decoded, err := cb.ecsManager.decodePrefab(prefab, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := cb.CreateEntity()
+
+	for _, dc := range decoded {
+		cb.record(command{kind: addCodecComponentCommand, entity: entity, codec: dc.codec, component: dc.component})
+	}
+	cb.AddComponent(entity.ID, PrefabInstanceComponent, &components.PrefabInstance{Name: prefab})
+
+	return entity, nil
+}
+
+// GetPrefabsManager returns the manager prefabs are instantiated from
+func (em *ECSManager) GetPrefabsManager() *PrefabsManager {
+	return em.prefabsManager
+}
+
+// decodedComponent is a component decoded from JSON together with the codec that adds it
+type decodedComponent struct {
+	codec     *ComponentCodec
+	component Component
+}
+
+// decodePrefab resolves a prefab and decodes its components in name order
+func (em *ECSManager) decodePrefab(prefab string, overrides map[string]json.RawMessage) ([]decodedComponent, error) {
+	comps, err := em.prefabsManager.ResolveComponents(prefab, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(comps))
+	for name := range comps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	decoded := make([]decodedComponent, 0, len(names))
+	for _, name := range names {
+		codec, codecExists := GetComponentCodec(name)
+		if !codecExists {
+			return nil, fmt.Errorf("prefab %s: %w: %s", prefab, ErrUnknownComponent, name)
+		}
+
+		c, err := codec.Decode(em, comps[name])
+		if err != nil {
+			return nil, fmt.Errorf("prefab %s: %s: %w", prefab, name, err)
+		}
+
+		decoded = append(decoded, decodedComponent{codec: codec, component: c})
+	}
+
+	return decoded, nil
+}
+
+// EncodePrefabOverrides encodes the components of an entity instantiated from a prefab as overrides of that prefab.
+// Only the fields that differ from the prefab are kept, components removed since are overridden with null.
+// It returns an empty prefab name if the entity was not instantiated from a prefab.
+func (em *ECSManager) EncodePrefabOverrides(eID uint64) (string, map[string]json.RawMessage, error) {
+	instance, instanceExists := Get[components.PrefabInstance](em, eID)
+	if !instanceExists {
+		return "", nil, nil
+	}
+
+	current, err := em.EncodeComponents(eID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	prefabComps, err := em.prefabsManager.ResolveComponents(instance.Name, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	overrides := make(map[string]json.RawMessage)
+
+	for name, data := range prefabComps {
+
+		// Components removed from the entity are overridden with null
+		if _, compExists := current[name]; !compExists {
+			overrides[name] = json.RawMessage("null")
+			continue
+		}
+
+		// Compare against the prefab component as the codec would encode it, so e.g. color names match
+		base, err := em.canonicalComponent(name, data)
+		if err != nil {
+			return "", nil, fmt.Errorf("prefab %s: %s: %w", instance.Name, name, err)
+		}
+
+		diff, changed, err := diffJSON(base, current[name])
+		if err != nil {
+			return "", nil, err
+		}
+		if changed {
+			overrides[name] = diff
+		}
+	}
+
+	// Components the prefab does not have are kept whole
+	for name, data := range current {
+		if _, compExists := prefabComps[name]; !compExists {
+			overrides[name] = data
+		}
+	}
+
+	return instance.Name, overrides, nil
+}
+
+// canonicalComponent decodes and encodes a component through its codec
+func (em *ECSManager) canonicalComponent(name string, data json.RawMessage) (json.RawMessage, error) {
+	codec, codecExists := GetComponentCodec(name)
+	if !codecExists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownComponent, name)
+	}
+
+	c, err := codec.Decode(em, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Encode(em, c)
+}
+
+// * JSON merging
+
+// MakeOverrides marshals the override values of each component, e.g. a map of the fields to change
+func MakeOverrides(values map[string]any) (map[string]json.RawMessage, error) {
+	overrides := make(map[string]json.RawMessage, len(values))
+
+	for name, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("override %s: %w", name, err)
+		}
+
+		overrides[name] = data
+	}
+
+	return overrides, nil
+}
+
+// mergeComponents returns a copy of comps with the overrides merged into it
+func mergeComponents(comps map[string]json.RawMessage, overrides map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	merged := make(map[string]json.RawMessage, len(comps)+len(overrides))
+	for name, data := range comps {
+		merged[name] = data
+	}
+
+	for name, override := range overrides {
+
+		// A null override removes the component
+		if isNullJSON(override) {
+			delete(merged, name)
+			continue
+		}
+
+		base, baseExists := merged[name]
+		if !baseExists {
+			merged[name] = override
+			continue
+		}
+
+		data, err := mergeJSON(base, override)
+		if err != nil {
+			return nil, fmt.Errorf("override %s: %w", name, err)
+		}
+
+		merged[name] = data
+	}
+
+	return merged, nil
+}
+
+// mergeJSON merges override into base. Objects are merged key by key, null removes a key
+// and any other value replaces the value in base.
+func mergeJSON(base json.RawMessage, override json.RawMessage) (json.RawMessage, error) {
+	var baseValue, overrideValue any
+
+	if err := json.Unmarshal(base, &baseValue); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(override, &overrideValue); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeValues(baseValue, overrideValue))
+}
+
+func mergeValues(base any, override any) any {
+	baseObject, baseIsObject := base.(map[string]any)
+	overrideObject, overrideIsObject := override.(map[string]any)
+
+	if !baseIsObject || !overrideIsObject {
+		return override
+	}
+
+	for key, value := range overrideObject {
+		if value == nil {
+			delete(baseObject, key)
+			continue
+		}
+
+		baseObject[key] = mergeValues(baseObject[key], value)
+	}
+
+	return baseObject
+}
+
+// diffJSON returns the smallest override that turns base into current, and whether there is any difference
+func diffJSON(base json.RawMessage, current json.RawMessage) (json.RawMessage, bool, error) {
+	var baseValue, currentValue any
+
+	if err := json.Unmarshal(base, &baseValue); err != nil {
+		return nil, false, err
+	}
+	if err := json.Unmarshal(current, &currentValue); err != nil {
+		return nil, false, err
+	}
+
+	diff, changed := diffValues(baseValue, currentValue)
+	if !changed {
+		return nil, false, nil
+	}
+
+	data, err := json.Marshal(diff)
+	return data, true, err
+}
+
+func diffValues(base any, current any) (any, bool) {
+	baseObject, baseIsObject := base.(map[string]any)
+	currentObject, currentIsObject := current.(map[string]any)
+
+	if !baseIsObject || !currentIsObject {
+		return current, !reflect.DeepEqual(base, current)
+	}
+
+	diff := make(map[string]any)
+
+	for key, value := range currentObject {
+		if keyDiff, changed := diffValues(baseObject[key], value); changed {
+			diff[key] = keyDiff
+		}
+	}
+
+	// Keys only the base has are removed with null
+	for key := range baseObject {
+		if _, keyExists := currentObject[key]; !keyExists {
+			diff[key] = nil
+		}
+	}
+
+	return diff, len(diff) > 0
+}
+
+func isNullJSON(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
@@ -0,0 +1,578 @@
+package ecs
+
+import (
+	"fmt"
+	"time"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/physics"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+)
+
+// JSON representations of the built-in components as they appear in scene files
+
+type transform2DData struct {
+	Position Vector2Data `json:"position"`
+	Rotation float32     `json:"rotation"`
+	Scale    Vector2Data `json:"scale"`
+}
+
+type spriteData struct {
+	TexturePath string        `json:"texture_path"`
+	SourceRect  RectangleData `json:"sourceRect"`
+	DestRect    RectangleData `json:"destRect"`
+	Origin      Vector2Data   `json:"origin"`
+	Rotation    float32       `json:"rotation"`
+	Color       ColorData     `json:"color"`
+}
+
+type colorData struct {
+	Color ColorData `json:"color"`
+}
+
+type playerData struct {
+	Name string `json:"name"`
+}
+
+type speedData struct {
+	Value float32 `json:"value"`
+}
+
+type velocityData struct {
+	Vector Vector2Data `json:"vector"`
+}
+
+type animationData struct {
+	Frames          []RectangleData `json:"frames"`
+	CurrentFrame    int             `json:"current_frame"`
+	FrameDurationMs int64           `json:"frame_duration_ms"`
+	IsLooping       bool            `json:"is_looping"`
+	IsPlaying       bool            `json:"is_playing"`
+}
+
+type audioSourceData struct {
+	FilePath        string  `json:"file_path"`
+	Volume          float32 `json:"volume"`
+	IsLooping       bool    `json:"is_looping"`
+	PlayOnCollision bool    `json:"play_on_collision,omitempty"`
+}
+
+type particleEmitterData struct {
+	EmitRate           int   `json:"emit_rate"`
+	ParticleLifetimeMs int64 `json:"particle_lifetime_ms"`
+	IsEmitting         bool  `json:"is_emitting"`
+}
+
+type rigidBodyData struct {
+	Mass         float32     `json:"mass"`
+	Velocity     Vector2Data `json:"velocity"`
+	Acceleration Vector2Data `json:"acceleration"`
+	Drag         float32     `json:"drag"`
+	Restitution  float32     `json:"restitution"`
+	IsKinematic  bool        `json:"is_kinematic"`
+	IsStatic     bool        `json:"is_static"`
+	IsContinuous bool        `json:"is_continuous,omitempty"`
+
+	// Angular velocity in degrees per second, an inertia of 0 is computed from the collider
+	AngularVelocity float32 `json:"angular_velocity,omitempty"`
+	AngularDrag     float32 `json:"angular_drag,omitempty"`
+	Inertia         float32 `json:"inertia,omitempty"`
+	FixedRotation   bool    `json:"fixed_rotation,omitempty"`
+
+	IsSleeping bool `json:"is_sleeping,omitempty"`
+	NeverSleep bool `json:"never_sleep,omitempty"`
+
+	// Friction coefficients left out use the defaults
+	StaticFriction  *float32 `json:"static_friction,omitempty"`
+	DynamicFriction *float32 `json:"dynamic_friction,omitempty"`
+}
+
+// Settings shared by every collider. Layers and mask are names from the collision layer table,
+// leaving them out puts the collider on the default layer and makes it collide with every layer
+type colliderData struct {
+	IsTrigger bool      `json:"is_trigger,omitempty"`
+	Layers    *[]string `json:"layers,omitempty"`
+	Mask      *[]string `json:"mask,omitempty"`
+}
+
+type boxColliderData struct {
+	Type string      `json:"type"`
+	Size Vector2Data `json:"size"`
+	colliderData
+}
+
+type circleColliderData struct {
+	Radius float32 `json:"radius"`
+	colliderData
+}
+
+type capsuleColliderData struct {
+	Radius float32 `json:"radius"`
+	Height float32 `json:"height"`
+	colliderData
+}
+
+type polygonColliderData struct {
+	Points []Vector2Data `json:"points"`
+	colliderData
+}
+
+type sceneTriggerData struct {
+	Event     string `json:"event"`
+	ScenePath string `json:"scene_path,omitempty"`
+}
+
+type uiPanelData struct {
+	Title     string        `json:"title"`
+	Bounds    RectangleData `json:"bounds"`
+	IsVisible bool          `json:"is_visible"`
+}
+
+type uiButtonData struct {
+	Text      string        `json:"text"`
+	Bounds    RectangleData `json:"bounds"`
+	IsVisible bool          `json:"is_visible"`
+	Event     string        `json:"event,omitempty"`
+	ScenePath string        `json:"scene_path,omitempty"`
+}
+
+type uiOverlayData struct {
+	Color     ColorData `json:"color"`
+	IsVisible bool      `json:"is_visible"`
+}
+
+type uiLabelData struct {
+	Label     string        `json:"label"`
+	Bounds    RectangleData `json:"bounds"`
+	IsVisible bool          `json:"is_visible"`
+}
+
+// encodeFriction returns a friction coefficient, or nil if it is the default
+func encodeFriction(friction float32, defaultFriction float32) *float32 {
+	if friction == defaultFriction {
+		return nil
+	}
+
+	return &friction
+}
+
+// decodeCollider returns the collider settings, naming the layers with the collision layers of the world
+func decodeCollider(em *ECSManager, d *colliderData) (physicscomponents.Collider, error) {
+	collider := physicscomponents.NewCollider()
+	collider.IsTrigger = d.IsTrigger
+
+	var err error
+	if collider.Layer, err = decodeCollisionLayers(em, d.Layers, collider.Layer); err != nil {
+		return collider, fmt.Errorf("layers: %w", err)
+	}
+	if collider.Mask, err = decodeCollisionLayers(em, d.Mask, collider.Mask); err != nil {
+		return collider, fmt.Errorf("mask: %w", err)
+	}
+
+	return collider, nil
+}
+
+// newColliderData returns the JSON representation of collider settings
+func newColliderData(em *ECSManager, c *physicscomponents.Collider) colliderData {
+	return colliderData{
+		IsTrigger: c.IsTrigger,
+		Layers:    encodeCollisionLayers(em, c.Layer, physicscomponents.DefaultCollisionLayer),
+		Mask:      encodeCollisionLayers(em, c.Mask, physicscomponents.AllCollisionLayers),
+	}
+}
+
+// decodeCollisionLayers combines named collision layers, returning defaultMask if they are left out
+func decodeCollisionLayers(em *ECSManager, names *[]string, defaultMask uint32) (uint32, error) {
+	if names == nil {
+		return defaultMask, nil
+	}
+
+	return em.GetCollisionLayers().GetMask(*names)
+}
+
+// encodeCollisionLayers returns the names of the layers in a mask, or nil if it is the default
+func encodeCollisionLayers(em *ECSManager, mask uint32, defaultMask uint32) *[]string {
+	if mask == defaultMask {
+		return nil
+	}
+
+	names := em.GetCollisionLayers().GetNames(mask)
+	return &names
+}
+
+func init() {
+
+	// * Components
+
+	RegisterComponent("Transform2D",
+		func(d *transform2DData) *components.Transform2D {
+			return &components.Transform2D{
+				Position: d.Position.Vector2(),
+				Rotation: d.Rotation,
+				Scale:    d.Scale.Vector2(),
+			}
+		},
+		func(c *components.Transform2D) *transform2DData {
+			return &transform2DData{
+				Position: NewVector2Data(c.Position),
+				Rotation: c.Rotation,
+				Scale:    NewVector2Data(c.Scale),
+			}
+		},
+	)
+
+	// The world Transform2D of a child is derived from its parent and its LocalTransform2D
+	RegisterComponent("LocalTransform2D",
+		func(d *transform2DData) *components.LocalTransform2D {
+			return &components.LocalTransform2D{
+				Position: d.Position.Vector2(),
+				Rotation: d.Rotation,
+				Scale:    d.Scale.Vector2(),
+			}
+		},
+		func(c *components.LocalTransform2D) *transform2DData {
+			return &transform2DData{
+				Position: NewVector2Data(c.Position),
+				Rotation: c.Rotation,
+				Scale:    NewVector2Data(c.Scale),
+			}
+		},
+	)
+
+	RegisterComponent("Sprite",
+		func(d *spriteData) *components.Sprite {
+			return &components.Sprite{
+				TexturePath: d.TexturePath,
+				SourceRect:  d.SourceRect.Rectangle(),
+				DestRect:    d.DestRect.Rectangle(),
+				Origin:      d.Origin.Vector2(),
+				Rotation:    d.Rotation,
+				Color:       d.Color.Color(),
+			}
+		},
+		func(c *components.Sprite) *spriteData {
+			return &spriteData{
+				TexturePath: c.TexturePath,
+				SourceRect:  NewRectangleData(c.SourceRect),
+				DestRect:    NewRectangleData(c.DestRect),
+				Origin:      NewVector2Data(c.Origin),
+				Rotation:    c.Rotation,
+				Color:       NewColorData(c.Color),
+			}
+		},
+	)
+
+	RegisterComponent("Color",
+		func(d *colorData) *components.Color {
+			return &components.Color{Color: d.Color.Color()}
+		},
+		func(c *components.Color) *colorData {
+			return &colorData{Color: NewColorData(c.Color)}
+		},
+	)
+
+	RegisterComponent("Player",
+		func(d *playerData) *components.Player {
+			return &components.Player{Name: d.Name}
+		},
+		func(c *components.Player) *playerData {
+			return &playerData{Name: c.Name}
+		},
+	)
+
+	RegisterComponent("Speed",
+		func(d *speedData) *components.Speed {
+			return &components.Speed{Value: d.Value}
+		},
+		func(c *components.Speed) *speedData {
+			return &speedData{Value: c.Value}
+		},
+	)
+
+	RegisterComponent("Velocity",
+		func(d *velocityData) *components.Velocity {
+			return &components.Velocity{Vector: d.Vector.Vector2()}
+		},
+		func(c *components.Velocity) *velocityData {
+			return &velocityData{Vector: NewVector2Data(c.Vector)}
+		},
+	)
+
+	RegisterComponent("Animation",
+		func(d *animationData) *components.Animation {
+			frames := make([]raylib.Rectangle, len(d.Frames))
+			for i, frame := range d.Frames {
+				frames[i] = frame.Rectangle()
+			}
+
+			return &components.Animation{
+				Frames:        frames,
+				CurrentFrame:  d.CurrentFrame,
+				FrameDuration: time.Duration(d.FrameDurationMs) * time.Millisecond,
+				IsLooping:     d.IsLooping,
+				IsPlaying:     d.IsPlaying,
+			}
+		},
+		func(c *components.Animation) *animationData {
+			frames := make([]RectangleData, len(c.Frames))
+			for i, frame := range c.Frames {
+				frames[i] = NewRectangleData(frame)
+			}
+
+			return &animationData{
+				Frames:          frames,
+				CurrentFrame:    c.CurrentFrame,
+				FrameDurationMs: c.FrameDuration.Milliseconds(),
+				IsLooping:       c.IsLooping,
+				IsPlaying:       c.IsPlaying,
+			}
+		},
+	)
+
+	// The sound itself is loaded by the AudioSystem once an audio device is available
+	RegisterComponent("AudioSource",
+		func(d *audioSourceData) *components.AudioSource {
+			return &components.AudioSource{
+				FilePath:        d.FilePath,
+				Volume:          d.Volume,
+				IsLooping:       d.IsLooping,
+				PlayOnCollision: d.PlayOnCollision,
+			}
+		},
+		func(c *components.AudioSource) *audioSourceData {
+			return &audioSourceData{
+				FilePath:        c.FilePath,
+				Volume:          c.Volume,
+				IsLooping:       c.IsLooping,
+				PlayOnCollision: c.PlayOnCollision,
+			}
+		},
+	)
+
+	RegisterComponent("ParticleEmitter",
+		func(d *particleEmitterData) *components.ParticleEmitter {
+			return &components.ParticleEmitter{
+				Particles:        []*components.Particle{},
+				EmitRate:         d.EmitRate,
+				ParticleLifetime: time.Duration(d.ParticleLifetimeMs) * time.Millisecond,
+				LastEmitTime:     time.Now(),
+				IsEmitting:       d.IsEmitting,
+			}
+		},
+		func(c *components.ParticleEmitter) *particleEmitterData {
+			return &particleEmitterData{
+				EmitRate:           c.EmitRate,
+				ParticleLifetimeMs: c.ParticleLifetime.Milliseconds(),
+				IsEmitting:         c.IsEmitting,
+			}
+		},
+	)
+
+	// * Physics Components
+
+	RegisterComponent("RigidBody",
+		func(d *rigidBodyData) *physicscomponents.RigidBody {
+			rb := physicscomponents.NewRigidBody(d.Mass, d.Drag, d.Restitution, d.IsKinematic, d.IsStatic)
+			rb.Velocity = d.Velocity.Vector2()
+			rb.Acceleration = d.Acceleration.Vector2()
+			rb.IsContinuous = d.IsContinuous
+			rb.AngularVelocity = d.AngularVelocity
+			rb.AngularDrag = d.AngularDrag
+			rb.Inertia = d.Inertia
+			rb.FixedRotation = d.FixedRotation
+			rb.IsSleeping = d.IsSleeping
+			rb.NeverSleep = d.NeverSleep
+
+			if d.StaticFriction != nil {
+				rb.StaticFriction = *d.StaticFriction
+			}
+			if d.DynamicFriction != nil {
+				rb.DynamicFriction = *d.DynamicFriction
+			}
+
+			return rb
+		},
+		func(c *physicscomponents.RigidBody) *rigidBodyData {
+			return &rigidBodyData{
+				Mass:         c.Mass,
+				Velocity:     NewVector2Data(c.Velocity),
+				Acceleration: NewVector2Data(c.Acceleration),
+				Drag:         c.Drag,
+				Restitution:  c.Restitution,
+				IsKinematic:  c.IsKinematic,
+				IsStatic:     c.IsStatic,
+				IsContinuous: c.IsContinuous,
+
+				AngularVelocity: c.AngularVelocity,
+				AngularDrag:     c.AngularDrag,
+				Inertia:         c.Inertia,
+				FixedRotation:   c.FixedRotation,
+
+				IsSleeping: c.IsSleeping,
+				NeverSleep: c.NeverSleep,
+
+				StaticFriction:  encodeFriction(c.StaticFriction, physicscomponents.DefaultStaticFriction),
+				DynamicFriction: encodeFriction(c.DynamicFriction, physicscomponents.DefaultDynamicFriction),
+			}
+		},
+	)
+
+	RegisterWorldComponent("BoxCollider",
+		func(em *ECSManager, d *boxColliderData) (*physicscomponents.BoxCollider, error) {
+			collider, err := decodeCollider(em, &d.colliderData)
+			if err != nil {
+				return nil, err
+			}
+
+			return &physicscomponents.BoxCollider{
+				Type:     d.Type,
+				Size:     d.Size.Vector2(),
+				Collider: collider,
+			}, nil
+		},
+		func(em *ECSManager, c *physicscomponents.BoxCollider) *boxColliderData {
+			return &boxColliderData{
+				Type:         c.Type,
+				Size:         NewVector2Data(c.Size),
+				colliderData: newColliderData(em, &c.Collider),
+			}
+		},
+	)
+
+	RegisterWorldComponent("CircleCollider",
+		func(em *ECSManager, d *circleColliderData) (*physicscomponents.CircleCollider, error) {
+			collider, err := decodeCollider(em, &d.colliderData)
+			if err != nil {
+				return nil, err
+			}
+
+			return &physicscomponents.CircleCollider{
+				Radius:   d.Radius,
+				Collider: collider,
+			}, nil
+		},
+		func(em *ECSManager, c *physicscomponents.CircleCollider) *circleColliderData {
+			return &circleColliderData{
+				Radius:       c.Radius,
+				colliderData: newColliderData(em, &c.Collider),
+			}
+		},
+	)
+
+	RegisterWorldComponent("CapsuleCollider",
+		func(em *ECSManager, d *capsuleColliderData) (*physicscomponents.CapsuleCollider, error) {
+			collider, err := decodeCollider(em, &d.colliderData)
+			if err != nil {
+				return nil, err
+			}
+
+			return &physicscomponents.CapsuleCollider{
+				Radius:   d.Radius,
+				Height:   d.Height,
+				Collider: collider,
+			}, nil
+		},
+		func(em *ECSManager, c *physicscomponents.CapsuleCollider) *capsuleColliderData {
+			return &capsuleColliderData{
+				Radius:       c.Radius,
+				Height:       c.Height,
+				colliderData: newColliderData(em, &c.Collider),
+			}
+		},
+	)
+
+	RegisterWorldComponent("PolygonCollider",
+		func(em *ECSManager, d *polygonColliderData) (*physicscomponents.PolygonCollider, error) {
+			collider, err := decodeCollider(em, &d.colliderData)
+			if err != nil {
+				return nil, err
+			}
+
+			points := make([]raylib.Vector2, len(d.Points))
+			for i, point := range d.Points {
+				points[i] = point.Vector2()
+			}
+
+			if err := physics.ValidatePolygon(points); err != nil {
+				return nil, fmt.Errorf("points: %w", err)
+			}
+
+			return &physicscomponents.PolygonCollider{
+				Points:   points,
+				Collider: collider,
+			}, nil
+		},
+		func(em *ECSManager, c *physicscomponents.PolygonCollider) *polygonColliderData {
+			points := make([]Vector2Data, len(c.Points))
+			for i, point := range c.Points {
+				points[i] = NewVector2Data(point)
+			}
+
+			return &polygonColliderData{
+				Points:       points,
+				colliderData: newColliderData(em, &c.Collider),
+			}
+		},
+	)
+
+	// Needs a trigger collider on the same entity
+	RegisterComponent("SceneTrigger",
+		func(d *sceneTriggerData) *components.SceneTrigger {
+			return &components.SceneTrigger{Event: d.Event, ScenePath: d.ScenePath}
+		},
+		func(c *components.SceneTrigger) *sceneTriggerData {
+			return &sceneTriggerData{Event: c.Event, ScenePath: c.ScenePath}
+		},
+	)
+
+	// * UI Components
+
+	RegisterUIComponent("UIPanel",
+		func(d *uiPanelData) *components.UIPanel {
+			return &components.UIPanel{Title: d.Title, Bounds: d.Bounds.Rectangle(), IsVisible: d.IsVisible}
+		},
+		func(c *components.UIPanel) *uiPanelData {
+			return &uiPanelData{Title: c.Title, Bounds: NewRectangleData(c.Bounds), IsVisible: c.IsVisible}
+		},
+	)
+
+	RegisterUIComponent("UIButton",
+		func(d *uiButtonData) *components.UIButton {
+			return &components.UIButton{
+				Text:      d.Text,
+				Bounds:    d.Bounds.Rectangle(),
+				IsVisible: d.IsVisible,
+				Event:     d.Event,
+				ScenePath: d.ScenePath,
+			}
+		},
+		func(c *components.UIButton) *uiButtonData {
+			return &uiButtonData{
+				Text:      c.Text,
+				Bounds:    NewRectangleData(c.Bounds),
+				IsVisible: c.IsVisible,
+				Event:     c.Event,
+				ScenePath: c.ScenePath,
+			}
+		},
+	)
+
+	RegisterUIComponent("UILabel",
+		func(d *uiLabelData) *components.UILabel {
+			return &components.UILabel{Label: d.Label, Bounds: d.Bounds.Rectangle(), IsVisible: d.IsVisible}
+		},
+		func(c *components.UILabel) *uiLabelData {
+			return &uiLabelData{Label: c.Label, Bounds: NewRectangleData(c.Bounds), IsVisible: c.IsVisible}
+		},
+	)
+
+	RegisterUIComponent("UIOverlay",
+		func(d *uiOverlayData) *components.UIOverlay {
+			return &components.UIOverlay{Color: d.Color.Color(), IsVisible: d.IsVisible}
+		},
+		func(c *components.UIOverlay) *uiOverlayData {
+			return &uiOverlayData{Color: NewColorData(c.Color), IsVisible: c.IsVisible}
+		},
+	)
+}
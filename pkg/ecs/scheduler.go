@@ -0,0 +1,214 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
+)
+
+// ComponentAccessSystem is implemented by logic systems that declare which component types they read and write.
+// Components added or removed through the CommandBuffer count as writes, and written components don't
+// need to be declared as read too.
+// Systems that don't implement it are assumed to touch everything and always run on their own.
+type ComponentAccessSystem interface {
+	GetReadComponents() []ComponentType
+	GetWriteComponents() []ComponentType
+}
+
+// Resource names state shared by logic systems outside of the components
+type Resource string
+
+const (
+	// PhysicsWorldResource is the dynamic tree behind the physics queries
+	PhysicsWorldResource Resource = "physics world"
+
+	// InputResource is the keyboard input sampled for the logic steps
+	InputResource Resource = "input"
+
+	// EventsResource is the events queue, events queued by systems running concurrently would arrive in any order
+	EventsResource Resource = "events"
+
+	// RandomResource is the random number generator of the platform
+	RandomResource Resource = "random"
+)
+
+// ResourceAccessSystem is implemented by logic systems that declare the resources they read and write,
+// next to their component access. The scheduler only sees what is declared, so a system touching
+// a resource has to declare it to be kept apart from the systems writing it.
+type ResourceAccessSystem interface {
+	GetReadResources() []Resource
+	GetWriteResources() []Resource
+}
+
+// SystemConflict reports two systems with the same priority that access a component type or a resource in conflicting ways.
+// Their order then only depends on the order they were added in.
+type SystemConflict struct {
+	SystemA       systeminterfaces.UpdatableSystemInterface
+	SystemB       systeminterfaces.UpdatableSystemInterface
+	ComponentType ComponentType
+	Resource      Resource
+	Priority      int
+}
+
+func (sc SystemConflict) String() string {
+	if sc.Resource != "" {
+		return fmt.Sprintf("%T and %T both access the %s resource with priority %d and at least one of them writes it", sc.SystemA, sc.SystemB, sc.Resource, sc.Priority)
+	}
+
+	return fmt.Sprintf("%T and %T both access component type %d with priority %d and at least one of them writes it", sc.SystemA, sc.SystemB, sc.ComponentType, sc.Priority)
+}
+
+// systemAccess holds the declared component and resource access of a system
+type systemAccess struct {
+	declared       bool
+	reads          ComponentMask
+	writes         ComponentMask
+	readResources  []Resource
+	writeResources []Resource
+}
+
+// getSystemAccess returns the declared access of a system
+func getSystemAccess(system systeminterfaces.UpdatableSystemInterface) systemAccess {
+	accessSystem, declared := system.(ComponentAccessSystem)
+	if !declared {
+		return systemAccess{}
+	}
+
+	access := systemAccess{
+		declared: true,
+		reads:    NewComponentMask(accessSystem.GetReadComponents()...),
+		writes:   NewComponentMask(accessSystem.GetWriteComponents()...),
+	}
+
+	if resourceSystem, resourcesDeclared := system.(ResourceAccessSystem); resourcesDeclared {
+		access.readResources = resourceSystem.GetReadResources()
+		access.writeResources = resourceSystem.GetWriteResources()
+	}
+
+	return access
+}
+
+// conflictsWith checks if two systems can't run at the same time.
+// It returns the first conflicting component type or resource when both systems declared their access.
+func (sa systemAccess) conflictsWith(other systemAccess) (ComponentType, Resource, bool) {
+	if !sa.declared || !other.declared {
+		return 0, "", true
+	}
+
+	for i := range sa.writes {
+		overlap := sa.writes[i]&(other.reads[i]|other.writes[i]) | other.writes[i]&sa.reads[i]
+		if overlap == 0 {
+			continue
+		}
+
+		// Find the lowest component type in the overlapping word
+		for bit := 0; bit < 64; bit++ {
+			if overlap&(1<<bit) != 0 {
+				return ComponentType(i*64 + bit), "", true
+			}
+		}
+	}
+
+	for _, resource := range sa.writeResources {
+		if slices.Contains(other.readResources, resource) || slices.Contains(other.writeResources, resource) {
+			return 0, resource, true
+		}
+	}
+
+	for _, resource := range other.writeResources {
+		if slices.Contains(sa.readResources, resource) {
+			return 0, resource, true
+		}
+	}
+
+	return 0, "", false
+}
+
+// buildSchedule groups logic systems, already sorted by priority, into stages.
+// A system depends on every earlier system it conflicts with and is placed in the stage after
+// the last of them, so systems in the same stage never conflict and can run concurrently.
+// Within a stage systems keep their priority order.
+func buildSchedule(systems []systeminterfaces.UpdatableSystemInterface) ([][]systeminterfaces.UpdatableSystemInterface, []SystemConflict) {
+
+	accesses := make([]systemAccess, len(systems))
+	for i, system := range systems {
+		accesses[i] = getSystemAccess(system)
+	}
+
+	levels := make([]int, len(systems))
+	conflicts := []SystemConflict{}
+	stageCount := 0
+
+	for j := range systems {
+		for i := 0; i < j; i++ {
+			ct, resource, conflict := accesses[j].conflictsWith(accesses[i])
+			if !conflict {
+				continue
+			}
+
+			// j has to run after i
+			if levels[i]+1 > levels[j] {
+				levels[j] = levels[i] + 1
+			}
+
+			// Only priority decides the order, so equal priorities make the order ambiguous
+			if accesses[i].declared && accesses[j].declared && systems[i].GetPriority() == systems[j].GetPriority() {
+				conflicts = append(conflicts, SystemConflict{
+					SystemA:       systems[i],
+					SystemB:       systems[j],
+					ComponentType: ct,
+					Resource:      resource,
+					Priority:      systems[i].GetPriority(),
+				})
+			}
+		}
+
+		if levels[j]+1 > stageCount {
+			stageCount = levels[j] + 1
+		}
+	}
+
+	stages := make([][]systeminterfaces.UpdatableSystemInterface, stageCount)
+	for i, system := range systems {
+		stages[levels[i]] = append(stages[levels[i]], system)
+	}
+
+	return stages, conflicts
+}
+
+// runStage updates the systems of a stage using up to workers goroutines.
+// A stage with a single system is run on the calling goroutine.
+func runStage(stage []systeminterfaces.UpdatableSystemInterface, dt float64, workers int) {
+	if len(stage) == 1 || workers <= 1 {
+		for _, system := range stage {
+			system.Update(dt)
+		}
+		return
+	}
+
+	if workers > len(stage) {
+		workers = len(stage)
+	}
+
+	jobs := make(chan systeminterfaces.UpdatableSystemInterface, len(stage))
+	for _, system := range stage {
+		jobs <- system
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for system := range jobs {
+				system.Update(dt)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
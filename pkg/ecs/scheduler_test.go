@@ -0,0 +1,157 @@
+package ecs
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
+)
+
+// accessSystem is a logic system that only declares its component and resource access
+type accessSystem struct {
+	reads          []ComponentType
+	writes         []ComponentType
+	readResources  []Resource
+	writeResources []Resource
+	priority       int
+}
+
+func (as *accessSystem) Update(dt float64) {}
+
+func (as *accessSystem) GetPriority() int {
+	return as.priority
+}
+
+func (as *accessSystem) GetReadComponents() []ComponentType {
+	return as.reads
+}
+
+func (as *accessSystem) GetWriteComponents() []ComponentType {
+	return as.writes
+}
+
+func (as *accessSystem) GetReadResources() []Resource {
+	return as.readResources
+}
+
+func (as *accessSystem) GetWriteResources() []Resource {
+	return as.writeResources
+}
+
+// undeclaredSystem doesn't declare what it accesses
+type undeclaredSystem struct {
+	priority int
+}
+
+func (us *undeclaredSystem) Update(dt float64) {}
+
+func (us *undeclaredSystem) GetPriority() int {
+	return us.priority
+}
+
+// touchesSame checks if one of the two systems writes something the other one reads or writes
+func touchesSame(a *accessSystem, b *accessSystem) bool {
+	writesRead := func(writes []ComponentType, reads []ComponentType) bool {
+		return slices.ContainsFunc(writes, func(ct ComponentType) bool { return slices.Contains(reads, ct) })
+	}
+	writesReadResource := func(writes []Resource, reads []Resource) bool {
+		return slices.ContainsFunc(writes, func(r Resource) bool { return slices.Contains(reads, r) })
+	}
+
+	return writesRead(a.writes, b.reads) || writesRead(a.writes, b.writes) || writesRead(b.writes, a.reads) ||
+		writesReadResource(a.writeResources, b.readResources) || writesReadResource(a.writeResources, b.writeResources) ||
+		writesReadResource(b.writeResources, a.readResources)
+}
+
+// Two systems where one writes a component or resource the other one reads or writes never share a stage,
+// and a system only runs after every earlier system it touches the same data as
+func TestStagesNeverShareWrittenData(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	componentTypes := []ComponentType{ComponentTypeOf[benchPosition](), ComponentTypeOf[benchVelocity](), ComponentTypeOf[benchTag](), Transform2DComponent, RigidBodyComponent}
+	resources := []Resource{PhysicsWorldResource, EventsResource, RandomResource}
+
+	pick := func() ([]ComponentType, []Resource) {
+		cts := []ComponentType{}
+		for _, ct := range componentTypes {
+			if random.Intn(4) == 0 {
+				cts = append(cts, ct)
+			}
+		}
+		rs := []Resource{}
+		for _, r := range resources {
+			if random.Intn(5) == 0 {
+				rs = append(rs, r)
+			}
+		}
+		return cts, rs
+	}
+
+	for run := 0; run < 200; run++ {
+		systems := []systeminterfaces.UpdatableSystemInterface{}
+		for i := 0; i < 8; i++ {
+			system := &accessSystem{priority: i / 2}
+			system.reads, system.readResources = pick()
+			system.writes, system.writeResources = pick()
+			systems = append(systems, system)
+		}
+
+		stages, _ := buildSchedule(systems)
+
+		stageOf := make(map[systeminterfaces.UpdatableSystemInterface]int)
+		for s, stage := range stages {
+			for _, system := range stage {
+				stageOf[system] = s
+			}
+
+			for i := range stage {
+				for j := i + 1; j < len(stage); j++ {
+					if touchesSame(stage[i].(*accessSystem), stage[j].(*accessSystem)) {
+						t.Fatalf("run %d: %+v and %+v share stage %d", run, stage[i], stage[j], s)
+					}
+				}
+			}
+		}
+
+		for i := range systems {
+			for j := i + 1; j < len(systems); j++ {
+				if touchesSame(systems[i].(*accessSystem), systems[j].(*accessSystem)) && stageOf[systems[i]] >= stageOf[systems[j]] {
+					t.Fatalf("run %d: %+v runs in stage %d, not after %+v in stage %d", run, systems[j], stageOf[systems[j]], systems[i], stageOf[systems[i]])
+				}
+			}
+		}
+	}
+}
+
+// Systems sharing only what they read share a stage, a written resource and undeclared systems split them
+func TestResourcesSplitStages(t *testing.T) {
+	reader := &accessSystem{readResources: []Resource{PhysicsWorldResource}, priority: 1}
+	otherReader := &accessSystem{readResources: []Resource{PhysicsWorldResource}, priority: 1}
+	writer := &accessSystem{writeResources: []Resource{PhysicsWorldResource}, priority: 2}
+	undeclared := &undeclaredSystem{priority: 3}
+	unrelated := &accessSystem{writes: []ComponentType{ComponentTypeOf[benchTag]()}, priority: 4}
+
+	stages, conflicts := buildSchedule([]systeminterfaces.UpdatableSystemInterface{reader, otherReader, writer, undeclared, unrelated})
+
+	want := [][]systeminterfaces.UpdatableSystemInterface{{reader, otherReader}, {writer}, {undeclared}, {unrelated}}
+	if len(stages) != len(want) {
+		t.Fatalf("got %d stages, want %d: %v", len(stages), len(want), stages)
+	}
+	for i := range want {
+		if !slices.Equal(stages[i], want[i]) {
+			t.Fatalf("stage %d = %v, want %v", i, stages[i], want[i])
+		}
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("conflicts = %v, want none between different priorities", conflicts)
+	}
+
+	// Two writers of a resource with the same priority are reported
+	other := &accessSystem{writeResources: []Resource{EventsResource}, priority: 1}
+	another := &accessSystem{readResources: []Resource{EventsResource}, priority: 1}
+	_, conflicts = buildSchedule([]systeminterfaces.UpdatableSystemInterface{other, another})
+	if len(conflicts) != 1 || conflicts[0].Resource != EventsResource {
+		t.Fatalf("conflicts = %v, want one on the events resource", conflicts)
+	}
+}
@@ -1,31 +1,53 @@
 package ecs
 
 import (
+	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
 
 	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
 	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
 type SystemsManager struct {
+	ecsManager      *ECSManager
 	logicSystems    []systeminterfaces.UpdatableSystemInterface
 	renderSystems   []systeminterfaces.RenderableSystemInterface
 	uiRenderSystems []systeminterfaces.UIRenderableSystemInterface
 	systemMutex     sync.RWMutex
+
+	// Parallel scheduling of logic systems
+	isParallel    atomic.Bool
+	workerCount   atomic.Int32
+	stages        [][]systeminterfaces.UpdatableSystemInterface
+	conflicts     []SystemConflict
+	scheduleDirty bool
+
+	// Structural changes queued by each logic system
+	commandBuffers map[systeminterfaces.UpdatableSystemInterface]*CommandBuffer
+	bufferMutex    sync.RWMutex
 }
 
 func NewSystemsManager(ecsManager *ECSManager) *SystemsManager {
-	return &SystemsManager{
+	sm := &SystemsManager{
+		ecsManager:      ecsManager,
 		logicSystems:    []systeminterfaces.UpdatableSystemInterface{},
 		renderSystems:   []systeminterfaces.RenderableSystemInterface{},
 		uiRenderSystems: []systeminterfaces.UIRenderableSystemInterface{},
+		scheduleDirty:   true,
+		commandBuffers:  make(map[systeminterfaces.UpdatableSystemInterface]*CommandBuffer),
 	}
+
+	sm.workerCount.Store(int32(runtime.NumCPU()))
+
+	return sm
 }
 
 func (sm *SystemsManager) AddLogicSystem(system systeminterfaces.UpdatableSystemInterface, priority int) {
 
-	//sm.systemMutex.Lock()
-	//defer sm.systemMutex.Unlock()
+	sm.systemMutex.Lock()
+	defer sm.systemMutex.Unlock()
 
 	inserted := false
 
@@ -48,6 +70,12 @@ func (sm *SystemsManager) AddLogicSystem(system systeminterfaces.UpdatableSystem
 		utils.InfoLogger.Printf("Added logic system: %T\n", system)
 	}
 
+	sm.bufferMutex.Lock()
+	sm.commandBuffers[system] = NewCommandBuffer(sm.ecsManager)
+	sm.bufferMutex.Unlock()
+
+	sm.scheduleDirty = true
+
 }
 
 func (sm *SystemsManager) RemoveLogicSystem(system systeminterfaces.UpdatableSystemInterface) {
@@ -58,6 +86,15 @@ func (sm *SystemsManager) RemoveLogicSystem(system systeminterfaces.UpdatableSys
 	for i, sys := range sm.logicSystems {
 		if sys == system {
 			sm.logicSystems = append(sm.logicSystems[:i], sm.logicSystems[i+1:]...)
+			sm.scheduleDirty = true
+
+			// Apply what the system queued before its buffer is dropped
+			sm.bufferMutex.Lock()
+			commandBuffer := sm.commandBuffers[system]
+			delete(sm.commandBuffers, system)
+			sm.bufferMutex.Unlock()
+			commandBuffer.Flush()
+
 			utils.InfoLogger.Printf("Removed logic system: %T\n", system)
 			break
 		}
@@ -66,8 +103,8 @@ func (sm *SystemsManager) RemoveLogicSystem(system systeminterfaces.UpdatableSys
 
 func (sm *SystemsManager) AddRenderSystem(system systeminterfaces.RenderableSystemInterface, priority int) {
 
-	//sm.systemMutex.Lock()
-	//defer sm.systemMutex.Unlock()
+	sm.systemMutex.Lock()
+	defer sm.systemMutex.Unlock()
 
 	inserted := false
 
@@ -161,30 +198,168 @@ func (sm *SystemsManager) GetCameraSystem() (systeminterfaces.CameraSystemInterf
 	return nil, false
 }
 
-func (sm *SystemsManager) Update(dt float64) {
+// GetPhysicsQueries retrieves the physics queries from the logic system providing them, e.g. the CollisionSystem
+func (sm *SystemsManager) GetPhysicsQueries() (systeminterfaces.PhysicsQueriesInterface, bool) {
 
 	sm.systemMutex.RLock()
 	defer sm.systemMutex.RUnlock()
 
-	for _, system := range sm.logicSystems {
-		system.Update(dt)
+	for _, sys := range sm.logicSystems {
+		if provider, ok := sys.(systeminterfaces.PhysicsQueriesProviderInterface); ok {
+			return provider.GetPhysicsQueries(), true
+		}
 	}
+
+	return nil, false
 }
 
-func (sm *SystemsManager) Render() {
+// GetPhysicsDebugRenderer retrieves the logic system drawing the debug views of the physics world, e.g. the CollisionSystem
+func (sm *SystemsManager) GetPhysicsDebugRenderer() (systeminterfaces.PhysicsDebugRendererInterface, bool) {
+
 	sm.systemMutex.RLock()
 	defer sm.systemMutex.RUnlock()
 
-	for _, system := range sm.renderSystems {
+	for _, sys := range sm.logicSystems {
+		if renderer, ok := sys.(systeminterfaces.PhysicsDebugRendererInterface); ok {
+			return renderer, true
+		}
+	}
+
+	return nil, false
+}
+
+// SetParallel enables or disables running non-conflicting logic systems concurrently.
+// The next Update picks up the change.
+func (sm *SystemsManager) SetParallel(isParallel bool) {
+	sm.isParallel.Store(isParallel)
+}
+
+// IsParallel checks if logic systems are run concurrently
+func (sm *SystemsManager) IsParallel() bool {
+	return sm.isParallel.Load()
+}
+
+// SetWorkerCount sets the maximum number of systems updated at the same time
+func (sm *SystemsManager) SetWorkerCount(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	sm.workerCount.Store(int32(workers))
+}
+
+// GetStages returns the logic systems grouped in the stages they run in when parallel
+func (sm *SystemsManager) GetStages() [][]systeminterfaces.UpdatableSystemInterface {
+	sm.systemMutex.Lock()
+	defer sm.systemMutex.Unlock()
+
+	sm.rebuildSchedule()
+
+	return sm.stages
+}
+
+// GetConflicts returns the conflicts found between systems with the same priority
+func (sm *SystemsManager) GetConflicts() []SystemConflict {
+	sm.systemMutex.Lock()
+	defer sm.systemMutex.Unlock()
+
+	sm.rebuildSchedule()
+
+	return sm.conflicts
+}
+
+// rebuildSchedule rebuilds the stages if logic systems were added or removed.
+// The caller must hold the systemMutex write lock.
+func (sm *SystemsManager) rebuildSchedule() {
+	if !sm.scheduleDirty {
+		return
+	}
+
+	sm.stages, sm.conflicts = buildSchedule(sm.logicSystems)
+	sm.scheduleDirty = false
+
+	for _, conflict := range sm.conflicts {
+		utils.WarnLogger.Printf("SystemsManager: conflict: %s\n", conflict)
+	}
+}
+
+// GetCommandBuffer returns the CommandBuffer a logic system queues its structural changes in.
+// Systems that are not added as logic systems get the shared CommandBuffer of the ECSManager.
+func (sm *SystemsManager) GetCommandBuffer(system systeminterfaces.UpdatableSystemInterface) *CommandBuffer {
+	sm.bufferMutex.RLock()
+	defer sm.bufferMutex.RUnlock()
+
+	if commandBuffer, bufferExists := sm.commandBuffers[system]; bufferExists {
+		return commandBuffer
+	}
+
+	return sm.ecsManager.GetCommandBuffer()
+}
+
+// Update runs the logic systems stage by stage in priority order.
+// Structural changes queued in the CommandBuffers are applied after each stage,
+// so every system sees the changes made by the systems in the stages before it.
+// When parallel, systems within a stage are run concurrently. Systems in a stage don't touch
+// each other's components, so both modes give the same results.
+// The stages are taken when the update starts and no lock is held while they run, so systems can
+// add or remove systems. Those changes apply from the next update.
+func (sm *SystemsManager) Update(dt float64) {
+
+	sm.systemMutex.Lock()
+	sm.rebuildSchedule()
+	stages := sm.stages
+	workers := int(sm.workerCount.Load())
+	sm.systemMutex.Unlock()
+
+	if !sm.IsParallel() {
+		workers = 1
+	}
+
+	// Apply changes queued outside of the update, e.g. by event handlers
+	sm.ecsManager.FlushCommands()
+
+	for _, stage := range stages {
+
+		// Components added or removed directly instead of through a CommandBuffer are only applied
+		// once the whole stage has run, so they can't move rows under the other systems of the stage
+		sm.ecsManager.componentsManager.deferStructuralChanges(func() {
+			runStage(stage, dt, workers)
+		})
+
+		// Sync point: apply the structural changes queued by the stage
+		sm.flushStage(stage)
+	}
+}
+
+// flushStage applies the structural changes queued by the systems of a stage.
+// The buffers are flushed in priority order no matter which system finished first,
+// followed by the shared buffer.
+func (sm *SystemsManager) flushStage(stage []systeminterfaces.UpdatableSystemInterface) {
+	for _, system := range stage {
+		sm.GetCommandBuffer(system).Flush()
+	}
+
+	sm.ecsManager.FlushCommands()
+}
+
+// Render draws the render systems in priority order, without holding the lock while they draw
+func (sm *SystemsManager) Render() {
+	sm.systemMutex.RLock()
+	renderSystems := slices.Clone(sm.renderSystems)
+	sm.systemMutex.RUnlock()
+
+	for _, system := range renderSystems {
 		system.Render()
 	}
 }
 
+// RenderUI draws the UI render systems in priority order, without holding the lock while they draw
 func (sm *SystemsManager) RenderUI() {
 	sm.systemMutex.RLock()
-	defer sm.systemMutex.RUnlock()
+	uiRenderSystems := slices.Clone(sm.uiRenderSystems)
+	sm.systemMutex.RUnlock()
 
-	for _, system := range sm.uiRenderSystems {
+	for _, system := range uiRenderSystems {
 		system.RenderUI()
 	}
 }
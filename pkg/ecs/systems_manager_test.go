@@ -0,0 +1,283 @@
+package ecs
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
+)
+
+// spawnSystem creates entities with a fresh component through its CommandBuffer every step.
+// With withChild set every entity also gets a child, parented through the provisional ID.
+type spawnSystem struct {
+	ecsManager *ECSManager
+	count      int
+	withChild  bool
+	newComp    func() (ComponentType, Component)
+	priority   int
+}
+
+func (ss *spawnSystem) Update(dt float64) {
+	commandBuffer := ss.ecsManager.GetSystemCommandBuffer(ss)
+
+	for i := 0; i < ss.count; i++ {
+		entity := commandBuffer.CreateEntity()
+		ct, c := ss.newComp()
+		commandBuffer.AddComponent(entity.ID, ct, c)
+
+		if ss.withChild {
+			child := commandBuffer.CreateEntity()
+			commandBuffer.SetParent(child.ID, entity.ID)
+		}
+	}
+}
+
+func (ss *spawnSystem) GetPriority() int {
+	return ss.priority
+}
+
+func (ss *spawnSystem) GetReadComponents() []ComponentType {
+	return []ComponentType{}
+}
+
+func (ss *spawnSystem) GetWriteComponents() []ComponentType {
+	ct, _ := ss.newComp()
+	if ss.withChild {
+		return []ComponentType{ct, HierarchyComponent, LocalTransform2DComponent}
+	}
+	return []ComponentType{ct}
+}
+
+// ageSystem counts the steps an entity lived in the X of its component and destroys it after lifetime steps.
+// With withChildren set the entities have children that are destroyed with them.
+type ageSystem struct {
+	ecsManager    *ECSManager
+	componentType ComponentType
+	lifetime      float32
+	withChildren  bool
+	priority      int
+}
+
+func (as *ageSystem) Update(dt float64) {
+	commandBuffer := as.ecsManager.GetSystemCommandBuffer(as)
+
+	as.ecsManager.Query([]ComponentType{as.componentType}, func(eID uint64, comps []Component) {
+		var age *float32
+		switch c := comps[0].(type) {
+		case *benchPosition:
+			age = &c.X
+		case *benchVelocity:
+			age = &c.X
+		default:
+			return
+		}
+
+		*age++
+		if *age >= as.lifetime {
+			commandBuffer.DestroyEntity(eID)
+		}
+	})
+}
+
+func (as *ageSystem) GetPriority() int {
+	return as.priority
+}
+
+func (as *ageSystem) GetReadComponents() []ComponentType {
+	return []ComponentType{}
+}
+
+func (as *ageSystem) GetWriteComponents() []ComponentType {
+	if as.withChildren {
+		return []ComponentType{as.componentType, HierarchyComponent}
+	}
+	return []ComponentType{as.componentType}
+}
+
+// runSpawnWorld runs steps logic steps of a world where two spawners and two agers share stages,
+// and describes every entity alive at the end
+func runSpawnWorld(t *testing.T, isParallel bool, steps int) []string {
+	t.Helper()
+
+	em := NewECSManager()
+	systemsManager := em.GetSystemsManager()
+	systemsManager.SetParallel(isParallel)
+	systemsManager.SetWorkerCount(4)
+
+	em.AddLogicSystem(&spawnSystem{ecsManager: em, count: 40, withChild: true, priority: 1, newComp: func() (ComponentType, Component) {
+		return ComponentTypeOf[benchPosition](), &benchPosition{}
+	}}, 1)
+	em.AddLogicSystem(&spawnSystem{ecsManager: em, count: 60, priority: 1, newComp: func() (ComponentType, Component) {
+		return ComponentTypeOf[benchVelocity](), &benchVelocity{}
+	}}, 1)
+	em.AddLogicSystem(&ageSystem{ecsManager: em, componentType: ComponentTypeOf[benchPosition](), lifetime: 3, withChildren: true, priority: 2}, 2)
+	em.AddLogicSystem(&ageSystem{ecsManager: em, componentType: ComponentTypeOf[benchVelocity](), lifetime: 4, priority: 2}, 2)
+
+	if stages := systemsManager.GetStages(); len(stages) != 2 || len(stages[0]) != 2 || len(stages[1]) != 2 {
+		t.Fatalf("expected two stages of two systems, got %v", stages)
+	}
+
+	for step := 0; step < steps; step++ {
+		systemsManager.Update(1.0 / 60.0)
+	}
+
+	world := []string{}
+	for _, entity := range em.GetAllEntities() {
+		if EntityGeneration(entity.ID) == provisionalGeneration {
+			t.Fatalf("entity %d kept its provisional ID", entity.ID)
+		}
+
+		description := fmt.Sprintf("%d:", entity.ID)
+		if position, exists := Get[benchPosition](em, entity.ID); exists {
+			description += fmt.Sprintf(" position %v", position.X)
+		}
+		if velocity, exists := Get[benchVelocity](em, entity.ID); exists {
+			description += fmt.Sprintf(" velocity %v", velocity.X)
+		}
+		if parent, hasParent := em.GetParent(entity.ID); hasParent {
+			description += fmt.Sprintf(" parent %d", parent)
+		}
+
+		world = append(world, description)
+	}
+	slices.Sort(world)
+
+	return world
+}
+
+// Systems in the same stage record into their own CommandBuffers, so running them in parallel
+// must create, destroy and number the entities exactly like running them one by one
+func TestParallelUpdateMatchesSequential(t *testing.T) {
+	const steps = 50
+
+	// Run the workers on several threads even on a single core, so their commands can interleave
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	sequential := runSpawnWorld(t, false, steps)
+	if len(sequential) == 0 {
+		t.Fatal("no entities alive after the steps")
+	}
+
+	for run := 0; run < 20; run++ {
+		parallel := runSpawnWorld(t, true, steps)
+		if !slices.Equal(sequential, parallel) {
+			t.Fatalf("run %d: parallel world differs from sequential\nsequential: %v\nparallel:   %v", run, sequential, parallel)
+		}
+	}
+}
+
+// directAddSystem adds a tag to every entity with a position directly instead of through its CommandBuffer
+type directAddSystem struct {
+	ecsManager *ECSManager
+	t          *testing.T
+}
+
+func (ds *directAddSystem) Update(dt float64) {
+	for eID, position := range Query1[benchPosition](ds.ecsManager) {
+		Add(ds.ecsManager, eID, &benchTag{Value: int(position.X)})
+
+		if Has[benchTag](ds.ecsManager, eID) {
+			ds.t.Errorf("entity %d got its tag while the stage was running", eID)
+		}
+	}
+}
+
+func (ds *directAddSystem) GetPriority() int {
+	return 1
+}
+
+func (ds *directAddSystem) GetReadComponents() []ComponentType {
+	return []ComponentType{ComponentTypeOf[benchPosition]()}
+}
+
+func (ds *directAddSystem) GetWriteComponents() []ComponentType {
+	return []ComponentType{ComponentTypeOf[benchTag]()}
+}
+
+// Components added directly by a system are only added once its stage has run,
+// so the other systems of the stage never see rows move under them
+func TestDirectStructuralChangesWaitForStage(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	em := NewECSManager()
+	em.GetSystemsManager().SetParallel(true)
+	em.GetSystemsManager().SetWorkerCount(4)
+
+	// Tagging an entity moves its velocity to another archetype while the ageSystem iterates the velocities
+	for i := 0; i < 200; i++ {
+		eID := em.CreateEntity().ID
+		Add(em, eID, &benchPosition{X: float32(i)})
+		Add(em, eID, &benchVelocity{})
+	}
+
+	em.AddLogicSystem(&directAddSystem{ecsManager: em, t: t}, 1)
+	em.AddLogicSystem(&ageSystem{ecsManager: em, componentType: ComponentTypeOf[benchVelocity](), lifetime: 1000, priority: 1}, 1)
+
+	if stages := em.GetSystemsManager().GetStages(); len(stages) != 1 {
+		t.Fatalf("expected both systems in one stage, got %v", stages)
+	}
+
+	em.GetSystemsManager().Update(1.0 / 60.0)
+
+	for eID, row := range Query2[benchPosition, benchVelocity](em) {
+		if tag, tagExists := Get[benchTag](em, eID); !tagExists || float32(tag.Value) != row.A.X {
+			t.Fatalf("entity %d: tag %v, want %v", eID, tag, row.A.X)
+		}
+		if row.B.X != 1 {
+			t.Fatalf("entity %d aged %v steps, want 1", eID, row.B.X)
+		}
+	}
+}
+
+// replacingSystem replaces itself with another system the first time it is updated
+type replacingSystem struct {
+	ecsManager  *ECSManager
+	replacement systeminterfaces.UpdatableSystemInterface
+	updates     int
+}
+
+func (rs *replacingSystem) Update(dt float64) {
+	rs.updates++
+
+	systemsManager := rs.ecsManager.GetSystemsManager()
+	systemsManager.GetStages()
+	systemsManager.GetConflicts()
+	rs.ecsManager.RemoveLogicSystem(rs)
+	rs.ecsManager.AddLogicSystem(rs.replacement, rs.replacement.GetPriority())
+}
+
+func (rs *replacingSystem) GetPriority() int {
+	return 1
+}
+
+// Systems can add and remove systems while the systems are updated, the change applies from the next update
+func TestSystemsChangeSystemsDuringUpdate(t *testing.T) {
+	em := NewECSManager()
+
+	replacement := &accessSystem{priority: 1}
+	replacing := &replacingSystem{ecsManager: em, replacement: replacement}
+	em.AddLogicSystem(replacing, replacing.GetPriority())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		em.GetSystemsManager().Update(1.0 / 60.0)
+		em.GetSystemsManager().Update(1.0 / 60.0)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("changing the systems from a system deadlocked the update")
+	}
+
+	if replacing.updates != 1 {
+		t.Fatalf("replaced system was updated %d times, want once", replacing.updates)
+	}
+	if stages := em.GetSystemsManager().GetStages(); len(stages) != 1 || !slices.Equal(stages[0], []systeminterfaces.UpdatableSystemInterface{replacement}) {
+		t.Fatalf("stages = %v, want only the replacement", stages)
+	}
+}
@@ -0,0 +1,143 @@
+package ecs
+
+import (
+	"iter"
+)
+
+// Row2 holds the components of an entity matched by Query2
+type Row2[A, B any] struct {
+	A *A
+	B *B
+}
+
+// Row3 holds the components of an entity matched by Query3
+type Row3[A, B, C any] struct {
+	A *A
+	B *B
+	C *C
+}
+
+// Get retrieves the component of type T for a given entity.
+// It returns false if the entity has no such component.
+func Get[T any](em *ECSManager, eID uint64) (*T, bool) {
+	comp, compExists := em.GetComponent(eID, ComponentTypeOf[T]())
+	if !compExists {
+		return nil, false
+	}
+
+	c, ok := comp.(*T)
+	return c, ok
+}
+
+// Has checks if an entity has a component of type T
+func Has[T any](em *ECSManager, eID uint64) bool {
+	_, compExists := Get[T](em, eID)
+	return compExists
+}
+
+// Add adds a copy of a component of type T to an entity, replacing any existing one
+func Add[T any](em *ECSManager, eID uint64, c *T) {
+	em.AddComponent(eID, ComponentTypeOf[T](), c)
+}
+
+// Remove removes the component of type T from an entity
+func Remove[T any](em *ECSManager, eID uint64) {
+	em.RemoveComponent(eID, ComponentTypeOf[T]())
+}
+
+// AddDeferred queues adding a component of type T to an entity
+func AddDeferred[T any](cb *CommandBuffer, eID uint64, c *T) {
+	cb.AddComponent(eID, ComponentTypeOf[T](), c)
+}
+
+// RemoveDeferred queues removing the component of type T from an entity
+func RemoveDeferred[T any](cb *CommandBuffer, eID uint64) {
+	cb.RemoveComponent(eID, ComponentTypeOf[T]())
+}
+
+// GetUI retrieves the UI component of type T for a given entity.
+// It returns false if the entity has no such UI component.
+func GetUI[T any](em *ECSManager, eID uint64) (*T, bool) {
+	comp, compExists := em.GetUIComponent(eID, UIComponentTypeOf[T]())
+	if !compExists {
+		return nil, false
+	}
+
+	c, ok := comp.(*T)
+	return c, ok
+}
+
+// AddUI adds a UI component of type T to an entity, replacing any existing one
+func AddUI[T any](em *ECSManager, eID uint64, c *T) {
+	em.AddUIComponent(eID, UIComponentTypeOf[T](), c)
+}
+
+// Query1 returns an iterator over every entity with a component of type A.
+// Other components can be looked up with Get while iterating, components added or removed are only
+// added or removed once the iteration ends.
+func Query1[A any](em *ECSManager) iter.Seq2[uint64, *A] {
+	return func(yield func(uint64, *A) bool) {
+		em.componentsManager.queryColumns([]ComponentType{ComponentTypeOf[A]()}, func(entities []uint64, cols []column) bool {
+			as, aOk := columnData[A](cols[0])
+			if !aOk {
+				return true
+			}
+
+			for row, eID := range entities {
+				if !yield(eID, &as[row]) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// Query2 returns an iterator over every entity with components of type A and B.
+// Other components can be looked up with Get while iterating, components added or removed are only
+// added or removed once the iteration ends.
+func Query2[A, B any](em *ECSManager) iter.Seq2[uint64, Row2[A, B]] {
+	return func(yield func(uint64, Row2[A, B]) bool) {
+		cts := []ComponentType{ComponentTypeOf[A](), ComponentTypeOf[B]()}
+
+		em.componentsManager.queryColumns(cts, func(entities []uint64, cols []column) bool {
+			as, aOk := columnData[A](cols[0])
+			bs, bOk := columnData[B](cols[1])
+			if !aOk || !bOk {
+				return true
+			}
+
+			for row, eID := range entities {
+				if !yield(eID, Row2[A, B]{A: &as[row], B: &bs[row]}) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// Query3 returns an iterator over every entity with components of type A, B and C.
+// Other components can be looked up with Get while iterating, components added or removed are only
+// added or removed once the iteration ends.
+func Query3[A, B, C any](em *ECSManager) iter.Seq2[uint64, Row3[A, B, C]] {
+	return func(yield func(uint64, Row3[A, B, C]) bool) {
+		cts := []ComponentType{ComponentTypeOf[A](), ComponentTypeOf[B](), ComponentTypeOf[C]()}
+
+		em.componentsManager.queryColumns(cts, func(entities []uint64, cols []column) bool {
+			as, aOk := columnData[A](cols[0])
+			bs, bOk := columnData[B](cols[1])
+			cs, cOk := columnData[C](cols[2])
+			if !aOk || !bOk || !cOk {
+				return true
+			}
+
+			for row, eID := range entities {
+				if !yield(eID, Row3[A, B, C]{A: &as[row], B: &bs[row], C: &cs[row]}) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
@@ -61,7 +61,7 @@ func (pm *PerformanceMonitor) Update() {
 	// Get the performance metrics from the ECS Manager
 	pm.PerformanceMonitorData.performanceMetrics = pm.ecsManager.GetPerformanceMetrics()
 
-	FPS := raylib.GetFPS()
+	FPS := pm.ecsManager.GetPlatform().GetFPS()
 
 	// Update the Performance Monitor data
 	pm.PerformanceMonitorData.FPS = FPS
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/webbelito/Fenrir/pkg/ecs"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
 
 	raygui "github.com/gen2brain/raylib-go/raygui"
 	raylib "github.com/gen2brain/raylib-go/raylib"
@@ -57,4 +58,35 @@ func (wi *WorldInspector) Render() {
 	// Draw the entity count label
 	raygui.Label(entityCountLabelRect, displayText)
 
+	// Define the position for the sleeping bodies label below the entity count
+	sleepingBodiesLabelRect := raylib.Rectangle{
+		X:      inspectorRect.X + 10,
+		Y:      inspectorRect.Y + 70,
+		Width:  200,
+		Height: 20,
+	}
+
+	sleepingCount, bodyCount := wi.getSleepingBodies()
+
+	// Draw the sleeping bodies label
+	raygui.Label(sleepingBodiesLabelRect, fmt.Sprintf("Sleeping Bodies: %d / %d", sleepingCount, bodyCount))
+
+}
+
+// getSleepingBodies returns the number of sleeping rigid bodies and the number of rigid bodies that can sleep
+func (wi *WorldInspector) getSleepingBodies() (int, int) {
+	sleepingCount, bodyCount := 0, 0
+
+	for _, rb := range ecs.Query1[physicscomponents.RigidBody](wi.ecsManager) {
+		if rb.IsStatic || rb.IsKinematic {
+			continue
+		}
+
+		bodyCount++
+		if rb.IsSleeping {
+			sleepingCount++
+		}
+	}
+
+	return sleepingCount, bodyCount
 }
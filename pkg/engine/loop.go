@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"math"
+)
+
+// Default settings for the fixed timestep loop
+const (
+	DefaultTickRate = 60.0 // Logic updates per second
+	DefaultMaxSteps = 5    // Maximum logic updates per frame before dropping time
+)
+
+// Loop runs logic at a fixed tick rate independent of the frame rate.
+// Frame time is accumulated and consumed in fixed steps, and the leftover
+// fraction of a step is exposed as an interpolation alpha for rendering.
+// It does not depend on a window, so it can be stepped manually, e.g. in tests.
+type Loop struct {
+	fixedDelta  float64
+	maxSteps    int
+	accumulator float64
+	alpha       float64
+	tickCount   uint64
+}
+
+// NewLoop initializes and returns a new Loop running tickRate steps per second
+// and at most maxSteps steps per call to Advance
+func NewLoop(tickRate float64, maxSteps int) *Loop {
+	if tickRate <= 0 {
+		tickRate = DefaultTickRate
+	}
+
+	if maxSteps < 1 {
+		maxSteps = 1
+	}
+
+	return &Loop{
+		fixedDelta: 1 / tickRate,
+		maxSteps:   maxSteps,
+	}
+}
+
+// Advance adds the frame time to the accumulator and calls update once per fixed step.
+// If more than maxSteps steps are due, e.g. after a frame hitch, the remaining time is dropped
+// so the simulation slows down instead of falling further behind.
+// It returns the number of steps that were run.
+func (l *Loop) Advance(frameTime float64, update func(dt float64)) int {
+	if frameTime < 0 {
+		frameTime = 0
+	}
+
+	l.accumulator += frameTime
+
+	steps := 0
+	for l.accumulator >= l.fixedDelta && steps < l.maxSteps {
+		update(l.fixedDelta)
+
+		l.accumulator -= l.fixedDelta
+		l.tickCount++
+		steps++
+	}
+
+	// Drop the time we couldn't catch up on, keeping the fraction of a step
+	// so the next frame doesn't start with a step already due
+	if l.accumulator >= l.fixedDelta {
+		l.accumulator = math.Mod(l.accumulator, l.fixedDelta)
+	}
+
+	l.alpha = l.accumulator / l.fixedDelta
+
+	return steps
+}
+
+// Step runs a single fixed step regardless of the accumulated time
+func (l *Loop) Step(update func(dt float64)) {
+	update(l.fixedDelta)
+	l.tickCount++
+}
+
+// GetAlpha returns how far the current frame is between the last two steps, from 0 to 1
+func (l *Loop) GetAlpha() float64 {
+	return l.alpha
+}
+
+// GetFixedDelta returns the duration of a single step in seconds
+func (l *Loop) GetFixedDelta() float64 {
+	return l.fixedDelta
+}
+
+// GetTickCount returns the number of steps run since the loop was created
+func (l *Loop) GetTickCount() uint64 {
+	return l.tickCount
+}
+
+// Reset clears the accumulated time and the interpolation alpha
+func (l *Loop) Reset() {
+	l.accumulator = 0
+	l.alpha = 0
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"testing"
+)
+
+// After a hitch the dropped time must not leave a step due, or the next frame runs one without any time passing
+func TestAdvanceDropsTimeAfterHitch(t *testing.T) {
+	loop := NewLoop(60, 5)
+
+	if steps := loop.Advance(1, func(dt float64) {}); steps != 5 {
+		t.Fatalf("hitch ran %d steps, want 5", steps)
+	}
+	if alpha := loop.GetAlpha(); alpha < 0 || alpha >= 1 {
+		t.Fatalf("alpha after hitch = %v, want in [0, 1)", alpha)
+	}
+
+	if steps := loop.Advance(0, func(dt float64) {}); steps != 0 {
+		t.Fatalf("frame without time ran %d steps after a hitch, want 0", steps)
+	}
+}
+
+func TestAdvanceCarriesRemainder(t *testing.T) {
+	loop := NewLoop(10, 5)
+
+	steps := 0
+	for frame := 0; frame < 4; frame++ {
+		steps += loop.Advance(0.04, func(dt float64) {})
+	}
+
+	// 0.16 seconds at 10 steps per second
+	if steps != 1 {
+		t.Fatalf("ran %d steps, want 1", steps)
+	}
+	if alpha := loop.GetAlpha(); alpha < 0.59 || alpha > 0.61 {
+		t.Fatalf("alpha = %v, want 0.6", alpha)
+	}
+}
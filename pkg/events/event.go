@@ -1,11 +1,18 @@
 package events
 
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
 // Event is an interface that all events must implement
 type Event interface{}
 
-// ButtonClickEvent represents a button click event
+// ButtonClickEvent represents a button click event.
+// Event and ScenePath are the scene event of the button, see NewSceneEvent, and are empty if it has none.
 type ButtonClickEvent struct {
 	ButtonText string
+	Event      string
+	ScenePath  string
 }
 
 // SceneChangeEvent represents a scene change event
@@ -13,7 +20,90 @@ type SceneChangeEvent struct {
 	ScenePath string
 }
 
+// ScenePushEvent represents a request to push a scene on top of the current scene
+type ScenePushEvent struct {
+	ScenePath string
+}
+
+// ScenePopEvent represents a request to pop the current scene
+type ScenePopEvent struct{}
+
+// SaveSceneEvent represents a request to save the current scene to a file
+type SaveSceneEvent struct {
+	ScenePath string
+}
+
 // ExitGameEvent represents an exit game event
 type ExitGameEvent struct {
 	ShouldExitGame bool
 }
+
+// Collision describes a contact between two colliders.
+// Normal points from EntityA towards EntityB, Impulse is the impulse applied to resolve the contact during the step.
+type Collision struct {
+	EntityA     uint64
+	EntityB     uint64
+	Normal      raylib.Vector2
+	Penetration float32
+	Impulse     float32
+	Points      []raylib.Vector2 // Contact points in world space
+}
+
+// CollisionEnterEvent is sent the first step two colliders touch
+type CollisionEnterEvent struct {
+	Collision
+}
+
+// CollisionStayEvent is sent every following step the two colliders keep touching
+type CollisionStayEvent struct {
+	Collision
+}
+
+// CollisionExitEvent is sent the first step two colliders stop touching, or one of them is destroyed.
+// It carries the last contact of the pair.
+type CollisionExitEvent struct {
+	Collision
+}
+
+// TriggerOverlap describes an entity overlapping a trigger collider
+type TriggerOverlap struct {
+	Trigger uint64
+	Other   uint64
+}
+
+// TriggerEnterEvent is sent the first step an entity overlaps a trigger
+type TriggerEnterEvent struct {
+	TriggerOverlap
+}
+
+// TriggerExitEvent is sent the first step an entity stops overlapping a trigger, or one of them is destroyed
+type TriggerExitEvent struct {
+	TriggerOverlap
+}
+
+// NewSceneEvent returns the event for a scene or game related event type,
+// as used by key bindings and buttons declared in scene files.
+// It returns false if the event type is unknown.
+func NewSceneEvent(eventType string, scenePath string) (Event, bool) {
+	switch eventType {
+	case "change_scene":
+		return SceneChangeEvent{ScenePath: scenePath}, true
+	case "push_scene":
+		return ScenePushEvent{ScenePath: scenePath}, true
+	case "pop_scene":
+		return ScenePopEvent{}, true
+	case "save_scene":
+		return SaveSceneEvent{ScenePath: scenePath}, true
+	case "exit_game":
+		return ExitGameEvent{ShouldExitGame: true}, true
+	default:
+		return nil, false
+	}
+}
+
+// OutOfBoundsEvent is sent the first step a body leaves world bounds with the event policy.
+// Position is where the body was when it left.
+type OutOfBoundsEvent struct {
+	Entity   uint64
+	Position raylib.Vector2
+}
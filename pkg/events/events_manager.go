@@ -1,53 +1,166 @@
 package events
 
 import (
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+
+	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
-// EventsHandler defines a function that handles an event
-type EventsHandler func(event Event)
+// SubscriptionID identifies a subscription so the handler can be unsubscribed later
+type SubscriptionID uint64
+
+// subscription is a handler subscribed to one event type
+type subscription struct {
+	id        SubscriptionID
+	priority  int
+	eventType reflect.Type
+	handler   func(event Event)
+	removed   atomic.Bool
+}
 
-// EventsManager manages event subscriptions and dispatching
+// EventsManager manages event subscriptions and dispatching.
+// Handlers are subscribed to the Go type of an event and called in priority order, lowest first.
+// Events are either dispatched right away or queued and delivered when the queue is flushed.
 type EventsManager struct {
-	subscribers map[string][]EventsHandler
+	subscribers map[reflect.Type][]*subscription
+	nextID      SubscriptionID
 	subMutex    sync.RWMutex
+
+	queue      []Event
+	queueMutex sync.Mutex
 }
 
 // NewEventsManager initializes and returns a new EventManager
 func NewEventsManager() *EventsManager {
 	return &EventsManager{
-		subscribers: make(map[string][]EventsHandler),
+		subscribers: make(map[reflect.Type][]*subscription),
 	}
 }
 
-// Subscribe registers a handler for a specific event type
-func (em *EventsManager) Subscribe(eventType string, handler EventsHandler) {
+// Subscribe registers a handler for the events of type T with a priority of 0
+func Subscribe[T Event](em *EventsManager, handler func(event T)) SubscriptionID {
+	return SubscribeWithPriority(em, 0, handler)
+}
+
+// SubscribeWithPriority registers a handler for the events of type T.
+// Handlers with a lower priority are called first, handlers with the same priority in the order they subscribed.
+func SubscribeWithPriority[T Event](em *EventsManager, priority int, handler func(event T)) SubscriptionID {
+	return em.subscribe(reflect.TypeFor[T](), priority, func(event Event) {
+		handler(event.(T))
+	})
+}
+
+// subscribe adds a subscription for an event type
+func (em *EventsManager) subscribe(eventType reflect.Type, priority int, handler func(event Event)) SubscriptionID {
 	em.subMutex.Lock()
 	defer em.subMutex.Unlock()
 
-	// If the event type doesn't exist in the map, create a new slice
-	if _, exists := em.subscribers[eventType]; !exists {
-		em.subscribers[eventType] = []EventsHandler{}
+	em.nextID++
+	sub := &subscription{
+		id:        em.nextID,
+		priority:  priority,
+		eventType: eventType,
+		handler:   handler,
 	}
 
-	// Append the handler to the slice
-	em.subscribers[eventType] = append(em.subscribers[eventType], handler)
+	// Replace the slice instead of appending in place, dispatches still hold the old one
+	subs := slices.Clone(em.subscribers[eventType])
+	index, _ := slices.BinarySearchFunc(subs, priority+1, func(s *subscription, p int) int {
+		return s.priority - p
+	})
+	em.subscribers[eventType] = slices.Insert(subs, index, sub)
 
+	return sub.id
 }
 
-// Dispatch sends an event to all registered handlers.
-func (em *EventsManager) Dispatch(eventType string, event Event) {
-	em.subMutex.RLock()
-	defer em.subMutex.RUnlock()
+// Unsubscribe removes a subscription. The handler is not called again, even by a dispatch already running.
+// It returns false if the subscription doesn't exist.
+func (em *EventsManager) Unsubscribe(id SubscriptionID) bool {
+	em.subMutex.Lock()
+	defer em.subMutex.Unlock()
+
+	for eventType, subs := range em.subscribers {
+		index := slices.IndexFunc(subs, func(s *subscription) bool {
+			return s.id == id
+		})
+		if index < 0 {
+			continue
+		}
+
+		subs[index].removed.Store(true)
+
+		if len(subs) == 1 {
+			delete(em.subscribers, eventType)
+		} else {
+			em.subscribers[eventType] = slices.Delete(slices.Clone(subs), index, index+1)
+		}
+
+		return true
+	}
+
+	return false
+}
 
-	// Get the handlers for the event type
-	handlers, handlersExists := em.subscribers[eventType]
-	if !handlersExists {
+// Dispatch sends an event to the handlers subscribed to its type right away.
+// Handlers may subscribe, unsubscribe and dispatch other events.
+func (em *EventsManager) Dispatch(event Event) {
+	if event == nil {
 		return
 	}
 
-	// Call each handler
-	for _, handler := range handlers {
-		handler(event)
+	em.subMutex.RLock()
+	subs := em.subscribers[reflect.TypeOf(event)]
+	em.subMutex.RUnlock()
+
+	// Call each handler without holding the lock
+	for _, sub := range subs {
+		if sub.removed.Load() {
+			continue
+		}
+
+		em.callHandler(sub, event)
 	}
 }
+
+// Queue stores an event to be dispatched by the next Flush, e.g. at the start of the next frame
+func (em *EventsManager) Queue(event Event) {
+	em.queueMutex.Lock()
+	defer em.queueMutex.Unlock()
+
+	em.queue = append(em.queue, event)
+}
+
+// Flush dispatches the queued events in the order they were queued.
+// Events queued by the handlers are kept for the next flush.
+func (em *EventsManager) Flush() {
+	em.queueMutex.Lock()
+	queue := em.queue
+	em.queue = nil
+	em.queueMutex.Unlock()
+
+	for _, event := range queue {
+		em.Dispatch(event)
+	}
+}
+
+// GetQueuedCount returns the number of events waiting for the next flush
+func (em *EventsManager) GetQueuedCount() int {
+	em.queueMutex.Lock()
+	defer em.queueMutex.Unlock()
+
+	return len(em.queue)
+}
+
+// callHandler calls a handler and logs a panic instead of letting it reach the dispatcher
+func (em *EventsManager) callHandler(sub *subscription, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.ErrorLogger.Printf("EventsManager: handler %d for %s panicked: %v", sub.id, sub.eventType, r)
+		}
+	}()
+
+	sub.handler(event)
+}
@@ -0,0 +1,171 @@
+package events
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// Handlers only receive the events of the type they subscribed to, already converted to it
+func TestSubscribeIsTyped(t *testing.T) {
+	em := NewEventsManager()
+
+	clicks := []string{}
+	Subscribe(em, func(e ButtonClickEvent) {
+		clicks = append(clicks, e.ButtonText)
+	})
+	scenes := []string{}
+	Subscribe(em, func(e SceneChangeEvent) {
+		scenes = append(scenes, e.ScenePath)
+	})
+
+	em.Dispatch(ButtonClickEvent{ButtonText: "Start"})
+	em.Dispatch(SceneChangeEvent{ScenePath: "game.json"})
+	em.Dispatch(ScenePopEvent{})
+	em.Dispatch(nil)
+
+	if !slices.Equal(clicks, []string{"Start"}) || !slices.Equal(scenes, []string{"game.json"}) {
+		t.Fatalf("clicks = %v, scenes = %v, want one event each", clicks, scenes)
+	}
+}
+
+// An unsubscribed handler is not called again, even later in a dispatch that is already running
+func TestUnsubscribe(t *testing.T) {
+	em := NewEventsManager()
+
+	calls := []string{}
+	var second SubscriptionID
+	SubscribeWithPriority(em, 1, func(e ScenePopEvent) {
+		calls = append(calls, "first")
+		em.Unsubscribe(second)
+	})
+	second = SubscribeWithPriority(em, 2, func(e ScenePopEvent) {
+		calls = append(calls, "second")
+	})
+
+	em.Dispatch(ScenePopEvent{})
+	em.Dispatch(ScenePopEvent{})
+
+	if !slices.Equal(calls, []string{"first", "first"}) {
+		t.Fatalf("calls = %v, want only the first handler", calls)
+	}
+	if em.Unsubscribe(second) {
+		t.Fatal("unsubscribing twice succeeded")
+	}
+	if em.Unsubscribe(SubscriptionID(100)) {
+		t.Fatal("unsubscribing an unknown subscription succeeded")
+	}
+}
+
+// Handlers are called lowest priority first, and in the order they subscribed within a priority
+func TestHandlersRunInPriorityOrder(t *testing.T) {
+	em := NewEventsManager()
+
+	calls := []string{}
+	subscribe := func(name string, priority int) {
+		SubscribeWithPriority(em, priority, func(e ScenePopEvent) {
+			calls = append(calls, name)
+		})
+	}
+	subscribe("late", 10)
+	subscribe("early", -5)
+	subscribe("default", 0)
+	subscribe("late again", 10)
+	subscribe("default again", 0)
+
+	em.Dispatch(ScenePopEvent{})
+
+	want := []string{"early", "default", "default again", "late", "late again"}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
+
+// A panicking handler is logged and the following handlers still get the event
+func TestPanickingHandlerDoesNotStopDispatch(t *testing.T) {
+	em := NewEventsManager()
+
+	SubscribeWithPriority(em, 1, func(e ScenePopEvent) {
+		panic("handler failed")
+	})
+	called := false
+	SubscribeWithPriority(em, 2, func(e ScenePopEvent) {
+		called = true
+	})
+
+	em.Dispatch(ScenePopEvent{})
+
+	if !called {
+		t.Fatal("the handler after the panicking one was not called")
+	}
+}
+
+// Queued events are delivered by Flush in the order they were queued,
+// events queued by the handlers wait for the next flush
+func TestFlushDispatchesInQueueOrder(t *testing.T) {
+	em := NewEventsManager()
+
+	clicks := []string{}
+	Subscribe(em, func(e ButtonClickEvent) {
+		clicks = append(clicks, e.ButtonText)
+		if e.ButtonText == "b" {
+			em.Queue(ButtonClickEvent{ButtonText: "queued by b"})
+		}
+	})
+
+	em.Queue(ButtonClickEvent{ButtonText: "a"})
+	em.Queue(ButtonClickEvent{ButtonText: "b"})
+	em.Queue(ButtonClickEvent{ButtonText: "c"})
+
+	if len(clicks) != 0 || em.GetQueuedCount() != 3 {
+		t.Fatalf("clicks = %v, queued = %d before the flush, want nothing delivered and 3 queued", clicks, em.GetQueuedCount())
+	}
+
+	em.Flush()
+	if !slices.Equal(clicks, []string{"a", "b", "c"}) || em.GetQueuedCount() != 1 {
+		t.Fatalf("clicks = %v, queued = %d after the flush, want a, b, c and 1 queued", clicks, em.GetQueuedCount())
+	}
+
+	em.Flush()
+	if !slices.Equal(clicks, []string{"a", "b", "c", "queued by b"}) || em.GetQueuedCount() != 0 {
+		t.Fatalf("clicks = %v, queued = %d after the second flush, want the queued event delivered", clicks, em.GetQueuedCount())
+	}
+}
+
+// Handlers can subscribe and dispatch without deadlocking, a handler subscribed during a dispatch
+// gets the following events
+func TestSubscribeInsideHandler(t *testing.T) {
+	em := NewEventsManager()
+
+	calls := []string{}
+	subscribed := false
+	Subscribe(em, func(e ScenePopEvent) {
+		calls = append(calls, "outer")
+		if subscribed {
+			return
+		}
+		subscribed = true
+
+		Subscribe(em, func(e ScenePopEvent) {
+			calls = append(calls, "inner")
+		})
+		em.Dispatch(ButtonClickEvent{})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		em.Dispatch(ScenePopEvent{})
+		em.Dispatch(ScenePopEvent{})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch deadlocked")
+	}
+
+	if !slices.Equal(calls, []string{"outer", "outer", "inner"}) {
+		t.Fatalf("calls = %v, want the inner handler from the second dispatch on", calls)
+	}
+}
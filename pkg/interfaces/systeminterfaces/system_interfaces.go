@@ -1,6 +1,10 @@
 package systeminterfaces
 
-import "github.com/webbelito/Fenrir/pkg/components"
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/physics"
+)
 
 type UpdatableSystemInterface interface {
 	Update(dt float64)
@@ -26,3 +30,30 @@ type UIRenderableSystemInterface interface {
 	RenderUI()
 	GetPriority() int
 }
+
+// PhysicsQueriesInterface answers raycasts, shape casts and overlap tests against the colliders in the world.
+// The mask holds the collision layers a query hits.
+type PhysicsQueriesInterface interface {
+	Raycast(origin raylib.Vector2, direction raylib.Vector2, maxDistance float32, mask uint32) (physics.RaycastHit, bool)
+	RaycastAll(origin raylib.Vector2, direction raylib.Vector2, maxDistance float32, mask uint32) []physics.RaycastHit
+	ShapeCast(shape *physics.Shape, direction raylib.Vector2, maxDistance float32, mask uint32) (physics.RaycastHit, bool)
+	ShapeCastCollider(eID uint64, direction raylib.Vector2, maxDistance float32) (physics.RaycastHit, bool)
+	OverlapBox(center raylib.Vector2, size raylib.Vector2, rotation float32, mask uint32) []uint64
+	OverlapCircle(center raylib.Vector2, radius float32, mask uint32) []uint64
+	OverlapShape(shape *physics.Shape, mask uint32) []uint64
+
+	// Sync updates the colliders to where they are now, SyncEntity only the collider of one entity
+	Sync() []uint64
+	SyncEntity(eID uint64)
+}
+
+// PhysicsDebugRendererInterface is a system drawing debug views of the physics world, e.g. the CollisionSystem
+type PhysicsDebugRendererInterface interface {
+	ToggleBroadPhaseRender()
+	ToggleJointsRender()
+}
+
+// PhysicsQueriesProviderInterface is a system that provides physics queries, e.g. the CollisionSystem
+type PhysicsQueriesProviderInterface interface {
+	GetPhysicsQueries() PhysicsQueriesInterface
+}
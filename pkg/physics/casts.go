@@ -0,0 +1,257 @@
+package physics
+
+import (
+	"math"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Constants for shape casts
+const (
+	maxCastIterations = 32   // Number of times a shape cast advances the shape before giving up
+	castTolerance     = 0.01 // Distance between the shapes at which a shape cast counts as a hit
+)
+
+// RaycastHit is a collider hit by a raycast or a shape cast
+type RaycastHit struct {
+	Entity   uint64
+	Point    raylib.Vector2 // Where the collider was hit, in world space
+	Normal   raylib.Vector2 // Surface normal of the collider at the hit point
+	Distance float32        // Distance traveled along the direction before the hit
+}
+
+// Translate returns a copy of the shape moved by an offset
+func (s *Shape) Translate(offset raylib.Vector2) Shape {
+	moved := *s
+	moved.Center = raylib.Vector2Add(s.Center, offset)
+	moved.Segment = [2]raylib.Vector2{raylib.Vector2Add(s.Segment[0], offset), raylib.Vector2Add(s.Segment[1], offset)}
+
+	if s.Vertices != nil {
+		moved.Vertices = make([]raylib.Vector2, len(s.Vertices))
+		for i, vertex := range s.Vertices {
+			moved.Vertices[i] = raylib.Vector2Add(vertex, offset)
+		}
+	}
+
+	return moved
+}
+
+// Raycast casts a ray against a shape. The direction must be normalized.
+// A ray starting inside the shape hits it at a distance of 0 with the normal facing back along the ray.
+func Raycast(shape *Shape, origin raylib.Vector2, direction raylib.Vector2, maxDistance float32) (RaycastHit, bool) {
+	if !shape.IsRounded() {
+		return raycastPolygon(shape.Vertices, shape.Normals, origin, direction, maxDistance)
+	}
+
+	// Circles are a single rounded end
+	hit, hitExists := raycastCircle(shape.Segment[0], shape.Radius, origin, direction, maxDistance)
+	if shape.Kind == CircleShape {
+		return hit, hitExists
+	}
+
+	// Capsules are both rounded ends and the box between them
+	if endHit, endHitExists := raycastCircle(shape.Segment[1], shape.Radius, origin, direction, maxDistance); endHitExists && (!hitExists || endHit.Distance < hit.Distance) {
+		hit, hitExists = endHit, true
+	}
+
+	segment := raylib.Vector2Subtract(shape.Segment[1], shape.Segment[0])
+	if raylib.Vector2Length(segment) == 0 {
+		return hit, hitExists
+	}
+
+	side := raylib.Vector2Scale(raylib.Vector2Normalize(raylib.NewVector2(segment.Y, -segment.X)), shape.Radius)
+	body := NewPolygonShape(raylib.Vector2{}, []raylib.Vector2{
+		raylib.Vector2Add(shape.Segment[0], side),
+		raylib.Vector2Add(shape.Segment[1], side),
+		raylib.Vector2Subtract(shape.Segment[1], side),
+		raylib.Vector2Subtract(shape.Segment[0], side),
+	}, 0)
+
+	if bodyHit, bodyHitExists := raycastPolygon(body.Vertices, body.Normals, origin, direction, maxDistance); bodyHitExists && (!hitExists || bodyHit.Distance < hit.Distance) {
+		hit, hitExists = bodyHit, true
+	}
+
+	return hit, hitExists
+}
+
+// raycastCircle casts a ray against a circle
+func raycastCircle(center raylib.Vector2, radius float32, origin raylib.Vector2, direction raylib.Vector2, maxDistance float32) (RaycastHit, bool) {
+	offset := raylib.Vector2Subtract(origin, center)
+
+	b := raylib.Vector2DotProduct(offset, direction)
+	c := raylib.Vector2DotProduct(offset, offset) - radius*radius
+
+	// The ray starts inside the circle
+	if c <= 0 {
+		return RaycastHit{Point: origin, Normal: raylib.Vector2Negate(direction)}, true
+	}
+
+	// The ray points away from the circle or misses it
+	discriminant := b*b - c
+	if b > 0 || discriminant < 0 {
+		return RaycastHit{}, false
+	}
+
+	distance := -b - float32(math.Sqrt(float64(discriminant)))
+	if distance > maxDistance {
+		return RaycastHit{}, false
+	}
+
+	point := raylib.Vector2Add(origin, raylib.Vector2Scale(direction, distance))
+
+	return RaycastHit{
+		Point:    point,
+		Normal:   raylib.Vector2Normalize(raylib.Vector2Subtract(point, center)),
+		Distance: distance,
+	}, true
+}
+
+// raycastPolygon casts a ray against a convex polygon by clipping it against every edge
+func raycastPolygon(vertices []raylib.Vector2, normals []raylib.Vector2, origin raylib.Vector2, direction raylib.Vector2, maxDistance float32) (RaycastHit, bool) {
+	if len(vertices) == 0 {
+		return RaycastHit{}, false
+	}
+
+	enter := float32(0)
+	exit := maxDistance
+	enterNormal := raylib.Vector2Negate(direction)
+
+	for i, normal := range normals {
+		numerator := raylib.Vector2DotProduct(normal, raylib.Vector2Subtract(vertices[i], origin))
+		denominator := raylib.Vector2DotProduct(normal, direction)
+
+		// The ray runs along the edge, it misses if it starts outside of it
+		if denominator == 0 {
+			if numerator < 0 {
+				return RaycastHit{}, false
+			}
+			continue
+		}
+
+		distance := numerator / denominator
+		if denominator < 0 && distance > enter {
+			// The ray enters the polygon through this edge
+			enter = distance
+			enterNormal = normal
+		} else if denominator > 0 && distance < exit {
+			// The ray leaves the polygon through this edge
+			exit = distance
+		}
+
+		if enter > exit {
+			return RaycastHit{}, false
+		}
+	}
+
+	return RaycastHit{
+		Point:    raylib.Vector2Add(origin, raylib.Vector2Scale(direction, enter)),
+		Normal:   enterNormal,
+		Distance: enter,
+	}, true
+}
+
+// CastShape moves a shape along a direction and returns where it first touches the target.
+// The direction must be normalized. A shape already overlapping the target hits it at a distance of 0
+// if it moves further into it, and ignores it otherwise so shapes can slide along and leave what they touch.
+func CastShape(shape *Shape, target *Shape, direction raylib.Vector2, maxDistance float32) (RaycastHit, bool) {
+
+	// Already touching at the start
+	if m, colliding := Collide(shape, target); colliding {
+		if raylib.Vector2DotProduct(direction, m.Normal) > 0 {
+			return castHit(m, 0), true
+		}
+
+		return RaycastHit{}, false
+	}
+
+	// Advance the shape by conservative advancement: the distance between two convex shapes moving apart by a translation
+	// can't shrink faster than the speed at which they approach along the normal between their closest points,
+	// so moving by distance / approach speed never moves past the first contact, however thin the target
+	traveled := float32(0)
+	for range maxCastIterations {
+		moved := shape.Translate(raylib.Vector2Scale(direction, traveled))
+
+		distance, normal, point := shapeDistance(&moved, target)
+		if distance <= castTolerance {
+			return RaycastHit{Point: point, Normal: raylib.Vector2Negate(normal), Distance: traveled}, true
+		}
+
+		// The shapes are closest along the normal, not approaching along it means they never touch
+		approach := raylib.Vector2DotProduct(direction, normal)
+		if approach <= 0 {
+			return RaycastHit{}, false
+		}
+
+		traveled += distance / approach
+		if traveled > maxDistance {
+			return RaycastHit{}, false
+		}
+	}
+
+	// The shape is still closing in after every iteration, it hits the target where it got to
+	moved := shape.Translate(raylib.Vector2Scale(direction, traveled))
+	_, normal, point := shapeDistance(&moved, target)
+
+	return RaycastHit{Point: point, Normal: raylib.Vector2Negate(normal), Distance: traveled}, true
+}
+
+// shapeDistance returns the distance between two shapes that don't overlap, the normal from the first shape towards the second
+// and the closest point of the second shape. The closest points of two separate convex shapes lie on their edges.
+func shapeDistance(a *Shape, b *Shape) (float32, raylib.Vector2, raylib.Vector2) {
+	edgesA := shapeEdges(a)
+	edgesB := shapeEdges(b)
+
+	closestDistance := float32(math.MaxFloat32)
+	var closestA, closestB raylib.Vector2
+
+	for _, edgeA := range edgesA {
+		for _, edgeB := range edgesB {
+			pointA, pointB := closestPointsSegments(edgeA[0], edgeA[1], edgeB[0], edgeB[1])
+
+			if distance := raylib.Vector2Distance(pointA, pointB); distance < closestDistance {
+				closestDistance = distance
+				closestA, closestB = pointA, pointB
+			}
+		}
+	}
+
+	// Cores touching each other have no direction, fall back to the direction between the centers
+	normal := raylib.Vector2Subtract(closestB, closestA)
+	if closestDistance == 0 {
+		normal = raylib.Vector2Subtract(b.Center, a.Center)
+	}
+	if raylib.Vector2Length(normal) == 0 {
+		normal = raylib.NewVector2(0, 1)
+	}
+	normal = raylib.Vector2Normalize(normal)
+
+	return max(closestDistance-a.Radius-b.Radius, 0), normal, raylib.Vector2Subtract(closestB, raylib.Vector2Scale(normal, b.Radius))
+}
+
+// shapeEdges returns the edges of a polygon, or the core segment of a rounded shape
+func shapeEdges(s *Shape) [][2]raylib.Vector2 {
+	if s.IsRounded() {
+		return [][2]raylib.Vector2{s.Segment}
+	}
+
+	edges := make([][2]raylib.Vector2, len(s.Vertices))
+	for i, vertex := range s.Vertices {
+		edges[i] = [2]raylib.Vector2{vertex, s.Vertices[(i+1)%len(s.Vertices)]}
+	}
+
+	return edges
+}
+
+// castHit turns the manifold of a shape cast into a hit, the normal faces the cast shape
+func castHit(m Manifold, distance float32) RaycastHit {
+	hit := RaycastHit{
+		Normal:   raylib.Vector2Negate(m.Normal),
+		Distance: distance,
+	}
+
+	if len(m.Points) > 0 {
+		hit.Point = m.Points[0]
+	}
+
+	return hit
+}
@@ -0,0 +1,57 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// A shape cast far past a thin target stops where it first touches it
+func TestCastShapeHitsThinTarget(t *testing.T) {
+	wall := NewBoxShape(raylib.NewVector2(500, 0), raylib.NewVector2(1, 200), 0)
+
+	tests := []struct {
+		name  string
+		shape Shape
+		want  float32
+	}{
+		{"box", NewBoxShape(raylib.Vector2{}, raylib.NewVector2(10, 10), 0), 494.5},
+		{"rotated box", NewBoxShape(raylib.Vector2{}, raylib.NewVector2(10, 10), 45), 499.5 - 5*math.Sqrt2},
+		{"circle", NewCircleShape(raylib.Vector2{}, 5), 494.5},
+		{"capsule", NewCapsuleShape(raylib.Vector2{}, 5, 30, 90), 484.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hit, hitExists := CastShape(&test.shape, &wall, raylib.NewVector2(1, 0), 10_000)
+			if !hitExists {
+				t.Fatal("the cast went through the wall")
+			}
+			if math.Abs(float64(hit.Distance-test.want)) > castTolerance*2 {
+				t.Errorf("distance = %v, want %v", hit.Distance, test.want)
+			}
+			if hit.Normal.X > -0.99 {
+				t.Errorf("normal = %v, want it facing back along the cast", hit.Normal)
+			}
+		})
+	}
+}
+
+// A shape cast moving past or away from a target misses it
+func TestCastShapeMisses(t *testing.T) {
+	wall := NewBoxShape(raylib.NewVector2(500, 0), raylib.NewVector2(1, 200), 0)
+	box := NewBoxShape(raylib.Vector2{}, raylib.NewVector2(10, 10), 0)
+
+	if hit, hitExists := CastShape(&box, &wall, raylib.NewVector2(-1, 0), 10_000); hitExists {
+		t.Errorf("cast away from the wall hit it at %v", hit.Distance)
+	}
+	if hit, hitExists := CastShape(&box, &wall, raylib.NewVector2(1, 0), 400); hitExists {
+		t.Errorf("cast shorter than the gap hit the wall at %v", hit.Distance)
+	}
+
+	above := box.Translate(raylib.NewVector2(0, -200))
+	if hit, hitExists := CastShape(&above, &wall, raylib.NewVector2(1, 0), 10_000); hitExists {
+		t.Errorf("cast passing over the wall hit it at %v", hit.Distance)
+	}
+}
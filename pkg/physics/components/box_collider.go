@@ -4,7 +4,18 @@ import (
 	raylib "github.com/gen2brain/raylib-go/raylib"
 )
 
+// BoxCollider is a box centered on the entity's position and rotated with its Transform2D
 type BoxCollider struct {
 	Type string
 	Size raylib.Vector2
+
+	Collider
+}
+
+// NewBoxCollider returns a box collider on the default layer that collides with every layer
+func NewBoxCollider(size raylib.Vector2) *BoxCollider {
+	return &BoxCollider{
+		Size:     size,
+		Collider: NewCollider(),
+	}
 }
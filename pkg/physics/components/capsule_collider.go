@@ -0,0 +1,19 @@
+package physicscomponents
+
+// CapsuleCollider is a vertical capsule centered on the entity's position and rotated with its Transform2D.
+// Height is the total height including the rounded ends, so it is at least twice the radius.
+type CapsuleCollider struct {
+	Radius float32
+	Height float32
+
+	Collider
+}
+
+// NewCapsuleCollider returns a capsule collider on the default layer that collides with every layer
+func NewCapsuleCollider(radius float32, height float32) *CapsuleCollider {
+	return &CapsuleCollider{
+		Radius:   radius,
+		Height:   height,
+		Collider: NewCollider(),
+	}
+}
@@ -0,0 +1,16 @@
+package physicscomponents
+
+// CircleCollider is a circle centered on the entity's position
+type CircleCollider struct {
+	Radius float32
+
+	Collider
+}
+
+// NewCircleCollider returns a circle collider on the default layer that collides with every layer
+func NewCircleCollider(radius float32) *CircleCollider {
+	return &CircleCollider{
+		Radius:   radius,
+		Collider: NewCollider(),
+	}
+}
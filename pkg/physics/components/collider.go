@@ -0,0 +1,32 @@
+package physicscomponents
+
+// Collider holds the settings shared by every collider shape.
+// An entity uses a single collider: a BoxCollider, CircleCollider, CapsuleCollider or PolygonCollider.
+type Collider struct {
+
+	// IsTrigger only detects overlaps and sends trigger events, nothing is resolved
+	IsTrigger bool
+
+	// Layer holds the bits of the collision layers the collider is on,
+	// Mask the bits of the layers it collides with, see CollisionLayers
+	Layer uint32
+	Mask  uint32
+}
+
+// NewCollider returns collider settings on the default layer colliding with every layer
+func NewCollider() Collider {
+	return Collider{
+		Layer: DefaultCollisionLayer,
+		Mask:  AllCollisionLayers,
+	}
+}
+
+// CanCollideWith checks if the layers of both colliders are in the mask of the other
+func (c *Collider) CanCollideWith(other *Collider) bool {
+	return c.Layer&other.Mask != 0 && other.Layer&c.Mask != 0
+}
+
+// IsInMask checks if the collider is on one of the layers of a mask, e.g. the mask of a query
+func (c *Collider) IsInMask(mask uint32) bool {
+	return c.Layer&mask != 0
+}
@@ -0,0 +1,134 @@
+package physicscomponents
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Collision layers are bits of a uint32, so a collider can be on up to 32 layers
+const (
+	MaxCollisionLayers = 32
+
+	DefaultCollisionLayer uint32 = 1
+	AllCollisionLayers    uint32 = 1<<MaxCollisionLayers - 1
+)
+
+// ErrUnknownCollisionLayer is returned for a layer name that is neither declared nor of the form layer<i>
+var ErrUnknownCollisionLayer = errors.New("unknown collision layer")
+
+// CollisionLayers names the collision layers by their bit index.
+// Every world has its own table, declared by the scene it was loaded from.
+type CollisionLayers struct {
+	names      [MaxCollisionLayers]string
+	layerMutex sync.RWMutex
+}
+
+// NewCollisionLayers initializes and returns a new table where only the default layer is named
+func NewCollisionLayers() *CollisionLayers {
+	return &CollisionLayers{names: defaultCollisionLayerNames()}
+}
+
+// defaultCollisionLayerNames returns the names of a table nothing was declared in
+func defaultCollisionLayerNames() [MaxCollisionLayers]string {
+	return [MaxCollisionLayers]string{"default"}
+}
+
+// Set replaces the named layers, the name at index i is the layer with bit i.
+// Layers without a name can still be referenced as "layer<i>".
+func (cl *CollisionLayers) Set(names []string) error {
+	if len(names) > MaxCollisionLayers {
+		return fmt.Errorf("collision layers: %d layers exceed the maximum of %d", len(names), MaxCollisionLayers)
+	}
+
+	table := [MaxCollisionLayers]string{}
+	for i, name := range names {
+		for _, other := range names[:i] {
+			if name != "" && name == other {
+				return fmt.Errorf("collision layers: %s is declared twice", name)
+			}
+		}
+
+		table[i] = name
+	}
+
+	cl.layerMutex.Lock()
+	defer cl.layerMutex.Unlock()
+
+	cl.names = table
+
+	return nil
+}
+
+// Reset forgets the declared layers, only the default layer is named again
+func (cl *CollisionLayers) Reset() {
+	cl.layerMutex.Lock()
+	defer cl.layerMutex.Unlock()
+
+	cl.names = defaultCollisionLayerNames()
+}
+
+// GetLayer returns the bit of a named layer
+func (cl *CollisionLayers) GetLayer(name string) (uint32, bool) {
+	cl.layerMutex.RLock()
+	defer cl.layerMutex.RUnlock()
+
+	for i, layerName := range cl.names {
+		if layerName != "" && layerName == name {
+			return 1 << i, true
+		}
+	}
+
+	// Fall back to the index of an unnamed layer
+	if index, err := strconv.Atoi(strings.TrimPrefix(name, "layer")); err == nil && strings.HasPrefix(name, "layer") && index >= 0 && index < MaxCollisionLayers {
+		return 1 << index, true
+	}
+
+	return 0, false
+}
+
+// GetMask combines the bits of named layers. Unknown names are returned as an ErrUnknownCollisionLayer.
+func (cl *CollisionLayers) GetMask(names []string) (uint32, error) {
+	mask := uint32(0)
+	unknown := []string{}
+
+	for _, name := range names {
+		layer, layerExists := cl.GetLayer(name)
+		if !layerExists {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		mask |= layer
+	}
+
+	if len(unknown) > 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCollisionLayer, strings.Join(unknown, ", "))
+	}
+
+	return mask, nil
+}
+
+// GetNames returns the names of the layers in a mask, in bit order
+func (cl *CollisionLayers) GetNames(mask uint32) []string {
+	cl.layerMutex.RLock()
+	defer cl.layerMutex.RUnlock()
+
+	names := make([]string, 0, bits.OnesCount32(mask))
+	for i := 0; i < MaxCollisionLayers; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+
+		if cl.names[i] != "" {
+			names = append(names, cl.names[i])
+		} else {
+			names = append(names, "layer"+strconv.Itoa(i))
+		}
+	}
+
+	return names
+}
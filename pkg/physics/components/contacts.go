@@ -0,0 +1,19 @@
+package physicscomponents
+
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Contact is a collider touching the entity during the last physics step
+type Contact struct {
+	Other       uint64
+	Normal      raylib.Vector2 // Points from the entity towards Other
+	Penetration float32
+	Impulse     float32
+	Points      []raylib.Vector2 // Contact points in world space
+}
+
+// Contacts lists the colliders touching an entity, updated by the CollisionSystem every step
+type Contacts struct {
+	Entries []Contact
+}
@@ -0,0 +1,61 @@
+package physicscomponents
+
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// JointType is the kind of constraint a joint applies
+type JointType string
+
+const (
+	// DistanceJoint keeps the anchors at Length from each other, like a rigid rod
+	DistanceJoint JointType = "distance"
+
+	// SpringJoint pulls the anchors towards Length, oscillating at Frequency and losing energy with DampingRatio
+	SpringJoint JointType = "spring"
+
+	// RevoluteJoint pins the anchors together, the bodies can turn around the pin
+	RevoluteJoint JointType = "revolute"
+
+	// WeldJoint pins the anchors together and keeps the rotation between the bodies
+	WeldJoint JointType = "weld"
+
+	// RopeJoint keeps the anchors at most Length from each other, they can move closer freely
+	RopeJoint JointType = "rope"
+)
+
+// Joint constrains the entity to another entity, or to a point in the world when Connected is 0.
+// Anchors are relative to the entity positions and turn with their rotation, the anchor of the world is a world position.
+type Joint struct {
+	Type            JointType
+	Connected       uint64
+	Anchor          raylib.Vector2
+	ConnectedAnchor raylib.Vector2
+	Length          float32
+
+	// Springs only
+	Frequency    float32 // Oscillations per second
+	DampingRatio float32 // 0 keeps oscillating, 1 stops without overshooting
+
+	// Welds only, the rotation of the connected entity relative to the entity
+	ReferenceRotation float32
+
+	// Impulses applied by the joint during the last step, they warm start the next step
+	Impulse        raylib.Vector2
+	AngularImpulse float32
+}
+
+// Joints lists the joints of an entity, solved by the CollisionSystem together with the contacts
+type Joints struct {
+	Entries []Joint
+}
+
+// IsValid checks if the joint has a known type
+func (jt JointType) IsValid() bool {
+	switch jt {
+	case DistanceJoint, SpringJoint, RevoluteJoint, WeldJoint, RopeJoint:
+		return true
+	}
+
+	return false
+}
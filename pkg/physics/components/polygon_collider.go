@@ -0,0 +1,21 @@
+package physicscomponents
+
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PolygonCollider is a convex polygon with its points relative to the entity's position,
+// rotated with its Transform2D. The points can be in either winding order.
+type PolygonCollider struct {
+	Points []raylib.Vector2
+
+	Collider
+}
+
+// NewPolygonCollider returns a polygon collider on the default layer that collides with every layer
+func NewPolygonCollider(points []raylib.Vector2) *PolygonCollider {
+	return &PolygonCollider{
+		Points:   points,
+		Collider: NewCollider(),
+	}
+}
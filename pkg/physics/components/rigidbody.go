@@ -4,6 +4,12 @@ import (
 	raylib "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Default friction coefficients of new rigid bodies and of colliders without a RigidBody
+const (
+	DefaultStaticFriction  = 0.5
+	DefaultDynamicFriction = 0.3
+)
+
 type RigidBody struct {
 	Mass         float32
 	InvMass      float32
@@ -12,20 +18,50 @@ type RigidBody struct {
 	Force        raylib.Vector2
 	Drag         float32
 	Restitution  float32
-	IsKinematic  bool
-	IsStatic     bool
+
+	// StaticFriction holds a resting contact in place, DynamicFriction slows a sliding one.
+	// The coefficients of both bodies in contact are combined.
+	StaticFriction  float32
+	DynamicFriction float32
+
+	// AngularVelocity turns the body in degrees per second, Torque is accumulated until the next step like Force.
+	// AngularDrag slows the turning by this fraction of the angular velocity per second.
+	AngularVelocity float32
+	Torque          float32
+	AngularDrag     float32
+
+	// Inertia resists turning like Mass resists moving, the RigidBodySystem computes it from the collider when it is 0.
+	// InvInertia is updated every step, bodies without a collider or with FixedRotation never turn from contacts.
+	Inertia       float32
+	InvInertia    float32
+	FixedRotation bool
+
+	IsKinematic bool
+	IsStatic    bool
+
+	// IsContinuous sweeps the collider of the body every step instead of only testing where it ends,
+	// so fast bodies like bullets can't tunnel through thin colliders
+	IsContinuous bool
+
+	// IsSleeping bodies are at rest and are neither moved nor solved until a force, a new velocity or an awake body touching them wakes them.
+	// SleepTime is how long the body has been slow enough to sleep, bodies with NeverSleep stay awake.
+	IsSleeping bool
+	SleepTime  float32
+	NeverSleep bool
 }
 
 func NewRigidBody(mass float32, drag float32, restitution float32, isKinematic bool, isStatic bool) *RigidBody {
 	rb := &RigidBody{
-		Mass:         mass,
-		Velocity:     raylib.NewVector2(0, 0),
-		Acceleration: raylib.NewVector2(0, 0),
-		Force:        raylib.NewVector2(0, 0),
-		Drag:         drag,
-		Restitution:  restitution,
-		IsKinematic:  isKinematic,
-		IsStatic:     isStatic,
+		Mass:            mass,
+		Velocity:        raylib.NewVector2(0, 0),
+		Acceleration:    raylib.NewVector2(0, 0),
+		Force:           raylib.NewVector2(0, 0),
+		Drag:            drag,
+		Restitution:     restitution,
+		StaticFriction:  DefaultStaticFriction,
+		DynamicFriction: DefaultDynamicFriction,
+		IsKinematic:     isKinematic,
+		IsStatic:        isStatic,
 	}
 
 	// Calculate InvMass
@@ -37,3 +73,52 @@ func NewRigidBody(mass float32, drag float32, restitution float32, isKinematic b
 
 	return rb
 }
+
+// Wake wakes the body up and restarts its sleep timer.
+// Bodies moved by their transform while sleeping are only woken by calling it.
+func (rb *RigidBody) Wake() {
+	rb.IsSleeping = false
+	rb.SleepTime = 0
+}
+
+// Sleep puts the body to sleep, stopping it
+func (rb *RigidBody) Sleep() {
+	rb.IsSleeping = true
+	rb.Velocity = raylib.NewVector2(0, 0)
+	rb.Acceleration = raylib.NewVector2(0, 0)
+	rb.AngularVelocity = 0
+}
+
+// ApplyForce adds a force at the center of the body until the next step, waking it
+func (rb *RigidBody) ApplyForce(force raylib.Vector2) {
+	rb.Force = raylib.Vector2Add(rb.Force, force)
+	rb.Wake()
+}
+
+// ApplyForceAtPoint adds a force at a point in world space until the next step.
+// center is the position of the body, a force off the center also turns it.
+func (rb *RigidBody) ApplyForceAtPoint(force raylib.Vector2, point raylib.Vector2, center raylib.Vector2) {
+	rb.ApplyForce(force)
+	rb.Torque += raylib.Vector2CrossProduct(raylib.Vector2Subtract(point, center), force)
+}
+
+// ApplyImpulse changes the velocity of the body at once, by inverse mass, waking it
+func (rb *RigidBody) ApplyImpulse(impulse raylib.Vector2) {
+	if rb.IsStatic || rb.Mass == 0 {
+		return
+	}
+
+	rb.Wake()
+	rb.Velocity = raylib.Vector2Add(rb.Velocity, raylib.Vector2Scale(impulse, 1/rb.Mass))
+}
+
+// ApplyImpulseAtPoint changes the velocity of the body at once at a point in world space.
+// center is the position of the body, an impulse off the center also changes its angular velocity.
+func (rb *RigidBody) ApplyImpulseAtPoint(impulse raylib.Vector2, point raylib.Vector2, center raylib.Vector2) {
+	if rb.IsStatic {
+		return
+	}
+
+	rb.ApplyImpulse(impulse)
+	rb.AngularVelocity += raylib.Vector2CrossProduct(raylib.Vector2Subtract(point, center), impulse) * rb.InvInertia * raylib.Rad2deg
+}
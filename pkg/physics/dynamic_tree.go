@@ -0,0 +1,570 @@
+package physics
+
+import (
+	"cmp"
+	"slices"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Constants for the DynamicTree
+const (
+	DefaultAABBMargin = 4.0 // Default distance the bounds of a proxy are grown by, so small moves don't update the tree
+	nullNode          = -1
+)
+
+// DynamicTree is a bounding volume hierarchy of axis aligned bounds, each leaf is a proxy of an entity.
+// Proxies are stored with bounds grown by a margin and are only moved in the tree when their bounds leave them,
+// so bodies that barely move cost nothing. The tree is kept balanced with rotations as proxies come and go.
+type DynamicTree struct {
+	Margin float32
+
+	nodes    []treeNode
+	root     int32
+	freeList int32
+
+	// The overlapping pairs are kept between calls of UpdatePairs, only the proxies that changed since are queried again
+	moved   map[int32]bool
+	changed map[uint64]bool
+	pairs   [][2]uint64
+}
+
+// treeNode is a leaf holding a proxy, or an internal node bounding its two children.
+// Free nodes link to the next free node through parent.
+type treeNode struct {
+	bounds Rectangle
+	parent int32
+	left   int32
+	right  int32
+	height int32
+	entity uint64
+}
+
+func (n *treeNode) isLeaf() bool {
+	return n.left == nullNode
+}
+
+// NewDynamicTree initializes and returns an empty DynamicTree growing proxies by margin
+func NewDynamicTree(margin float32) *DynamicTree {
+	return &DynamicTree{
+		Margin:   margin,
+		nodes:    []treeNode{},
+		root:     nullNode,
+		freeList: nullNode,
+		moved:    make(map[int32]bool),
+		changed:  make(map[uint64]bool),
+		pairs:    [][2]uint64{},
+	}
+}
+
+// CreateProxy adds an entity with its bounds to the tree and returns the ID of its proxy
+func (dt *DynamicTree) CreateProxy(eID uint64, bounds Rectangle) int32 {
+	proxyID := dt.allocateNode()
+
+	node := &dt.nodes[proxyID]
+	node.bounds = fatten(bounds, dt.Margin)
+	node.entity = eID
+	node.height = 0
+
+	dt.insertLeaf(proxyID)
+	dt.markChanged(proxyID)
+
+	return proxyID
+}
+
+// DestroyProxy removes a proxy from the tree
+func (dt *DynamicTree) DestroyProxy(proxyID int32) {
+	if !dt.isProxy(proxyID) {
+		return
+	}
+
+	dt.markChanged(proxyID)
+	dt.removeLeaf(proxyID)
+	dt.freeNode(proxyID)
+}
+
+// MoveProxy updates the bounds of a proxy. It returns true if the bounds left the grown bounds of the proxy
+// and it was moved in the tree, false if the tree is unchanged.
+func (dt *DynamicTree) MoveProxy(proxyID int32, bounds Rectangle) bool {
+	if !dt.isProxy(proxyID) {
+		return false
+	}
+
+	if containsRectangle(dt.nodes[proxyID].bounds, bounds) {
+		return false
+	}
+
+	dt.removeLeaf(proxyID)
+	dt.nodes[proxyID].bounds = fatten(bounds, dt.Margin)
+	dt.insertLeaf(proxyID)
+	dt.markChanged(proxyID)
+
+	return true
+}
+
+// GetFatBounds returns the grown bounds a proxy is stored with
+func (dt *DynamicTree) GetFatBounds(proxyID int32) Rectangle {
+	if !dt.isProxy(proxyID) {
+		return Rectangle{}
+	}
+
+	return dt.nodes[proxyID].bounds
+}
+
+// Query adds the entities whose proxies overlap a range to found
+func (dt *DynamicTree) Query(rangeRect Rectangle, found *[]uint64) {
+	dt.query(rangeRect, func(proxyID int32) {
+		*found = append(*found, dt.nodes[proxyID].entity)
+	})
+}
+
+// Raycast calls hit with the entity of every proxy a ray passes through before maxDistance, in no particular order.
+// hit returns the distance to clip the ray at, e.g. the distance of the closest hit so far, and maxDistance to keep it,
+// so the nodes past the clipped distance are skipped. The direction must be normalized.
+func (dt *DynamicTree) Raycast(origin raylib.Vector2, direction raylib.Vector2, maxDistance float32, hit func(eID uint64, maxDistance float32) float32) {
+	if dt.root == nullNode {
+		return
+	}
+
+	var buffer [64]int32
+	stack := append(buffer[:0], dt.root)
+	for len(stack) > 0 {
+		nodeID := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &dt.nodes[nodeID]
+		if !rayHitsBounds(origin, direction, maxDistance, &node.bounds) {
+			continue
+		}
+
+		if node.isLeaf() {
+			maxDistance = min(maxDistance, hit(node.entity, maxDistance))
+			continue
+		}
+
+		stack = append(stack, node.left, node.right)
+	}
+}
+
+// UpdatePairs returns every pair of entities whose proxies overlap, once each,
+// with the lower entity ID first and in entity order. The slice is shared until the next call.
+// Only the proxies created, moved or destroyed since the last call are queried, the pairs of the others are kept,
+// so proxies staying inside their grown bounds, like static and resting bodies, cost nothing.
+func (dt *DynamicTree) UpdatePairs() [][2]uint64 {
+	if len(dt.moved) == 0 {
+		return dt.pairs
+	}
+
+	// Find the pairs of the changed proxies
+	found := [][2]uint64{}
+	for proxyID := range dt.moved {
+		if !dt.isProxy(proxyID) {
+			continue
+		}
+		entity := dt.nodes[proxyID].entity
+
+		dt.query(dt.nodes[proxyID].bounds, func(otherID int32) {
+			// A pair of two changed proxies is found from both, keep it from the lower proxy
+			if otherID == proxyID || (dt.moved[otherID] && otherID < proxyID) {
+				return
+			}
+
+			other := dt.nodes[otherID].entity
+			found = append(found, [2]uint64{min(entity, other), max(entity, other)})
+		})
+	}
+	slices.SortFunc(found, comparePairs)
+
+	// Keep the pairs of the unchanged proxies, and merge the found pairs in
+	pairs := make([][2]uint64, 0, len(dt.pairs)+len(found))
+	next := 0
+	for _, pair := range dt.pairs {
+		if dt.changed[pair[0]] || dt.changed[pair[1]] {
+			continue
+		}
+
+		for next < len(found) && comparePairs(found[next], pair) < 0 {
+			pairs = append(pairs, found[next])
+			next++
+		}
+		pairs = append(pairs, pair)
+	}
+	pairs = append(pairs, found[next:]...)
+
+	clear(dt.moved)
+	clear(dt.changed)
+	dt.pairs = pairs
+
+	return pairs
+}
+
+// markChanged queues a proxy created, moved or destroyed for the next UpdatePairs
+func (dt *DynamicTree) markChanged(proxyID int32) {
+	dt.moved[proxyID] = true
+	dt.changed[dt.nodes[proxyID].entity] = true
+}
+
+// comparePairs orders pairs of entities by their first entity, then by their second
+func comparePairs(a [2]uint64, b [2]uint64) int {
+	if a[0] != b[0] {
+		return cmp.Compare(a[0], b[0])
+	}
+
+	return cmp.Compare(a[1], b[1])
+}
+
+// Walk calls visit with the bounds and depth of every node, parents before their children
+func (dt *DynamicTree) Walk(visit func(bounds Rectangle, depth int32, isLeaf bool)) {
+	if dt.root == nullNode {
+		return
+	}
+
+	type entry struct {
+		node  int32
+		depth int32
+	}
+
+	stack := []entry{{dt.root, 0}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &dt.nodes[current.node]
+		visit(node.bounds, current.depth, node.isLeaf())
+
+		if !node.isLeaf() {
+			stack = append(stack, entry{node.right, current.depth + 1}, entry{node.left, current.depth + 1})
+		}
+	}
+}
+
+// GetHeight returns the height of the tree, 0 for a single proxy
+func (dt *DynamicTree) GetHeight() int32 {
+	if dt.root == nullNode {
+		return 0
+	}
+
+	return dt.nodes[dt.root].height
+}
+
+// query calls found with every proxy overlapping a range
+func (dt *DynamicTree) query(rangeRect Rectangle, found func(proxyID int32)) {
+	if dt.root == nullNode {
+		return
+	}
+
+	// Start on a buffer on the stack, a balanced tree never needs more
+	var buffer [64]int32
+	stack := append(buffer[:0], dt.root)
+	for len(stack) > 0 {
+		nodeID := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &dt.nodes[nodeID]
+		if !node.bounds.Intersects(&rangeRect) {
+			continue
+		}
+
+		if node.isLeaf() {
+			found(nodeID)
+			continue
+		}
+
+		stack = append(stack, node.left, node.right)
+	}
+}
+
+// isProxy checks if a node ID is a leaf in the tree
+func (dt *DynamicTree) isProxy(proxyID int32) bool {
+	if proxyID < 0 || int(proxyID) >= len(dt.nodes) {
+		return false
+	}
+
+	node := &dt.nodes[proxyID]
+	return node.height == 0 && node.isLeaf()
+}
+
+// allocateNode returns a free node, reusing removed ones first
+func (dt *DynamicTree) allocateNode() int32 {
+	if dt.freeList == nullNode {
+		dt.nodes = append(dt.nodes, treeNode{})
+		dt.freeList = int32(len(dt.nodes) - 1)
+		dt.nodes[dt.freeList].parent = nullNode
+		dt.nodes[dt.freeList].height = -1
+	}
+
+	nodeID := dt.freeList
+	dt.freeList = dt.nodes[nodeID].parent
+
+	dt.nodes[nodeID] = treeNode{
+		parent: nullNode,
+		left:   nullNode,
+		right:  nullNode,
+	}
+
+	return nodeID
+}
+
+// freeNode returns a node to the free list
+func (dt *DynamicTree) freeNode(nodeID int32) {
+	dt.nodes[nodeID] = treeNode{
+		parent: dt.freeList,
+		left:   nullNode,
+		right:  nullNode,
+		height: -1,
+	}
+	dt.freeList = nodeID
+}
+
+// insertLeaf finds the cheapest sibling for a leaf by the perimeter the tree grows by, and pairs them under a new parent
+func (dt *DynamicTree) insertLeaf(leaf int32) {
+	if dt.root == nullNode {
+		dt.root = leaf
+		dt.nodes[leaf].parent = nullNode
+		return
+	}
+
+	leafBounds := dt.nodes[leaf].bounds
+
+	// Walk down towards the sibling that grows the tree the least
+	index := dt.root
+	for !dt.nodes[index].isLeaf() {
+		node := &dt.nodes[index]
+
+		perimeter := getPerimeter(node.bounds)
+		combinedPerimeter := getPerimeter(union(node.bounds, leafBounds))
+
+		// Cost of pairing the leaf with this node, and of pushing it further down
+		cost := 2 * combinedPerimeter
+		inheritanceCost := 2 * (combinedPerimeter - perimeter)
+
+		leftCost := dt.getDescendCost(node.left, leafBounds) + inheritanceCost
+		rightCost := dt.getDescendCost(node.right, leafBounds) + inheritanceCost
+
+		if cost < leftCost && cost < rightCost {
+			break
+		}
+
+		if leftCost < rightCost {
+			index = node.left
+		} else {
+			index = node.right
+		}
+	}
+
+	sibling := index
+
+	// Pair the leaf with its sibling under a new parent
+	oldParent := dt.nodes[sibling].parent
+	newParent := dt.allocateNode()
+
+	dt.nodes[newParent].parent = oldParent
+	dt.nodes[newParent].bounds = union(leafBounds, dt.nodes[sibling].bounds)
+	dt.nodes[newParent].height = dt.nodes[sibling].height + 1
+	dt.nodes[newParent].left = sibling
+	dt.nodes[newParent].right = leaf
+	dt.nodes[sibling].parent = newParent
+	dt.nodes[leaf].parent = newParent
+
+	if oldParent == nullNode {
+		dt.root = newParent
+	} else if dt.nodes[oldParent].left == sibling {
+		dt.nodes[oldParent].left = newParent
+	} else {
+		dt.nodes[oldParent].right = newParent
+	}
+
+	dt.refit(dt.nodes[leaf].parent)
+}
+
+// getDescendCost returns how much the tree grows if a leaf with bounds is pushed down into a child
+func (dt *DynamicTree) getDescendCost(child int32, bounds Rectangle) float32 {
+	combined := getPerimeter(union(bounds, dt.nodes[child].bounds))
+	if dt.nodes[child].isLeaf() {
+		return combined
+	}
+
+	return combined - getPerimeter(dt.nodes[child].bounds)
+}
+
+// removeLeaf takes a leaf out of the tree, its sibling takes the place of their parent
+func (dt *DynamicTree) removeLeaf(leaf int32) {
+	if leaf == dt.root {
+		dt.root = nullNode
+		return
+	}
+
+	parent := dt.nodes[leaf].parent
+	grandParent := dt.nodes[parent].parent
+
+	sibling := dt.nodes[parent].left
+	if sibling == leaf {
+		sibling = dt.nodes[parent].right
+	}
+
+	dt.freeNode(parent)
+
+	if grandParent == nullNode {
+		dt.root = sibling
+		dt.nodes[sibling].parent = nullNode
+		return
+	}
+
+	if dt.nodes[grandParent].left == parent {
+		dt.nodes[grandParent].left = sibling
+	} else {
+		dt.nodes[grandParent].right = sibling
+	}
+	dt.nodes[sibling].parent = grandParent
+
+	dt.refit(grandParent)
+}
+
+// refit balances the nodes from a node up to the root and updates their bounds and heights
+func (dt *DynamicTree) refit(index int32) {
+	for index != nullNode {
+		index = dt.balance(index)
+
+		node := &dt.nodes[index]
+		left := &dt.nodes[node.left]
+		right := &dt.nodes[node.right]
+
+		node.height = 1 + max(left.height, right.height)
+		node.bounds = union(left.bounds, right.bounds)
+
+		index = node.parent
+	}
+}
+
+// balance rotates the taller child of a node up if the heights of its children differ by more than one.
+// It returns the node now in its place.
+func (dt *DynamicTree) balance(iA int32) int32 {
+	a := &dt.nodes[iA]
+	if a.isLeaf() || a.height < 2 {
+		return iA
+	}
+
+	iB := a.left
+	iC := a.right
+	difference := dt.nodes[iC].height - dt.nodes[iB].height
+
+	if difference > 1 {
+		return dt.rotate(iA, iC, iB, false)
+	}
+
+	if difference < -1 {
+		return dt.rotate(iA, iB, iC, true)
+	}
+
+	return iA
+}
+
+// rotate moves the tall child of a node into its place. The taller grandchild stays with the tall child
+// and the shorter one replaces the tall child under the node. fromLeft tells which side the tall child was on.
+func (dt *DynamicTree) rotate(iA int32, iTall int32, iShort int32, fromLeft bool) int32 {
+	tall := &dt.nodes[iTall]
+	iF := tall.left
+	iG := tall.right
+
+	// The tall child takes the place of the node
+	tall.left = iA
+	tall.parent = dt.nodes[iA].parent
+	dt.nodes[iA].parent = iTall
+
+	if tall.parent == nullNode {
+		dt.root = iTall
+	} else if dt.nodes[tall.parent].left == iA {
+		dt.nodes[tall.parent].left = iTall
+	} else {
+		dt.nodes[tall.parent].right = iTall
+	}
+
+	// Keep the taller grandchild, give the other one to the node
+	iKeep, iGive := iF, iG
+	if dt.nodes[iF].height < dt.nodes[iG].height {
+		iKeep, iGive = iG, iF
+	}
+
+	tall.right = iKeep
+	if fromLeft {
+		dt.nodes[iA].left = iGive
+	} else {
+		dt.nodes[iA].right = iGive
+	}
+	dt.nodes[iGive].parent = iA
+
+	a := &dt.nodes[iA]
+	a.bounds = union(dt.nodes[iShort].bounds, dt.nodes[iGive].bounds)
+	a.height = 1 + max(dt.nodes[iShort].height, dt.nodes[iGive].height)
+
+	tall.bounds = union(a.bounds, dt.nodes[iKeep].bounds)
+	tall.height = 1 + max(a.height, dt.nodes[iKeep].height)
+
+	return iTall
+}
+
+// fatten grows bounds by a margin on every side
+func fatten(bounds Rectangle, margin float32) Rectangle {
+	return Rectangle{
+		Position: raylib.NewVector2(bounds.Position.X-margin, bounds.Position.Y-margin),
+		Width:    bounds.Width + 2*margin,
+		Height:   bounds.Height + 2*margin,
+	}
+}
+
+// union returns the bounds covering two bounds
+func union(a Rectangle, b Rectangle) Rectangle {
+	minX := min(a.Position.X, b.Position.X)
+	minY := min(a.Position.Y, b.Position.Y)
+
+	return Rectangle{
+		Position: raylib.NewVector2(minX, minY),
+		Width:    max(a.Position.X+a.Width, b.Position.X+b.Width) - minX,
+		Height:   max(a.Position.Y+a.Height, b.Position.Y+b.Height) - minY,
+	}
+}
+
+// containsRectangle checks if the outer bounds contain the inner bounds entirely
+func containsRectangle(outer Rectangle, inner Rectangle) bool {
+	return inner.Position.X >= outer.Position.X &&
+		inner.Position.Y >= outer.Position.Y &&
+		inner.Position.X+inner.Width <= outer.Position.X+outer.Width &&
+		inner.Position.Y+inner.Height <= outer.Position.Y+outer.Height
+}
+
+// getPerimeter returns the perimeter of bounds, the cost of a node in the tree
+func getPerimeter(bounds Rectangle) float32 {
+	return 2 * (bounds.Width + bounds.Height)
+}
+
+// rayHitsBounds checks if a ray passes through bounds before maxDistance, clipping it against each axis in turn
+func rayHitsBounds(origin raylib.Vector2, direction raylib.Vector2, maxDistance float32, bounds *Rectangle) bool {
+	near, far := float32(0), maxDistance
+
+	for _, axis := range [2][4]float32{
+		{origin.X, direction.X, bounds.Position.X, bounds.Position.X + bounds.Width},
+		{origin.Y, direction.Y, bounds.Position.Y, bounds.Position.Y + bounds.Height},
+	} {
+		start, step, low, high := axis[0], axis[1], axis[2], axis[3]
+
+		// A ray parallel to the axis never crosses its sides
+		if step == 0 {
+			if start < low || start > high {
+				return false
+			}
+			continue
+		}
+
+		enter, exit := (low-start)/step, (high-start)/step
+		if enter > exit {
+			enter, exit = exit, enter
+		}
+
+		near, far = max(near, enter), min(far, exit)
+		if near > far {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,259 @@
+package physics
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+var broadPhaseCounts = []int{1_000, 10_000}
+
+// newBroadPhaseWorld places count boxes of 8 to 16 pixels at random, in an area keeping the same density for every count
+func newBroadPhaseWorld(count int) (Rectangle, []Rectangle) {
+	random := rand.New(rand.NewSource(1))
+	side := float32(math.Sqrt(float64(count))) * 40
+	area := Rectangle{Width: side, Height: side}
+
+	boxes := make([]Rectangle, count)
+	for i := range boxes {
+		size := 8 + random.Float32()*8
+		boxes[i] = Rectangle{
+			Position: raylib.NewVector2(random.Float32()*(side-size), random.Float32()*(side-size)),
+			Width:    size,
+			Height:   size,
+		}
+	}
+
+	return area, boxes
+}
+
+// stepBroadPhaseWorld moves every tenth box by more than the margin of the DynamicTree, back and forth.
+// The other boxes rest like static and sleeping bodies.
+func stepBroadPhaseWorld(boxes []Rectangle, step int) {
+	offset := float32(DefaultAABBMargin + 1)
+	if step%2 == 1 {
+		offset = -offset
+	}
+
+	for i := 0; i < len(boxes); i += 10 {
+		boxes[i].Position.X += offset
+	}
+}
+
+// BenchmarkQuadTree rebuilds the QuadTree of box centers every step and queries it with every box grown by the largest box,
+// like the broad phase did before the DynamicTree
+func BenchmarkQuadTree(b *testing.B) {
+	for _, count := range broadPhaseCounts {
+		b.Run(fmt.Sprintf("%d", count), func(b *testing.B) {
+			area, boxes := newBroadPhaseWorld(count)
+			found := []uint64{}
+
+			for n := 0; n < b.N; n++ {
+				stepBroadPhaseWorld(boxes, n)
+
+				tree := NewQuadTree(area, 4, 5, 0)
+				maxExtent := float32(0)
+				for i, box := range boxes {
+					tree.Insert(uint64(i), raylib.NewVector2(box.Position.X+box.Width/2, box.Position.Y+box.Height/2))
+					maxExtent = max(maxExtent, box.Width, box.Height)
+				}
+
+				pairs := make(map[[2]uint64]bool)
+				for i, box := range boxes {
+					found = found[:0]
+					tree.Query(fatten(box, maxExtent/2), &found)
+
+					for _, other := range found {
+						if uint64(i) < other && box.Intersects(&boxes[other]) {
+							pairs[[2]uint64{uint64(i), other}] = true
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDynamicTree moves the proxies of the boxes every step and updates the pairs of those that left their grown bounds
+func BenchmarkDynamicTree(b *testing.B) {
+	for _, count := range broadPhaseCounts {
+		b.Run(fmt.Sprintf("%d", count), func(b *testing.B) {
+			_, boxes := newBroadPhaseWorld(count)
+
+			tree := NewDynamicTree(DefaultAABBMargin)
+			proxies := make([]int32, len(boxes))
+			for i, box := range boxes {
+				proxies[i] = tree.CreateProxy(uint64(i), box)
+			}
+			tree.UpdatePairs()
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				stepBroadPhaseWorld(boxes, n)
+
+				for i := 0; i < len(boxes); i += 10 {
+					tree.MoveProxy(proxies[i], boxes[i])
+				}
+
+				tree.UpdatePairs()
+			}
+		})
+	}
+}
+
+// bruteForcePairs returns every pair of proxies whose grown bounds overlap, in entity order
+func bruteForcePairs(tree *DynamicTree, proxies map[uint64]int32) [][2]uint64 {
+	pairs := [][2]uint64{}
+
+	for eA, proxyA := range proxies {
+		boundsA := tree.GetFatBounds(proxyA)
+		for eB, proxyB := range proxies {
+			boundsB := tree.GetFatBounds(proxyB)
+			if eA < eB && boundsA.Intersects(&boundsB) {
+				pairs = append(pairs, [2]uint64{eA, eB})
+			}
+		}
+	}
+
+	slices.SortFunc(pairs, func(a [2]uint64, b [2]uint64) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	return pairs
+}
+
+// The pairs kept between calls of UpdatePairs are the same as testing every proxy against every other,
+// while proxies are created, moved and destroyed
+func TestUpdatePairsMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	randomBounds := func() Rectangle {
+		return Rectangle{Position: raylib.NewVector2(random.Float32()*400, random.Float32()*400), Width: 10 + random.Float32()*30, Height: 10 + random.Float32()*30}
+	}
+
+	tree := NewDynamicTree(DefaultAABBMargin)
+	proxies := make(map[uint64]int32)
+	nextEntity := uint64(1)
+
+	for step := 0; step < 200; step++ {
+		for i := 0; i < 5; i++ {
+			proxies[nextEntity] = tree.CreateProxy(nextEntity, randomBounds())
+			nextEntity++
+		}
+
+		for eID, proxyID := range proxies {
+			switch random.Intn(10) {
+			case 0:
+				tree.DestroyProxy(proxyID)
+				delete(proxies, eID)
+			case 1, 2:
+				bounds := tree.GetFatBounds(proxyID)
+				bounds.Position = raylib.Vector2Add(bounds.Position, raylib.NewVector2(random.Float32()*20-10, random.Float32()*20-10))
+				tree.MoveProxy(proxyID, fatten(bounds, -DefaultAABBMargin))
+			}
+		}
+
+		want := bruteForcePairs(tree, proxies)
+		if got := tree.UpdatePairs(); !slices.Equal(got, want) {
+			t.Fatalf("step %d: %d pairs, want %d\ngot:  %v\nwant: %v", step, len(got), len(want), got, want)
+		}
+	}
+}
+
+// A raycast visits the proxies whose grown bounds the ray passes through and no others,
+// and skips the proxies past the distance it is clipped at
+func TestRaycastVisitsProxiesOnTheRay(t *testing.T) {
+	_, boxes := newBroadPhaseWorld(2_000)
+
+	tree := NewDynamicTree(DefaultAABBMargin)
+	proxies := make(map[uint64]int32)
+	for i, box := range boxes {
+		proxies[uint64(i)] = tree.CreateProxy(uint64(i), box)
+	}
+
+	origin := raylib.NewVector2(3, 7)
+	direction := raylib.Vector2Normalize(raylib.NewVector2(1, 0.9))
+	maxDistance := float32(2_000)
+
+	onRay := func(distance float32) []uint64 {
+		want := []uint64{}
+		for eID, proxyID := range proxies {
+			bounds := tree.GetFatBounds(proxyID)
+			if rayHitsBounds(origin, direction, distance, &bounds) {
+				want = append(want, eID)
+			}
+		}
+		slices.Sort(want)
+		return want
+	}
+
+	visited := []uint64{}
+	tree.Raycast(origin, direction, maxDistance, func(eID uint64, maxDistance float32) float32 {
+		visited = append(visited, eID)
+		return maxDistance
+	})
+	slices.Sort(visited)
+
+	want := onRay(maxDistance)
+	if !slices.Equal(visited, want) {
+		t.Fatalf("visited %d proxies, want the %d on the ray", len(visited), len(want))
+	}
+	if len(want) == 0 || len(want) > len(boxes)/10 {
+		t.Fatalf("%d of %d proxies are on the ray, the ray doesn't test the tree", len(want), len(boxes))
+	}
+
+	// Clipping the ray at the first proxy visited skips everything further than it
+	clip := float32(300)
+	visited = visited[:0]
+	tree.Raycast(origin, direction, maxDistance, func(eID uint64, maxDistance float32) float32 {
+		visited = append(visited, eID)
+		return clip
+	})
+
+	for _, eID := range visited[1:] {
+		if bounds := tree.GetFatBounds(proxies[eID]); !rayHitsBounds(origin, direction, clip, &bounds) {
+			t.Fatalf("proxy %d at %v was visited past the clipped distance", eID, bounds)
+		}
+	}
+	for _, eID := range onRay(clip) {
+		if !slices.Contains(visited, eID) {
+			t.Fatalf("proxy %d before the clipped distance was not visited", eID)
+		}
+	}
+}
+
+// BenchmarkRaycast casts a long diagonal ray through the tree, against querying the bounds of the whole ray
+func BenchmarkRaycast(b *testing.B) {
+	for _, count := range broadPhaseCounts {
+		area, boxes := newBroadPhaseWorld(count)
+
+		tree := NewDynamicTree(DefaultAABBMargin)
+		for i, box := range boxes {
+			tree.CreateProxy(uint64(i), box)
+		}
+
+		origin := area.Position
+		direction := raylib.Vector2Normalize(raylib.NewVector2(area.Width, area.Height))
+		maxDistance := raylib.Vector2Length(raylib.NewVector2(area.Width, area.Height))
+
+		b.Run(fmt.Sprintf("tree/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				tree.Raycast(origin, direction, maxDistance, func(eID uint64, maxDistance float32) float32 {
+					return maxDistance
+				})
+			}
+		})
+
+		b.Run(fmt.Sprintf("bounds/%d", count), func(b *testing.B) {
+			found := []uint64{}
+			for n := 0; n < b.N; n++ {
+				found = found[:0]
+				tree.Query(area, &found)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package physics
+
+import (
+	"math"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// referenceFaceTolerance prefers the first polygon's face when both polygons separate by about the same,
+// so the reference face doesn't flip between frames
+const referenceFaceTolerance = 0.01
+
+// Manifold describes how two shapes overlap
+type Manifold struct {
+	Normal      raylib.Vector2   // Points from the first shape towards the second
+	Penetration float32          // Distance along the normal to separate the shapes
+	Points      []raylib.Vector2 // Contact points in world space
+}
+
+// Collide tests two shapes against each other and returns their contact manifold.
+// It returns false if the shapes don't overlap.
+func Collide(a *Shape, b *Shape) (Manifold, bool) {
+	switch {
+	case !a.IsRounded() && !b.IsRounded():
+		return collidePolygons(a, b)
+
+	case !a.IsRounded():
+		return collidePolygonRounded(a, b)
+
+	case !b.IsRounded():
+		// Test from the polygon and turn the normal around so it still points from a to b
+		m, colliding := collidePolygonRounded(b, a)
+		m.Normal = raylib.Vector2Negate(m.Normal)
+		return m, colliding
+
+	default:
+		return collideRounded(a, b)
+	}
+}
+
+// * Rounded shapes
+
+// collideRounded tests two circles or capsules by the closest points of their cores
+func collideRounded(a *Shape, b *Shape) (Manifold, bool) {
+	pointA, pointB := closestPointsSegments(a.Segment[0], a.Segment[1], b.Segment[0], b.Segment[1])
+
+	delta := raylib.Vector2Subtract(pointB, pointA)
+	distance := raylib.Vector2Length(delta)
+	radius := a.Radius + b.Radius
+
+	if distance >= radius {
+		return Manifold{}, false
+	}
+
+	// Cores on top of each other have no direction, fall back to the direction between the centers
+	normal := raylib.NewVector2(0, 1)
+	if distance > 0 {
+		normal = raylib.Vector2Scale(delta, 1/distance)
+	} else if centerDelta := raylib.Vector2Subtract(b.Center, a.Center); raylib.Vector2Length(centerDelta) > 0 {
+		normal = raylib.Vector2Normalize(centerDelta)
+	}
+
+	penetration := radius - distance
+
+	return Manifold{
+		Normal:      normal,
+		Penetration: penetration,
+		Points:      []raylib.Vector2{raylib.Vector2Add(pointA, raylib.Vector2Scale(normal, a.Radius-penetration/2))},
+	}, true
+}
+
+// collidePolygonRounded tests a polygon against a circle or capsule, the normal points from the polygon
+func collidePolygonRounded(polygon *Shape, rounded *Shape) (Manifold, bool) {
+	if len(polygon.Vertices) == 0 {
+		return Manifold{}, false
+	}
+
+	// Find the closest points between the polygon edges and the core of the rounded shape
+	closestDistance := float32(math.MaxFloat32)
+	var closestPolygon, closestCore raylib.Vector2
+
+	count := len(polygon.Vertices)
+	for i := range polygon.Vertices {
+		pointPolygon, pointCore := closestPointsSegments(polygon.Vertices[i], polygon.Vertices[(i+1)%count], rounded.Segment[0], rounded.Segment[1])
+
+		distance := raylib.Vector2Distance(pointPolygon, pointCore)
+		if distance < closestDistance {
+			closestDistance = distance
+			closestPolygon = pointPolygon
+			closestCore = pointCore
+		}
+	}
+
+	coreInside := containsPoint(polygon, rounded.Segment[0]) || containsPoint(polygon, rounded.Segment[1])
+
+	// The core is outside the polygon, the closest points give the normal
+	if !coreInside && closestDistance > 1e-6 {
+		if closestDistance >= rounded.Radius {
+			return Manifold{}, false
+		}
+
+		return Manifold{
+			Normal:      raylib.Vector2Scale(raylib.Vector2Subtract(closestCore, closestPolygon), 1/closestDistance),
+			Penetration: rounded.Radius - closestDistance,
+			Points:      []raylib.Vector2{closestPolygon},
+		}, true
+	}
+
+	// The core reaches into the polygon, separate along the axis of least overlap
+	axes := polygon.Normals
+	if segment := raylib.Vector2Subtract(rounded.Segment[1], rounded.Segment[0]); raylib.Vector2Length(segment) > 0 {
+		axes = append(axes[:len(axes):len(axes)], raylib.Vector2Normalize(raylib.NewVector2(segment.Y, -segment.X)))
+	}
+
+	normal, penetration := leastOverlapAxis(axes, polygon.Vertices, rounded.Segment[:], rounded.Radius)
+	if raylib.Vector2DotProduct(raylib.Vector2Subtract(rounded.Center, polygon.Center), normal) < 0 {
+		normal = raylib.Vector2Negate(normal)
+	}
+
+	// The deepest end of the core is the contact point
+	point := rounded.Segment[0]
+	if raylib.Vector2DotProduct(rounded.Segment[1], normal) < raylib.Vector2DotProduct(point, normal) {
+		point = rounded.Segment[1]
+	}
+
+	return Manifold{
+		Normal:      normal,
+		Penetration: penetration,
+		Points:      []raylib.Vector2{point},
+	}, true
+}
+
+// leastOverlapAxis returns the axis on which the polygon and the rounded core overlap the least, and the overlap
+func leastOverlapAxis(axes []raylib.Vector2, vertices []raylib.Vector2, core []raylib.Vector2, radius float32) (raylib.Vector2, float32) {
+	bestAxis := raylib.NewVector2(0, 1)
+	bestOverlap := float32(math.MaxFloat32)
+
+	for _, axis := range axes {
+		minA, maxA := project(vertices, axis)
+		minB, maxB := project(core, axis)
+
+		overlap := min(maxA, maxB+radius) - max(minA, minB-radius)
+		if overlap < bestOverlap {
+			bestOverlap = overlap
+			bestAxis = axis
+		}
+	}
+
+	return bestAxis, max(bestOverlap, 0)
+}
+
+// * Polygons
+
+// collidePolygons tests two convex polygons with the separating axis theorem.
+// The contact points are found by clipping the incident edge against the reference face.
+func collidePolygons(a *Shape, b *Shape) (Manifold, bool) {
+	if len(a.Vertices) == 0 || len(b.Vertices) == 0 {
+		return Manifold{}, false
+	}
+
+	edgeA, separationA := maxSeparation(a, b)
+	if separationA > 0 {
+		return Manifold{}, false
+	}
+
+	edgeB, separationB := maxSeparation(b, a)
+	if separationB > 0 {
+		return Manifold{}, false
+	}
+
+	// The face separating the most is the reference face, the other polygon is clipped against it
+	reference, incident, referenceEdge, flip := a, b, edgeA, false
+	if separationB > separationA+referenceFaceTolerance {
+		reference, incident, referenceEdge, flip = b, a, edgeB, true
+	}
+
+	referenceNormal := reference.Normals[referenceEdge]
+	v1 := reference.Vertices[referenceEdge]
+	v2 := reference.Vertices[(referenceEdge+1)%len(reference.Vertices)]
+
+	// The incident edge is the one facing the reference face the most
+	incidentEdge := 0
+	minDot := float32(math.MaxFloat32)
+	for i, normal := range incident.Normals {
+		if dot := raylib.Vector2DotProduct(referenceNormal, normal); dot < minDot {
+			minDot = dot
+			incidentEdge = i
+		}
+	}
+
+	points := []raylib.Vector2{
+		incident.Vertices[incidentEdge],
+		incident.Vertices[(incidentEdge+1)%len(incident.Vertices)],
+	}
+
+	// Clip the incident edge to the sides of the reference face
+	tangent := raylib.Vector2Normalize(raylib.Vector2Subtract(v2, v1))
+	points = clipSegment(points, raylib.Vector2Negate(tangent), -raylib.Vector2DotProduct(tangent, v1))
+	points = clipSegment(points, tangent, raylib.Vector2DotProduct(tangent, v2))
+
+	// Keep the points below the reference face
+	m := Manifold{Normal: referenceNormal}
+	for _, point := range points {
+		separation := raylib.Vector2DotProduct(referenceNormal, raylib.Vector2Subtract(point, v1))
+		if separation <= 0 {
+			m.Points = append(m.Points, point)
+			m.Penetration = max(m.Penetration, -separation)
+		}
+	}
+
+	if len(m.Points) == 0 {
+		return Manifold{}, false
+	}
+
+	if flip {
+		m.Normal = raylib.Vector2Negate(m.Normal)
+	}
+
+	return m, true
+}
+
+// maxSeparation returns the edge of a whose normal separates b the most, and by how much.
+// A negative separation means the polygons overlap along every normal of a.
+func maxSeparation(a *Shape, b *Shape) (int, float32) {
+	bestEdge := 0
+	bestSeparation := float32(-math.MaxFloat32)
+
+	for i, normal := range a.Normals {
+		separation := float32(math.MaxFloat32)
+		for _, vertex := range b.Vertices {
+			separation = min(separation, raylib.Vector2DotProduct(normal, raylib.Vector2Subtract(vertex, a.Vertices[i])))
+		}
+
+		if separation > bestSeparation {
+			bestSeparation = separation
+			bestEdge = i
+		}
+	}
+
+	return bestEdge, bestSeparation
+}
+
+// clipSegment keeps the part of a segment where dot(normal, point) <= offset
+func clipSegment(points []raylib.Vector2, normal raylib.Vector2, offset float32) []raylib.Vector2 {
+	if len(points) < 2 {
+		return points
+	}
+
+	distance0 := raylib.Vector2DotProduct(normal, points[0]) - offset
+	distance1 := raylib.Vector2DotProduct(normal, points[1]) - offset
+
+	clipped := make([]raylib.Vector2, 0, 2)
+	if distance0 <= 0 {
+		clipped = append(clipped, points[0])
+	}
+	if distance1 <= 0 {
+		clipped = append(clipped, points[1])
+	}
+
+	// The points are on both sides, add the point where the segment crosses
+	if distance0*distance1 < 0 {
+		t := distance0 / (distance0 - distance1)
+		clipped = append(clipped, raylib.Vector2Lerp(points[0], points[1], t))
+	}
+
+	return clipped
+}
+
+// * Helpers
+
+// containsPoint checks if a point is inside a convex polygon
+func containsPoint(polygon *Shape, point raylib.Vector2) bool {
+	for i, normal := range polygon.Normals {
+		if raylib.Vector2DotProduct(normal, raylib.Vector2Subtract(point, polygon.Vertices[i])) > 0 {
+			return false
+		}
+	}
+
+	return len(polygon.Normals) > 0
+}
+
+// project returns the range of points projected on an axis
+func project(points []raylib.Vector2, axis raylib.Vector2) (float32, float32) {
+	minProjection := float32(math.MaxFloat32)
+	maxProjection := float32(-math.MaxFloat32)
+
+	for _, point := range points {
+		projection := raylib.Vector2DotProduct(point, axis)
+		minProjection = min(minProjection, projection)
+		maxProjection = max(maxProjection, projection)
+	}
+
+	return minProjection, maxProjection
+}
+
+// closestPointOnSegment returns the point of the segment ab closest to p
+func closestPointOnSegment(p raylib.Vector2, a raylib.Vector2, b raylib.Vector2) raylib.Vector2 {
+	ab := raylib.Vector2Subtract(b, a)
+
+	lengthSquared := raylib.Vector2DotProduct(ab, ab)
+	if lengthSquared == 0 {
+		return a
+	}
+
+	t := clamp(raylib.Vector2DotProduct(raylib.Vector2Subtract(p, a), ab)/lengthSquared, 0, 1)
+	return raylib.Vector2Add(a, raylib.Vector2Scale(ab, t))
+}
+
+// closestPointsSegments returns the closest points between the segments p1q1 and p2q2
+func closestPointsSegments(p1 raylib.Vector2, q1 raylib.Vector2, p2 raylib.Vector2, q2 raylib.Vector2) (raylib.Vector2, raylib.Vector2) {
+	d1 := raylib.Vector2Subtract(q1, p1)
+	d2 := raylib.Vector2Subtract(q2, p2)
+	r := raylib.Vector2Subtract(p1, p2)
+
+	a := raylib.Vector2DotProduct(d1, d1)
+	e := raylib.Vector2DotProduct(d2, d2)
+	f := raylib.Vector2DotProduct(d2, r)
+
+	// Either segment is a point
+	if a == 0 {
+		return p1, closestPointOnSegment(p1, p2, q2)
+	}
+	if e == 0 {
+		return closestPointOnSegment(p2, p1, q1), p2
+	}
+
+	// Segments crossing each other have no single pair of closest points, return the crossing
+	if point, crossing := intersectSegments(p1, q1, p2, q2); crossing {
+		return point, point
+	}
+
+	c := raylib.Vector2DotProduct(d1, r)
+	b := raylib.Vector2DotProduct(d1, d2)
+	denominator := a*e - b*b
+
+	// Parallel segments pick any s, the clamping below fixes t
+	s := float32(0)
+	if denominator != 0 {
+		s = clamp((b*f-c*e)/denominator, 0, 1)
+	}
+
+	t := (b*s + f) / e
+	if t < 0 {
+		t = 0
+		s = clamp(-c/a, 0, 1)
+	} else if t > 1 {
+		t = 1
+		s = clamp((b-c)/a, 0, 1)
+	}
+
+	return raylib.Vector2Add(p1, raylib.Vector2Scale(d1, s)), raylib.Vector2Add(p2, raylib.Vector2Scale(d2, t))
+}
+
+// intersectSegments returns the point where the segments p1q1 and p2q2 cross, if they do
+func intersectSegments(p1 raylib.Vector2, q1 raylib.Vector2, p2 raylib.Vector2, q2 raylib.Vector2) (raylib.Vector2, bool) {
+	d1 := raylib.Vector2Subtract(q1, p1)
+	d2 := raylib.Vector2Subtract(q2, p2)
+
+	denominator := d1.X*d2.Y - d1.Y*d2.X
+	if denominator == 0 {
+		return raylib.Vector2{}, false
+	}
+
+	r := raylib.Vector2Subtract(p2, p1)
+	s := (r.X*d2.Y - r.Y*d2.X) / denominator
+	t := (r.X*d1.Y - r.Y*d1.X) / denominator
+
+	if s < 0 || s > 1 || t < 0 || t > 1 {
+		return raylib.Vector2{}, false
+	}
+
+	return raylib.Vector2Add(p1, raylib.Vector2Scale(d1, s)), true
+}
+
+func clamp(value float32, minValue float32, maxValue float32) float32 {
+	return max(minValue, min(value, maxValue))
+}
@@ -5,30 +5,10 @@ import (
 	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
-// Recatangle defines the boundry of each QuadTree node
-type Rectangle struct {
-	Position raylib.Vector2
-	Width    float32
-	Height   float32
-}
-
-// Contains checks if a point is within the bounds of a rectangle
-func (r *Rectangle) Contains(point raylib.Vector2) bool {
-	return point.X >= r.Position.X &&
-		point.X <= r.Position.X+r.Width &&
-		point.Y >= r.Position.Y &&
-		point.Y <= r.Position.Y+r.Height
-}
-
-// Intersects checks if two rectrangles intersect
-func (r *Rectangle) Intersects(other *Rectangle) bool {
-	return !(other.Position.X > r.Position.X+r.Width ||
-		other.Position.X+other.Width < r.Position.X ||
-		other.Position.Y > r.Position.Y+r.Height ||
-		other.Position.Y+other.Height < r.Position.Y)
-}
-
 // QuadTree represents a node in the QuadTree
+//
+// Deprecated: the broad phase uses the DynamicTree, which keeps the bounds of colliders between steps instead of
+// being rebuilt from their centers every step. Use DynamicTree instead.
 type QuadTree struct {
 	Boundry      Rectangle
 	Capacity     int32
@@ -43,6 +23,8 @@ type QuadTree struct {
 }
 
 // NewQuadTree initializes a new QuadTree node
+//
+// Deprecated: use NewDynamicTree instead.
 func NewQuadTree(b Rectangle, c int32, mD int32, cD int32) *QuadTree {
 	return &QuadTree{
 		Boundry:      b,
@@ -0,0 +1,28 @@
+package physics
+
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Rectangle is an axis aligned rectangle, the bounds of colliders and of the nodes of the DynamicTree and QuadTree
+type Rectangle struct {
+	Position raylib.Vector2
+	Width    float32
+	Height   float32
+}
+
+// Contains checks if a point is within the bounds of a rectangle
+func (r *Rectangle) Contains(point raylib.Vector2) bool {
+	return point.X >= r.Position.X &&
+		point.X <= r.Position.X+r.Width &&
+		point.Y >= r.Position.Y &&
+		point.Y <= r.Position.Y+r.Height
+}
+
+// Intersects checks if two rectrangles intersect
+func (r *Rectangle) Intersects(other *Rectangle) bool {
+	return !(other.Position.X > r.Position.X+r.Width ||
+		other.Position.X+other.Width < r.Position.X ||
+		other.Position.Y > r.Position.Y+r.Height ||
+		other.Position.Y+other.Height < r.Position.Y)
+}
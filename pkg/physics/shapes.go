@@ -0,0 +1,251 @@
+package physics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ShapeKind identifies how a Shape is tested by the narrow phase
+type ShapeKind int
+
+const (
+	CircleShape ShapeKind = iota
+	CapsuleShape
+	PolygonShape
+)
+
+// Errors returned by ValidatePolygon
+var (
+	ErrTooFewPoints    = errors.New("a polygon needs at least 3 points")
+	ErrCollinearPoints = errors.New("polygon has collinear points")
+	ErrConcavePolygon  = errors.New("polygon is not convex")
+)
+
+// collinearTolerance is the sine of the angle between two edges below which they count as collinear
+const collinearTolerance = 1e-4
+
+// Shape is a collider in world space.
+// Circles and capsules are rounded shapes: a point or a segment with a radius.
+// Polygons, including boxes, are convex with their vertices in counter-clockwise order.
+type Shape struct {
+	Kind   ShapeKind
+	Center raylib.Vector2
+
+	// Rounded shapes
+	Radius  float32
+	Segment [2]raylib.Vector2
+
+	// Polygons
+	Vertices []raylib.Vector2
+	Normals  []raylib.Vector2
+}
+
+// NewCircleShape returns a circle at a center
+func NewCircleShape(center raylib.Vector2, radius float32) Shape {
+	return Shape{
+		Kind:    CircleShape,
+		Center:  center,
+		Radius:  radius,
+		Segment: [2]raylib.Vector2{center, center},
+	}
+}
+
+// NewCapsuleShape returns a vertical capsule of a total height, rotated by rotation degrees around its center
+func NewCapsuleShape(center raylib.Vector2, radius float32, height float32, rotation float32) Shape {
+	halfSegment := max(height/2-radius, 0)
+	offset := raylib.Vector2Rotate(raylib.NewVector2(0, halfSegment), rotation*raylib.Deg2rad)
+
+	return Shape{
+		Kind:    CapsuleShape,
+		Center:  center,
+		Radius:  radius,
+		Segment: [2]raylib.Vector2{raylib.Vector2Subtract(center, offset), raylib.Vector2Add(center, offset)},
+	}
+}
+
+// NewBoxShape returns a box of a size centered on center, rotated by rotation degrees
+func NewBoxShape(center raylib.Vector2, size raylib.Vector2, rotation float32) Shape {
+	halfX := size.X / 2
+	halfY := size.Y / 2
+
+	return NewPolygonShape(center, []raylib.Vector2{
+		raylib.NewVector2(-halfX, -halfY),
+		raylib.NewVector2(halfX, -halfY),
+		raylib.NewVector2(halfX, halfY),
+		raylib.NewVector2(-halfX, halfY),
+	}, rotation)
+}
+
+// NewPolygonShape returns a convex polygon from points relative to center, rotated by rotation degrees.
+// The points can be in either winding order and are expected to have passed ValidatePolygon.
+func NewPolygonShape(center raylib.Vector2, points []raylib.Vector2, rotation float32) Shape {
+	count := len(points)
+	vertices := make([]raylib.Vector2, count)
+
+	for i, point := range points {
+		vertices[i] = raylib.Vector2Add(center, raylib.Vector2Rotate(point, rotation*raylib.Deg2rad))
+	}
+
+	// Keep the vertices counter-clockwise so the edge normals point outwards
+	if signedArea(vertices) < 0 {
+		for i, j := 0, count-1; i < j; i, j = i+1, j-1 {
+			vertices[i], vertices[j] = vertices[j], vertices[i]
+		}
+	}
+
+	normals := make([]raylib.Vector2, count)
+	for i := range vertices {
+		edge := raylib.Vector2Subtract(vertices[(i+1)%count], vertices[i])
+		normals[i] = raylib.Vector2Normalize(raylib.NewVector2(edge.Y, -edge.X))
+	}
+
+	return Shape{
+		Kind:     PolygonShape,
+		Center:   center,
+		Vertices: vertices,
+		Normals:  normals,
+	}
+}
+
+// ValidatePolygon checks that points describe a convex polygon the narrow phase can handle:
+// at least 3 points, no two consecutive edges on the same line and every point turning the same way.
+func ValidatePolygon(points []raylib.Vector2) error {
+	count := len(points)
+	if count < 3 {
+		return fmt.Errorf("%w, got %d", ErrTooFewPoints, count)
+	}
+
+	winding := float32(0)
+	for i := range points {
+		previous := raylib.Vector2Subtract(points[(i+1)%count], points[i])
+		next := raylib.Vector2Subtract(points[(i+2)%count], points[(i+1)%count])
+		cross := previous.X*next.Y - previous.Y*next.X
+
+		// Repeated points make an edge without a length, which counts as collinear too
+		lengths := raylib.Vector2Length(previous) * raylib.Vector2Length(next)
+		if lengths == 0 || float32(math.Abs(float64(cross))) <= collinearTolerance*lengths {
+			return fmt.Errorf("%w at point %d", ErrCollinearPoints, (i+1)%count)
+		}
+
+		if winding == 0 {
+			winding = cross
+		} else if (winding > 0) != (cross > 0) {
+			return fmt.Errorf("%w at point %d", ErrConcavePolygon, (i+1)%count)
+		}
+	}
+
+	// A star crossing itself turns the same way at every point, but its points are not all inside every edge
+	for i := range points {
+		start := points[i]
+		edge := raylib.Vector2Subtract(points[(i+1)%count], start)
+
+		for j, point := range points {
+			if j == i || j == (i+1)%count {
+				continue
+			}
+
+			side := edge.X*(point.Y-start.Y) - edge.Y*(point.X-start.X)
+			if (winding > 0) != (side > 0) {
+				return fmt.Errorf("%w, it crosses itself", ErrConcavePolygon)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsRounded checks if the shape is a circle or a capsule
+func (s *Shape) IsRounded() bool {
+	return s.Kind != PolygonShape
+}
+
+// Bounds returns the axis aligned rectangle enclosing the shape
+func (s *Shape) Bounds() Rectangle {
+	points := s.Vertices
+	if s.IsRounded() {
+		points = s.Segment[:]
+	}
+
+	if len(points) == 0 {
+		return Rectangle{Position: s.Center}
+	}
+
+	minPoint := points[0]
+	maxPoint := points[0]
+	for _, point := range points[1:] {
+		minPoint = raylib.NewVector2(min(minPoint.X, point.X), min(minPoint.Y, point.Y))
+		maxPoint = raylib.NewVector2(max(maxPoint.X, point.X), max(maxPoint.Y, point.Y))
+	}
+
+	return Rectangle{
+		Position: raylib.NewVector2(minPoint.X-s.Radius, minPoint.Y-s.Radius),
+		Width:    maxPoint.X - minPoint.X + 2*s.Radius,
+		Height:   maxPoint.Y - minPoint.Y + 2*s.Radius,
+	}
+}
+
+// Inertia returns the moment of inertia of the shape around its center for a mass spread evenly over its area
+func (s *Shape) Inertia(mass float32) float32 {
+	switch s.Kind {
+	case CircleShape:
+		return mass * s.Radius * s.Radius / 2
+
+	case CapsuleShape:
+		// A box between the ends and a circle split over both ends, sharing the mass by area
+		length := raylib.Vector2Distance(s.Segment[0], s.Segment[1])
+		boxArea := 2 * s.Radius * length
+		circleArea := math.Pi * s.Radius * s.Radius
+		if boxArea+circleArea == 0 {
+			return 0
+		}
+
+		boxMass := mass * boxArea / (boxArea + circleArea)
+		circleMass := mass - boxMass
+
+		boxInertia := boxMass * (length*length + 4*s.Radius*s.Radius) / 12
+		circleInertia := circleMass * (s.Radius*s.Radius/2 + length*length/4)
+
+		return boxInertia + circleInertia
+
+	default:
+		// Sum the triangles between the center and every edge, then move the inertia to the centroid
+		// since a polygon's points don't have to be centered on its position
+		area := float32(0)
+		inertia := float32(0)
+		centroid := raylib.Vector2{}
+
+		for i := range s.Vertices {
+			a := raylib.Vector2Subtract(s.Vertices[i], s.Center)
+			b := raylib.Vector2Subtract(s.Vertices[(i+1)%len(s.Vertices)], s.Center)
+			cross := a.X*b.Y - b.X*a.Y
+
+			area += cross / 2
+			inertia += cross * (raylib.Vector2DotProduct(a, a) + raylib.Vector2DotProduct(a, b) + raylib.Vector2DotProduct(b, b)) / 12
+			centroid = raylib.Vector2Add(centroid, raylib.Vector2Scale(raylib.Vector2Add(a, b), cross/6))
+		}
+
+		if area == 0 {
+			return 0
+		}
+
+		centroid = raylib.Vector2Scale(centroid, 1/area)
+
+		return mass * (inertia/area - raylib.Vector2DotProduct(centroid, centroid))
+	}
+}
+
+// signedArea returns twice the signed area of a polygon, positive for counter-clockwise vertices
+func signedArea(vertices []raylib.Vector2) float32 {
+	area := float32(0)
+
+	for i := range vertices {
+		a := vertices[i]
+		b := vertices[(i+1)%len(vertices)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+
+	return area
+}
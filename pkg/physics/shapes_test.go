@@ -0,0 +1,59 @@
+package physics
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestValidatePolygon(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []raylib.Vector2
+		want   error
+	}{
+		{"triangle", []raylib.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}, nil},
+		{"clockwise square", []raylib.Vector2{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 10, Y: 10}, {X: 10, Y: 0}}, nil},
+		{"two points", []raylib.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}}, ErrTooFewPoints},
+		{"point on an edge", []raylib.Vector2{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}, ErrCollinearPoints},
+		{"repeated point", []raylib.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}, ErrCollinearPoints},
+		{"line", []raylib.Vector2{{X: 0, Y: 0}, {X: 5, Y: 5}, {X: 10, Y: 10}}, ErrCollinearPoints},
+		{"arrow", []raylib.Vector2{{X: 0, Y: 0}, {X: 10, Y: 5}, {X: 0, Y: 10}, {X: 3, Y: 5}}, ErrConcavePolygon},
+		{"star", []raylib.Vector2{{X: 0, Y: -10}, {X: 6, Y: 8}, {X: -10, Y: -3}, {X: 10, Y: -3}, {X: -6, Y: 8}}, ErrConcavePolygon},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePolygon(test.points)
+			if test.want == nil && err != nil {
+				t.Fatalf("ValidatePolygon = %v, want no error", err)
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Fatalf("ValidatePolygon = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+// The inertia of a polygon is about its centroid, so moving its points away from its position doesn't change it
+func TestPolygonInertiaAboutCentroid(t *testing.T) {
+	const mass = 2
+
+	square := NewBoxShape(raylib.NewVector2(100, 100), raylib.NewVector2(10, 20), 30)
+	want := float32(mass * (10*10 + 20*20) / 12.0)
+	if got := square.Inertia(mass); math.Abs(float64(got-want)) > 1e-2 {
+		t.Fatalf("centered box inertia = %v, want %v", got, want)
+	}
+
+	offset := NewPolygonShape(raylib.NewVector2(100, 100), []raylib.Vector2{
+		{X: 45, Y: 40},
+		{X: 55, Y: 40},
+		{X: 55, Y: 60},
+		{X: 45, Y: 60},
+	}, 30)
+	if got := offset.Inertia(mass); math.Abs(float64(got-want)) > 1e-2 {
+		t.Fatalf("offset box inertia = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package physicssystems
+
+import (
+	// STD
+	"math"
+
+	// ECS
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+
+	// PHYSICS
+	"github.com/webbelito/Fenrir/pkg/physics"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	// RAYLIB
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// colliderComponents are the collider types, an entity is expected to have at most one of them
+var colliderComponents = []ecs.ComponentType{
+	ecs.BoxColliderComponent,
+	ecs.CircleColliderComponent,
+	ecs.CapsuleColliderComponent,
+	ecs.PolygonColliderComponent,
+}
+
+// getColliderEntities returns the entities with a Transform2D and any collider
+func getColliderEntities(em *ecs.ECSManager) []uint64 {
+	entities := []uint64{}
+
+	for _, colliderComponent := range colliderComponents {
+		entities = append(entities, em.GetComponentsManager().GetEntitiesWithComponents([]ecs.ComponentType{
+			ecs.Transform2DComponent,
+			colliderComponent,
+		})...)
+	}
+
+	return entities
+}
+
+// getCollider returns the collider settings of an entity, whichever shape it uses
+func getCollider(em *ecs.ECSManager, eID uint64) (*physicscomponents.Collider, bool) {
+	if box, boxExists := ecs.Get[physicscomponents.BoxCollider](em, eID); boxExists {
+		return &box.Collider, true
+	}
+	if circle, circleExists := ecs.Get[physicscomponents.CircleCollider](em, eID); circleExists {
+		return &circle.Collider, true
+	}
+	if capsule, capsuleExists := ecs.Get[physicscomponents.CapsuleCollider](em, eID); capsuleExists {
+		return &capsule.Collider, true
+	}
+	if polygon, polygonExists := ecs.Get[physicscomponents.PolygonCollider](em, eID); polygonExists {
+		return &polygon.Collider, true
+	}
+
+	return nil, false
+}
+
+// getColliderShape returns the collider of an entity in world space, placed and rotated by its Transform2D
+func getColliderShape(em *ecs.ECSManager, eID uint64) (physics.Shape, *physicscomponents.Collider, bool) {
+	transform, transformExists := ecs.Get[components.Transform2D](em, eID)
+	if !transformExists {
+		return physics.Shape{}, nil, false
+	}
+
+	if box, boxExists := ecs.Get[physicscomponents.BoxCollider](em, eID); boxExists {
+		return physics.NewBoxShape(transform.Position, box.Size, transform.Rotation), &box.Collider, true
+	}
+	if circle, circleExists := ecs.Get[physicscomponents.CircleCollider](em, eID); circleExists {
+		return physics.NewCircleShape(transform.Position, circle.Radius), &circle.Collider, true
+	}
+	if capsule, capsuleExists := ecs.Get[physicscomponents.CapsuleCollider](em, eID); capsuleExists {
+		return physics.NewCapsuleShape(transform.Position, capsule.Radius, capsule.Height, transform.Rotation), &capsule.Collider, true
+	}
+	if polygon, polygonExists := ecs.Get[physicscomponents.PolygonCollider](em, eID); polygonExists {
+		return physics.NewPolygonShape(transform.Position, polygon.Points, transform.Rotation), &polygon.Collider, true
+	}
+
+	return physics.Shape{}, nil, false
+}
+
+// getColliderBounds returns the bounds of the collider of an entity in world space,
+// the same as the bounds of its shape but without building the shape
+func getColliderBounds(em *ecs.ECSManager, eID uint64) (physics.Rectangle, bool) {
+	transform, transformExists := ecs.Get[components.Transform2D](em, eID)
+	if !transformExists {
+		return physics.Rectangle{}, false
+	}
+
+	sin, cos := math.Sincos(float64(transform.Rotation * raylib.Deg2rad))
+	sinAbs, cosAbs := float32(math.Abs(sin)), float32(math.Abs(cos))
+
+	// Half of the width and height of the bounds around the position
+	var extents raylib.Vector2
+
+	if box, boxExists := ecs.Get[physicscomponents.BoxCollider](em, eID); boxExists {
+		extents = raylib.NewVector2(cosAbs*box.Size.X/2+sinAbs*box.Size.Y/2, sinAbs*box.Size.X/2+cosAbs*box.Size.Y/2)
+	} else if circle, circleExists := ecs.Get[physicscomponents.CircleCollider](em, eID); circleExists {
+		extents = raylib.NewVector2(circle.Radius, circle.Radius)
+	} else if capsule, capsuleExists := ecs.Get[physicscomponents.CapsuleCollider](em, eID); capsuleExists {
+		// The ends of the vertical segment, rotated, grown by the radius
+		halfSegment := max(capsule.Height/2-capsule.Radius, 0)
+		extents = raylib.NewVector2(sinAbs*halfSegment+capsule.Radius, cosAbs*halfSegment+capsule.Radius)
+	} else if polygon, polygonExists := ecs.Get[physicscomponents.PolygonCollider](em, eID); polygonExists {
+		return getPolygonBounds(transform, polygon.Points), true
+	} else {
+		return physics.Rectangle{}, false
+	}
+
+	return physics.Rectangle{
+		Position: raylib.Vector2Subtract(transform.Position, extents),
+		Width:    2 * extents.X,
+		Height:   2 * extents.Y,
+	}, true
+}
+
+// getPolygonBounds returns the bounds of polygon points placed and rotated by a Transform2D
+func getPolygonBounds(transform *components.Transform2D, points []raylib.Vector2) physics.Rectangle {
+	if len(points) == 0 {
+		return physics.Rectangle{Position: transform.Position}
+	}
+
+	minPoint := raylib.NewVector2(math.MaxFloat32, math.MaxFloat32)
+	maxPoint := raylib.NewVector2(-math.MaxFloat32, -math.MaxFloat32)
+
+	for _, point := range points {
+		vertex := raylib.Vector2Add(transform.Position, raylib.Vector2Rotate(point, transform.Rotation*raylib.Deg2rad))
+		minPoint = raylib.NewVector2(min(minPoint.X, vertex.X), min(minPoint.Y, vertex.Y))
+		maxPoint = raylib.NewVector2(max(maxPoint.X, vertex.X), max(maxPoint.Y, vertex.Y))
+	}
+
+	return physics.Rectangle{
+		Position: minPoint,
+		Width:    maxPoint.X - minPoint.X,
+		Height:   maxPoint.Y - minPoint.Y,
+	}
+}
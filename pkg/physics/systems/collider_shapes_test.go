@@ -0,0 +1,47 @@
+package physicssystems
+
+import (
+	"math"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// The bounds the broad phase computes without building shapes are the bounds of the shapes
+func TestColliderBoundsMatchShapes(t *testing.T) {
+	em := ecs.NewECSManager()
+
+	colliders := map[string]func(eID uint64){
+		"box":     func(eID uint64) { ecs.Add(em, eID, physicscomponents.NewBoxCollider(raylib.NewVector2(40, 10))) },
+		"circle":  func(eID uint64) { ecs.Add(em, eID, physicscomponents.NewCircleCollider(12)) },
+		"capsule": func(eID uint64) { ecs.Add(em, eID, physicscomponents.NewCapsuleCollider(6, 40)) },
+		"polygon": func(eID uint64) {
+			ecs.Add(em, eID, physicscomponents.NewPolygonCollider([]raylib.Vector2{{X: 5, Y: 0}, {X: 30, Y: 10}, {X: 10, Y: 25}}))
+		},
+	}
+
+	for name, addCollider := range colliders {
+		for _, rotation := range []float32{0, 30, 90, 135, 200, 315} {
+			eID := em.CreateEntity().ID
+			ecs.Add(em, eID, &components.Transform2D{Position: raylib.NewVector2(100, -50), Rotation: rotation, Scale: raylib.NewVector2(1, 1)})
+			addCollider(eID)
+
+			shape, _, _ := getColliderShape(em, eID)
+			want := shape.Bounds()
+			got, boundsExist := getColliderBounds(em, eID)
+			if !boundsExist {
+				t.Fatalf("%s at %v degrees has no bounds", name, rotation)
+			}
+
+			const tolerance = 1e-3
+			if math.Abs(float64(got.Position.X-want.Position.X)) > tolerance || math.Abs(float64(got.Position.Y-want.Position.Y)) > tolerance ||
+				math.Abs(float64(got.Width-want.Width)) > tolerance || math.Abs(float64(got.Height-want.Height)) > tolerance {
+				t.Errorf("%s at %v degrees: bounds %v, want %v", name, rotation, got, want)
+			}
+		}
+	}
+}
@@ -2,16 +2,21 @@ package physicssystems
 
 import (
 	// STD
-	"math"
-	"sync"
+	"cmp"
+	"slices"
+
+	// EVENTS
+	"github.com/webbelito/Fenrir/pkg/events"
 
 	// ECS
-	"github.com/webbelito/Fenrir/pkg/components"
 	"github.com/webbelito/Fenrir/pkg/ecs"
 
 	// UTILS
 	"github.com/webbelito/Fenrir/pkg/utils"
 
+	// INTERFACES
+	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
+
 	// PHYSICS
 	"github.com/webbelito/Fenrir/pkg/physics"
 	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
@@ -23,29 +28,59 @@ import (
 // Constants for collision resolution
 const (
 	slop              = 0.01 // Allowable penetration for collision resolution
-	velocityThreshold = 0.1  // Minimum velocity for collision resolution before clamping
-	DampingFactor     = 0.98 // Damping factor for to reduce oscillation
-	PercentCorrection = 0.8  // Percentage of penetration to correct
+	PercentCorrection = 0.8  // Percentage of the remaining penetration to correct each position iteration
 )
 
+// contactPair identifies two colliding entities, the lower entity ID first
+type contactPair [2]uint64
+
+func newContactPair(eA uint64, eB uint64) contactPair {
+	if eA > eB {
+		return contactPair{eB, eA}
+	}
+
+	return contactPair{eA, eB}
+}
+
 type CollisionSystem struct {
-	quadTree             *physics.QuadTree
-	csMutex              sync.RWMutex
-	ShouldRenderQuadTree bool
-	ecsManager           *ecs.ECSManager
-	entitesManager       *ecs.EntitiesManager
-	componentsManager    *ecs.ComponentsManager
-	priority             int
+	queries            *PhysicsQueries
+	contacts           map[contactPair]events.Collision
+	impulses           map[contactPair]cachedImpulse
+	triggerOverlaps    map[contactPair]bool
+	VelocityIterations int
+	PositionIterations int
+
+	// Bodies slower than SleepVelocity and SleepAngularVelocity for TimeToSleep fall asleep with the bodies they touch
+	SleepVelocity        float32
+	SleepAngularVelocity float32
+	TimeToSleep          float32
+
+	ShouldRenderBroadPhase bool
+	ShouldRenderJoints     bool
+	ecsManager             *ecs.ECSManager
+	entitesManager         *ecs.EntitiesManager
+	componentsManager      *ecs.ComponentsManager
+	priority               int
 }
 
-func NewCollisionSystem(ecsM *ecs.ECSManager, b physics.Rectangle, c int32, mD int32, cD int32, p int) *CollisionSystem {
+// NewCollisionSystem initializes and returns a new CollisionSystem, the bounds of the colliders are grown by margin in the broad phase
+func NewCollisionSystem(ecsM *ecs.ECSManager, margin float32, p int) *CollisionSystem {
 	return &CollisionSystem{
-		quadTree:             physics.NewQuadTree(b, c, mD, cD),
-		ShouldRenderQuadTree: false,
-		ecsManager:           ecsM,
-		entitesManager:       ecsM.GetEntitiesManager(),
-		componentsManager:    ecsM.GetComponentsManager(),
-		priority:             p,
+		queries:                NewPhysicsQueries(ecsM, margin),
+		contacts:               make(map[contactPair]events.Collision),
+		impulses:               make(map[contactPair]cachedImpulse),
+		VelocityIterations:     DefaultVelocityIterations,
+		PositionIterations:     DefaultPositionIterations,
+		SleepVelocity:          DefaultSleepVelocity,
+		SleepAngularVelocity:   DefaultSleepAngularVelocity,
+		TimeToSleep:            DefaultTimeToSleep,
+		triggerOverlaps:        make(map[contactPair]bool),
+		ShouldRenderBroadPhase: false,
+		ShouldRenderJoints:     false,
+		ecsManager:             ecsM,
+		entitesManager:         ecsM.GetEntitiesManager(),
+		componentsManager:      ecsM.GetComponentsManager(),
+		priority:               p,
 	}
 }
 
@@ -56,282 +91,280 @@ func (cs *CollisionSystem) Update(dt float64) {
 		return
 	}
 
-	// Define the boundry of the QuadTree based on screen size or game world
-	// TODO: Implement a game world size for this
-
-	screenWidth := float32(raylib.GetScreenWidth())
-	screenHeight := float32(raylib.GetScreenHeight())
+	// Update the broad phase with the colliders that moved, the queries of other systems use the same tree
+	cs.queries.Sync()
 
-	boundry := physics.Rectangle{
-		Position: raylib.NewVector2(0, 0),
-		Width:    screenWidth,
-		Height:   screenHeight,
-	}
+	// Contacts and trigger overlaps found during this step
+	contacts := make(map[contactPair]events.Collision)
+	triggerContacts := make(map[contactPair]events.Collision)
 
-	// Initialize or reset the QuadTree
-	cs.csMutex.Lock()
-	cs.quadTree.Clear()
-	cs.quadTree = physics.NewQuadTree(boundry, cs.quadTree.Capacity, cs.quadTree.MaxDepth, cs.quadTree.CurrentDepth)
-	cs.csMutex.Unlock()
-
-	// Retrieve entities with the required components
-	entities := cs.componentsManager.GetEntitiesWithComponents([]ecs.ComponentType{
-		ecs.Transform2DComponent,
-		ecs.BoxColliderComponent,
-	})
+	// Check the shapes of every pair of colliders whose bounds overlap and whose layers collide
+	for _, pair := range cs.getCandidatePairs() {
 
-	// Insert entities into the QuadTree
-	for _, entity := range entities {
-		transformComp, transCompExists := cs.componentsManager.GetComponent(entity, ecs.Transform2DComponent)
-		if !transCompExists {
+		// Resting bodies haven't moved, keep their contact from the previous step instead of testing it again
+		if !cs.isAwake(pair[0]) && !cs.isAwake(pair[1]) && !cs.isTrigger(pair[0]) && !cs.isTrigger(pair[1]) {
+			if collision, wasTouching := cs.contacts[pair]; wasTouching {
+				contacts[pair] = collision
+			}
 			continue
 		}
 
-		transform, transExists := transformComp.(*components.Transform2D)
-
-		if !transExists {
+		collision, colliding := cs.detectCollision(pair[0], pair[1])
+		if !colliding {
 			continue
 		}
 
-		// Insert entity into the QuadTree based on its position
-		cs.csMutex.Lock()
-		cs.quadTree.Insert(entity, transform.Position)
-		cs.csMutex.Unlock()
-
+		if cs.isTrigger(pair[0]) || cs.isTrigger(pair[1]) {
+			triggerContacts[pair] = collision
+		} else {
+			contacts[pair] = collision
+		}
 	}
 
-	// Perform collision checks
-	for _, eID := range entities {
+	// Wake the islands touched by awake bodies, then resolve the joints and the contacts between bodies together
+	islands := cs.buildIslands(contacts)
+	cs.wakeIslands(islands, contacts)
+	cs.solveConstraints(contacts, dt)
 
-		// Get Transform2D component
-		transformComp, transCompExists := cs.componentsManager.GetComponent(eID, ecs.Transform2DComponent)
-		if !transCompExists {
-			continue
-		}
+	// Put the islands that came to rest to sleep
+	cs.updateSleep(islands, dt)
 
-		// Get the reference to the Transform2D component
-		transform, transExists := transformComp.(*components.Transform2D)
-		if !transExists {
-			continue
-		}
+	// Let the other systems know which entities touched
+	cs.publishContacts(contacts)
+	cs.publishTriggers(triggerContacts)
+}
 
-		// Get the BoxCollider component
-		colliderComp, colliderCompExists := cs.componentsManager.GetComponent(eID, ecs.BoxColliderComponent)
-		if !colliderCompExists {
-			continue
-		}
+// Render draws the debug views turned on with ToggleBroadPhaseRender and ToggleJointsRender
+func (cs *CollisionSystem) Render() {
+	if cs.ShouldRenderJoints {
+		cs.drawJoints()
+	}
 
-		// Get the reference to the BoxCollider component
-		collider, colliderExists := colliderComp.(*physicscomponents.BoxCollider)
-		if !colliderExists {
-			continue
-		}
+	if !cs.ShouldRenderBroadPhase {
+		return
+	}
+
+	cs.queries.queryMutex.RLock()
+	defer cs.queries.queryMutex.RUnlock()
 
-		// Define the range for querying the potential colliders
-		rangeRect := physics.Rectangle{
-			Position: raylib.NewVector2(
-				transform.Position.X-collider.Size.X,
-				transform.Position.Y-collider.Size.Y,
-			),
-			Width:  collider.Size.X * 2,
-			Height: collider.Size.Y * 2,
+	// Traverse the DynamicTree and render the bounds of the colliders and of the nodes grouping them
+	cs.queries.tree.Walk(func(bounds physics.Rectangle, depth int32, isLeaf bool) {
+		color := raylib.DarkGreen
+		if isLeaf {
+			color = raylib.Green
 		}
 
-		// Query the QuadTree for potential colliders
-		found := []uint64{}
-		cs.csMutex.RLock()
-		cs.quadTree.Query(rangeRect, &found)
-		cs.csMutex.RUnlock()
+		raylib.DrawRectangleLines(int32(bounds.Position.X), int32(bounds.Position.Y), int32(bounds.Width), int32(bounds.Height), color)
+	})
+}
 
-		// Check for actiual collisions with the found entities
-		for _, otherID := range found {
-			if otherID == eID {
-				continue // Ignore self
+// drawJoints draws a line between the anchors of every joint, and the anchors themselves
+func (cs *CollisionSystem) drawJoints() {
+	for eID, joints := range ecs.Query1[physicscomponents.Joints](cs.ecsManager) {
+		for i := range joints.Entries {
+			anchor, connectedAnchor, anchorsExist := cs.ecsManager.GetJointAnchors(eID, &joints.Entries[i])
+			if !anchorsExist {
+				continue
 			}
 
-			// Check for Box to Box collision
-			cs.handleBoxToBoxCollision(eID, otherID)
+			raylib.DrawLineV(anchor, connectedAnchor, raylib.Yellow)
+			raylib.DrawCircleV(anchor, 3, raylib.Orange)
+			raylib.DrawCircleV(connectedAnchor, 3, raylib.Orange)
 		}
 	}
-
-	// TODO: Remove the handleInput to the Input Manager
-	// Handle input to toggle the QuadTree rendering
-	if raylib.IsKeyPressed(raylib.KeyQ) {
-		cs.ToggleQuadTreeRender()
-	}
-
-	// TODO: Handle the rendering to the Render System
-	if cs.ShouldRenderQuadTree {
-		cs.Render()
-	}
 }
 
-func (cs *CollisionSystem) Render() {
-	if !cs.ShouldRenderQuadTree {
-		return
-	}
-
-	cs.csMutex.RLock()
-	defer cs.csMutex.RUnlock()
-
-	// Traverse the QuadTree and render the nodes
-	cs.drawQuadTreeNode(cs.quadTree, cs.quadTree.CurrentDepth, raylib.Green)
+// GetPhysicsQueries returns the queries answered from the colliders indexed by the CollisionSystem
+func (cs *CollisionSystem) GetPhysicsQueries() systeminterfaces.PhysicsQueriesInterface {
+	return cs.queries
 }
 
-func (cs *CollisionSystem) drawQuadTreeNode(qt *physics.QuadTree, cD int32, bC raylib.Color) {
-
-	if qt == nil {
-		return
-	}
-
-	// Draw the boundry of the QuadTree node
-	raylib.DrawRectangleLines(
-		int32(qt.Boundry.Position.X),
-		int32(qt.Boundry.Position.Y),
-		int32(qt.Boundry.Width),
-		int32(qt.Boundry.Height),
-		bC,
-	)
-
-	// Recursively draw the children
-	if qt.Divided {
-		cs.drawQuadTreeNode(qt.NW, cD+1, bC)
-		cs.drawQuadTreeNode(qt.NE, cD+1, bC)
-		cs.drawQuadTreeNode(qt.SW, cD+1, bC)
-		cs.drawQuadTreeNode(qt.SE, cD+1, bC)
-	}
+func (cs *CollisionSystem) ToggleBroadPhaseRender() {
+	cs.ShouldRenderBroadPhase = !cs.ShouldRenderBroadPhase
 }
 
-func (cs *CollisionSystem) ToggleQuadTreeRender() {
-	cs.ShouldRenderQuadTree = !cs.ShouldRenderQuadTree
+func (cs *CollisionSystem) ToggleJointsRender() {
+	cs.ShouldRenderJoints = !cs.ShouldRenderJoints
 }
 
-func (cs *CollisionSystem) handleBoxToBoxCollision(eA uint64, eB uint64) {
-
-	// Get the components for entity A
-	transformA, transformAExists := cs.componentsManager.GetComponent(eA, ecs.Transform2DComponent)
-	colliderA, colliderAExists := cs.componentsManager.GetComponent(eA, ecs.BoxColliderComponent)
-	rigidBodyA, rigidbodyAExists := cs.componentsManager.GetComponent(eA, ecs.RigidBodyComponent)
+// publishContacts queues the enter, stay and exit events of the contacts compared to the previous step
+// and updates the Contacts of the entities
+func (cs *CollisionSystem) publishContacts(contacts map[contactPair]events.Collision) {
+	eventsManager := cs.ecsManager.GetEventsManager()
 
-	if !transformAExists || !colliderAExists || !rigidbodyAExists {
-		return
+	// Queue the events in pair order so they are delivered in the same order every run
+	pairs := make([]contactPair, 0, len(contacts))
+	for pair := range contacts {
+		pairs = append(pairs, pair)
 	}
+	slices.SortFunc(pairs, compareContactPairs)
 
-	// Get the components for entity B
-	transformB, transformBExists := cs.componentsManager.GetComponent(eB, ecs.Transform2DComponent)
-	colliderB, colliderBExists := cs.componentsManager.GetComponent(eB, ecs.BoxColliderComponent)
-	rigidBodyB, rigidBodyBExists := cs.componentsManager.GetComponent(eB, ecs.RigidBodyComponent)
-
-	if !transformBExists || !colliderBExists || !rigidBodyBExists {
-		return
+	for _, pair := range pairs {
+		if _, wasTouching := cs.contacts[pair]; wasTouching {
+			eventsManager.Queue(events.CollisionStayEvent{Collision: contacts[pair]})
+		} else {
+			eventsManager.Queue(events.CollisionEnterEvent{Collision: contacts[pair]})
+		}
 	}
 
-	// Type assertions
-	tA, tAOk := transformA.(*components.Transform2D)
-	tb, tBOk := transformB.(*components.Transform2D)
-	cA, cAOk := colliderA.(*physicscomponents.BoxCollider)
-	cB, cBOk := colliderB.(*physicscomponents.BoxCollider)
-	rbA, rbAOk := rigidBodyA.(*physicscomponents.RigidBody)
-	rbB, rbBOk := rigidBodyB.(*physicscomponents.RigidBody)
+	exited := make([]contactPair, 0)
+	for pair := range cs.contacts {
+		if _, isTouching := contacts[pair]; !isTouching {
+			exited = append(exited, pair)
+		}
+	}
+	slices.SortFunc(exited, compareContactPairs)
 
-	if !tAOk || !tBOk || !cAOk || !cBOk || !rbAOk || !rbBOk {
-		return
+	for _, pair := range exited {
+		eventsManager.Queue(events.CollisionExitEvent{Collision: cs.contacts[pair]})
 	}
 
-	// Calculate the difference in positions
-	deltaX := tb.Position.X - tA.Position.X
-	deltaY := tb.Position.Y - tA.Position.Y
+	cs.contacts = contacts
+
+	// Collect the contacts of every entity, seen from the entity
+	entityContacts := make(map[uint64][]physicscomponents.Contact)
+	for _, pair := range pairs {
+		collision := contacts[pair]
+
+		entityContacts[collision.EntityA] = append(entityContacts[collision.EntityA], physicscomponents.Contact{
+			Other:       collision.EntityB,
+			Normal:      collision.Normal,
+			Penetration: collision.Penetration,
+			Impulse:     collision.Impulse,
+			Points:      collision.Points,
+		})
+		entityContacts[collision.EntityB] = append(entityContacts[collision.EntityB], physicscomponents.Contact{
+			Other:       collision.EntityA,
+			Normal:      raylib.Vector2Negate(collision.Normal),
+			Penetration: collision.Penetration,
+			Impulse:     collision.Impulse,
+			Points:      collision.Points,
+		})
+	}
 
-	// Calculate the combined half-widths and half-heights
-	halfWidthA := cA.Size.X / 2
-	halfHeightA := cA.Size.Y / 2
-	halfWidthB := cB.Size.X / 2
-	halfHeightB := cB.Size.Y / 2
+	// Clear the contacts of the entities that no longer touch anything
+	for eID, entityContactList := range ecs.Query1[physicscomponents.Contacts](cs.ecsManager) {
+		if _, isTouching := entityContacts[eID]; !isTouching {
+			entityContactList.Entries = entityContactList.Entries[:0]
+		}
+	}
 
-	// Calculate the overlap on both axes
-	overlapX := (halfWidthA + halfWidthB) - float32(math.Abs(float64(deltaX)))
-	overlapY := (halfHeightA + halfHeightB) - float32(math.Abs(float64(deltaY)))
+	for eID, entries := range entityContacts {
+		if entityContactList, contactsExists := ecs.Get[physicscomponents.Contacts](cs.ecsManager, eID); contactsExists {
+			entityContactList.Entries = entries
+			continue
+		}
 
-	// If there's no overlap, no collision
-	if overlapX <= 0 || overlapY <= 0 {
-		return
+		// Add the list at the next sync point, other systems may be iterating the entity's archetype
+		ecs.AddDeferred(cs.ecsManager.GetSystemCommandBuffer(cs), eID, &physicscomponents.Contacts{Entries: entries})
 	}
+}
 
-	// Determine the axis of least penetration
-	var normal raylib.Vector2
-	var penetration float32
+// publishTriggers queues the enter and exit events of the triggers compared to the previous step.
+// A pair of triggers sends an event for each of them.
+func (cs *CollisionSystem) publishTriggers(triggerContacts map[contactPair]events.Collision) {
+	eventsManager := cs.ecsManager.GetEventsManager()
 
-	// Determine the smallest overlap
-	if overlapX < overlapY {
+	// Pairs are ordered as trigger first, other entity second
+	overlaps := make(map[contactPair]bool, len(triggerContacts))
+	for pair := range triggerContacts {
+		if cs.isTrigger(pair[0]) {
+			overlaps[contactPair{pair[0], pair[1]}] = true
+		}
+		if cs.isTrigger(pair[1]) {
+			overlaps[contactPair{pair[1], pair[0]}] = true
+		}
+	}
 
-		// Resolve collision on the X axis
-		if deltaX > 0 {
-			normal = raylib.NewVector2(1, 0)
-		} else {
-			normal = raylib.NewVector2(-1, 0)
+	entered := make([]contactPair, 0)
+	for overlap := range overlaps {
+		if !cs.triggerOverlaps[overlap] {
+			entered = append(entered, overlap)
 		}
-		penetration = overlapX
-	} else {
+	}
+	slices.SortFunc(entered, compareContactPairs)
 
-		// Resolve collision on the Y axis
-		if deltaY > 0 {
-			normal = raylib.NewVector2(0, 1)
-		} else {
-			normal = raylib.NewVector2(0, -1)
+	exited := make([]contactPair, 0)
+	for overlap := range cs.triggerOverlaps {
+		if !overlaps[overlap] {
+			exited = append(exited, overlap)
 		}
-		penetration = overlapY
 	}
+	slices.SortFunc(exited, compareContactPairs)
 
-	// Relative velocity
-	relativeVelocity := raylib.Vector2Subtract(rbB.Velocity, rbA.Velocity)
-	velocityAlongNormal := raylib.Vector2DotProduct(relativeVelocity, normal)
+	for _, overlap := range entered {
+		eventsManager.Queue(events.TriggerEnterEvent{TriggerOverlap: events.TriggerOverlap{Trigger: overlap[0], Other: overlap[1]}})
+	}
 
-	// Do not resolve if velocities are separating
-	if velocityAlongNormal > 0 {
-		return
+	for _, overlap := range exited {
+		eventsManager.Queue(events.TriggerExitEvent{TriggerOverlap: events.TriggerOverlap{Trigger: overlap[0], Other: overlap[1]}})
 	}
 
-	// Calculate restitution (bounciness)
-	restitution := math.Min(float64(rbA.Restitution), float64(rbB.Restitution))
+	cs.triggerOverlaps = overlaps
+}
 
-	// Calculate impulse scalar
-	impulseScalar := -(1 + float32(restitution)) * velocityAlongNormal
-	impulseScalar /= (rbA.InvMass + rbB.InvMass)
+// isTrigger checks if the collider of an entity is a trigger
+func (cs *CollisionSystem) isTrigger(eID uint64) bool {
+	collider, colliderExists := getCollider(cs.ecsManager, eID)
+	return colliderExists && collider.IsTrigger
+}
 
-	// Calculate impulse vector
-	impulse := raylib.Vector2Scale(normal, impulseScalar)
+// compareContactPairs orders contact pairs by their entity IDs
+func compareContactPairs(a contactPair, b contactPair) int {
+	if a[0] != b[0] {
+		return cmp.Compare(a[0], b[0])
+	}
 
-	// Apply impulse to the entities
-	rbA.Velocity = raylib.Vector2Subtract(rbA.Velocity, raylib.Vector2Scale(impulse, rbA.InvMass))
-	rbB.Velocity = raylib.Vector2Add(rbB.Velocity, raylib.Vector2Scale(impulse, rbB.InvMass))
+	return cmp.Compare(a[1], b[1])
+}
 
-	// Positional correction to prevent sinking and jitter
-	correctionMagnitude := float32(float32(math.Max(float64(penetration-slop), float64(0.0)))/(rbA.InvMass+rbB.InvMass)) * PercentCorrection
-	correction := raylib.Vector2Scale(normal, correctionMagnitude)
+// getCandidatePairs returns the pairs of colliders whose bounds overlap in the broad phase,
+// without the pairs whose layers don't collide so they never reach the narrow phase
+func (cs *CollisionSystem) getCandidatePairs() []contactPair {
+	pairs := cs.queries.getPairs()
+	candidates := make([]contactPair, 0, len(pairs))
 
-	// Apply positional correction based on inverse mass, only if not static
-	if rbA.InvMass != 0 {
-		tA.Position = raylib.Vector2Subtract(tA.Position, raylib.Vector2Scale(correction, rbA.InvMass))
-	}
-	if rbB.InvMass != 0 {
-		tb.Position = raylib.Vector2Add(tb.Position, raylib.Vector2Scale(correction, rbB.InvMass))
+	for _, pair := range pairs {
+		cA, cAExists := getCollider(cs.ecsManager, pair[0])
+		cB, cBExists := getCollider(cs.ecsManager, pair[1])
+		if !cAExists || !cBExists || !cA.CanCollideWith(cB) {
+			continue
+		}
+
+		candidates = append(candidates, contactPair(pair))
 	}
 
-	// Apply daming to reduce residual velocity and	prevent	oscillation.
-	rbA.Velocity = raylib.Vector2Scale(rbA.Velocity, DampingFactor)
-	rbB.Velocity = raylib.Vector2Scale(rbB.Velocity, DampingFactor)
+	return candidates
+}
+
+// detectCollision tests the colliders of two entities against each other, whatever their shapes.
+// It returns the contact and true if the colliders overlap, the contact is resolved later by the solver.
+// The layers of the colliders are filtered before, see getCandidatePairs.
+func (cs *CollisionSystem) detectCollision(eA uint64, eB uint64) (events.Collision, bool) {
+
+	// Get the colliders in world space
+	shapeA, _, shapeAExists := getColliderShape(cs.ecsManager, eA)
+	shapeB, _, shapeBExists := getColliderShape(cs.ecsManager, eB)
 
-	// Clamp velocities below the threshold to prevent jitter
-	if raylib.Vector2Length(rbA.Velocity) < velocityThreshold {
-		rbA.Velocity = raylib.NewVector2(0, 0)
+	if !shapeAExists || !shapeBExists {
+		return events.Collision{}, false
 	}
-	if raylib.Vector2Length(rbB.Velocity) < velocityThreshold {
-		rbB.Velocity = raylib.NewVector2(0, 0)
+
+	// Find the contact manifold of the two shapes
+	manifold, colliding := physics.Collide(&shapeA, &shapeB)
+	if !colliding {
+		return events.Collision{}, false
 	}
+
+	return events.Collision{
+		EntityA:     eA,
+		EntityB:     eB,
+		Normal:      manifold.Normal,
+		Penetration: manifold.Penetration,
+		Points:      manifold.Points,
+	}, true
 }
 
 func (cs *CollisionSystem) GetPriority() int {
 	return cs.priority
 }
-
-// TODO: Implement a Destroy Entity
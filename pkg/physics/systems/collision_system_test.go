@@ -0,0 +1,197 @@
+package physicssystems
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/events"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// addBox creates an entity with a box collider of the given size and a rigid body at a position
+func addBox(em *ecs.ECSManager, position raylib.Vector2, size raylib.Vector2, rb *physicscomponents.RigidBody) uint64 {
+	eID := em.CreateEntity().ID
+	ecs.Add(em, eID, &components.Transform2D{Position: position, Scale: raylib.NewVector2(1, 1)})
+	ecs.Add(em, eID, physicscomponents.NewBoxCollider(size))
+	ecs.Add(em, eID, rb)
+
+	return eID
+}
+
+// recordContactEvents subscribes to the collision events and returns the list they are written to
+func recordContactEvents(em *ecs.ECSManager) *[]string {
+	log := []string{}
+	events.Subscribe(em.GetEventsManager(), func(e events.CollisionEnterEvent) {
+		log = append(log, fmt.Sprintf("enter %d %d", e.EntityA, e.EntityB))
+	})
+	events.Subscribe(em.GetEventsManager(), func(e events.CollisionStayEvent) {
+		log = append(log, fmt.Sprintf("stay %d %d", e.EntityA, e.EntityB))
+	})
+	events.Subscribe(em.GetEventsManager(), func(e events.CollisionExitEvent) {
+		log = append(log, fmt.Sprintf("exit %d %d", e.EntityA, e.EntityB))
+	})
+
+	return &log
+}
+
+// Enter, stay and exit are each sent once per step in that order, and a contact with a destroyed entity exits once
+func TestContactEventsFollowTheContact(t *testing.T) {
+	em := ecs.NewECSManager()
+	em.AddLogicSystem(NewCollisionSystem(em, 0, 1), 1)
+	log := recordContactEvents(em)
+
+	ground := addBox(em, raylib.NewVector2(0, 0), raylib.NewVector2(100, 10), physicscomponents.NewRigidBody(0, 0, 0, false, true))
+	box := addBox(em, raylib.NewVector2(0, -100), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	// The box is placed every step, resting 1 pixel into the ground or away from it
+	touching := raylib.NewVector2(0, -9)
+	apart := raylib.NewVector2(0, -100)
+
+	runStep := func() []string {
+		*log = (*log)[:0]
+		em.StepLogicSystems(1.0 / 60.0)
+		em.GetEventsManager().Flush()
+
+		return slices.Clone(*log)
+	}
+	step := func(position raylib.Vector2) []string {
+		transform, _ := ecs.Get[components.Transform2D](em, box)
+		transform.Position = position
+
+		return runStep()
+	}
+
+	enter, stay, exit := fmt.Sprintf("enter %d %d", ground, box), fmt.Sprintf("stay %d %d", ground, box), fmt.Sprintf("exit %d %d", ground, box)
+
+	for i, test := range []struct {
+		position raylib.Vector2
+		want     []string
+	}{
+		{apart, []string{}},
+		{touching, []string{enter}},
+		{touching, []string{stay}},
+		{touching, []string{stay}},
+		{apart, []string{exit}},
+		{apart, []string{}},
+		{touching, []string{enter}},
+	} {
+		if got := step(test.position); !slices.Equal(got, test.want) {
+			t.Fatalf("step %d: events = %v, want %v", i, got, test.want)
+		}
+	}
+
+	// Destroying the box while it touches the ground ends the contact the next step, and only once
+	em.DestroyEntity(box)
+
+	if got := runStep(); !slices.Equal(got, []string{exit}) {
+		t.Fatalf("events after destroying the box = %v, want %v", got, []string{exit})
+	}
+	if got := runStep(); len(got) != 0 {
+		t.Fatalf("events the step after = %v, want none", got)
+	}
+}
+
+// Entering and leaving a trigger sends one enter and one exit event, a pair of triggers sends them for each trigger,
+// and destroying an entity inside a trigger sends its exit event
+func TestTriggerEventsFollowTheOverlap(t *testing.T) {
+	em := ecs.NewECSManager()
+	em.AddLogicSystem(NewCollisionSystem(em, 0, 1), 1)
+
+	log := []string{}
+	events.Subscribe(em.GetEventsManager(), func(e events.TriggerEnterEvent) {
+		log = append(log, fmt.Sprintf("enter %d %d", e.Trigger, e.Other))
+	})
+	events.Subscribe(em.GetEventsManager(), func(e events.TriggerExitEvent) {
+		log = append(log, fmt.Sprintf("exit %d %d", e.Trigger, e.Other))
+	})
+
+	addTrigger := func(position raylib.Vector2) uint64 {
+		eID := em.CreateEntity().ID
+		collider := physicscomponents.NewBoxCollider(raylib.NewVector2(20, 20))
+		collider.IsTrigger = true
+		ecs.Add(em, eID, &components.Transform2D{Position: position, Scale: raylib.NewVector2(1, 1)})
+		ecs.Add(em, eID, collider)
+		return eID
+	}
+	trigger := addTrigger(raylib.NewVector2(0, 0))
+	otherTrigger := addTrigger(raylib.NewVector2(500, 0))
+	box := addBox(em, raylib.NewVector2(-100, 0), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	step := func(eID uint64, position raylib.Vector2) []string {
+		transform, _ := ecs.Get[components.Transform2D](em, eID)
+		transform.Position = position
+
+		log = log[:0]
+		em.StepLogicSystems(1.0 / 60.0)
+		em.GetEventsManager().Flush()
+
+		return slices.Clone(log)
+	}
+
+	for i, test := range []struct {
+		eID      uint64
+		position raylib.Vector2
+		want     []string
+	}{
+		{box, raylib.NewVector2(0, 0), []string{fmt.Sprintf("enter %d %d", trigger, box)}},
+		{box, raylib.NewVector2(5, 0), []string{}},
+		{box, raylib.NewVector2(-100, 0), []string{fmt.Sprintf("exit %d %d", trigger, box)}},
+		{otherTrigger, raylib.NewVector2(10, 0), []string{fmt.Sprintf("enter %d %d", trigger, otherTrigger), fmt.Sprintf("enter %d %d", otherTrigger, trigger)}},
+		{box, raylib.NewVector2(5, 0), []string{fmt.Sprintf("enter %d %d", trigger, box), fmt.Sprintf("enter %d %d", otherTrigger, box)}},
+	} {
+		if got := step(test.eID, test.position); !slices.Equal(got, test.want) {
+			t.Fatalf("step %d: events = %v, want %v", i, got, test.want)
+		}
+	}
+
+	// Overlapping a trigger is not a contact
+	if contacts, _ := ecs.Get[physicscomponents.Contacts](em, box); contacts != nil && len(contacts.Entries) != 0 {
+		t.Fatalf("box contacts = %v, want none with triggers", contacts.Entries)
+	}
+
+	em.DestroyEntity(box)
+	want := []string{fmt.Sprintf("exit %d %d", trigger, box), fmt.Sprintf("exit %d %d", otherTrigger, box)}
+	if got := step(otherTrigger, raylib.NewVector2(10, 0)); !slices.Equal(got, want) {
+		t.Fatalf("events after destroying the box = %v, want %v", got, want)
+	}
+}
+
+// Overlapping colliders whose layers don't collide are dropped before the narrow phase and never touch
+func TestFilteredPairsSkipNarrowPhase(t *testing.T) {
+	em := ecs.NewECSManager()
+	cs := NewCollisionSystem(em, 0, 1)
+	em.AddLogicSystem(cs, 1)
+	log := recordContactEvents(em)
+
+	ground := addBox(em, raylib.NewVector2(0, 0), raylib.NewVector2(100, 10), physicscomponents.NewRigidBody(0, 0, 0, false, true))
+	ghost := addBox(em, raylib.NewVector2(0, -9), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+	box := addBox(em, raylib.NewVector2(40, -9), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	// The ghost is on its own layer and only collides with it
+	ghostCollider, _ := ecs.Get[physicscomponents.BoxCollider](em, ghost)
+	ghostCollider.Layer = 1 << 5
+	ghostCollider.Mask = 1 << 5
+
+	cs.queries.Sync()
+	if pairs := cs.queries.getPairs(); len(pairs) != 2 {
+		t.Fatalf("broad phase pairs = %v, want both boxes over the ground", pairs)
+	}
+	if candidates := cs.getCandidatePairs(); !slices.Equal(candidates, []contactPair{newContactPair(ground, box)}) {
+		t.Fatalf("candidate pairs = %v, want only the box and the ground", candidates)
+	}
+
+	em.StepLogicSystems(1.0 / 60.0)
+	em.GetEventsManager().Flush()
+
+	if want := []string{fmt.Sprintf("enter %d %d", ground, box)}; !slices.Equal(*log, want) {
+		t.Fatalf("events = %v, want %v", *log, want)
+	}
+	if transform, _ := ecs.Get[components.Transform2D](em, ghost); transform.Position != raylib.NewVector2(0, -9) {
+		t.Fatalf("ghost moved to %v, want it left inside the ground", transform.Position)
+	}
+}
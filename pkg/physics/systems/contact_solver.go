@@ -0,0 +1,394 @@
+package physicssystems
+
+import (
+	// STD
+	"math"
+	"slices"
+
+	// EVENTS
+	"github.com/webbelito/Fenrir/pkg/events"
+
+	// ECS
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+
+	// PHYSICS
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	// RAYLIB
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Constants for the contact solver
+const (
+	DefaultVelocityIterations = 8    // Default number of passes over the joints and contacts solving velocities
+	DefaultPositionIterations = 3    // Default number of passes over the joints and contacts correcting positions
+	restitutionThreshold      = 30.0 // Approach speed in pixels per second below which contacts don't bounce, so resting bodies settle
+	warmStartAngle            = 0.95 // Minimum cosine between the old and new normal of a contact to reuse its impulses
+)
+
+// solverBody is one side of a contact. Colliders without a RigidBody, static and kinematic bodies
+// have an inverse mass and inertia of 0 and are never moved by the solver.
+type solverBody struct {
+	rigidBody  *physicscomponents.RigidBody
+	transform  *components.Transform2D
+	invMass    float32
+	invInertia float32
+}
+
+func (sb *solverBody) velocity() raylib.Vector2 {
+	if sb.rigidBody == nil {
+		return raylib.Vector2{}
+	}
+
+	return sb.rigidBody.Velocity
+}
+
+// angularVelocity returns the angular velocity of the body in radians per second
+func (sb *solverBody) angularVelocity() float32 {
+	if sb.rigidBody == nil {
+		return 0
+	}
+
+	return sb.rigidBody.AngularVelocity * raylib.Deg2rad
+}
+
+// velocityAt returns the velocity of the point of the body at an offset from its center
+func (sb *solverBody) velocityAt(offset raylib.Vector2) raylib.Vector2 {
+	return raylib.Vector2Add(sb.velocity(), raylib.Vector2Cross(sb.angularVelocity(), offset))
+}
+
+// center returns the position of the body, the world is centered on the origin
+func (sb *solverBody) center() raylib.Vector2 {
+	if sb.transform == nil {
+		return raylib.Vector2{}
+	}
+
+	return sb.transform.Position
+}
+
+// applyImpulse applies an impulse at an offset from the center of the body, turning it if the impulse is off center
+func (sb *solverBody) applyImpulse(impulse raylib.Vector2, offset raylib.Vector2) {
+	if sb.invMass != 0 {
+		sb.rigidBody.Velocity = raylib.Vector2Add(sb.rigidBody.Velocity, raylib.Vector2Scale(impulse, sb.invMass))
+	}
+
+	if sb.invInertia != 0 {
+		sb.rigidBody.AngularVelocity += raylib.Vector2CrossProduct(offset, impulse) * sb.invInertia * raylib.Rad2deg
+	}
+}
+
+func (sb *solverBody) move(offset raylib.Vector2) {
+	if sb.invMass == 0 {
+		return
+	}
+
+	sb.transform.Position = raylib.Vector2Add(sb.transform.Position, raylib.Vector2Scale(offset, sb.invMass))
+}
+
+// turn rotates the body by an angle in radians scaled by its inverse inertia
+func (sb *solverBody) turn(angle float32) {
+	if sb.invInertia == 0 {
+		return
+	}
+
+	sb.transform.Rotation += angle * sb.invInertia * raylib.Rad2deg
+}
+
+// getEffectiveMass returns the mass two bodies resist an impulse along a direction with, at offsets from their centers
+func getEffectiveMass(bodyA solverBody, bodyB solverBody, offsetA raylib.Vector2, offsetB raylib.Vector2, direction raylib.Vector2) float32 {
+	crossA := raylib.Vector2CrossProduct(offsetA, direction)
+	crossB := raylib.Vector2CrossProduct(offsetB, direction)
+
+	invMass := bodyA.invMass + bodyB.invMass + bodyA.invInertia*crossA*crossA + bodyB.invInertia*crossB*crossB
+	if invMass == 0 {
+		return 0
+	}
+
+	return 1 / invMass
+}
+
+// contactConstraint keeps two bodies from moving into each other and applies friction along their contact
+type contactConstraint struct {
+	pair         contactPair
+	bodyA, bodyB solverBody
+	normal       raylib.Vector2 // Points from A towards B
+	tangent      raylib.Vector2
+	penetration  float32
+	normalMass   float32 // Mass the centers resist being pushed apart with
+
+	staticFriction  float32
+	dynamicFriction float32
+
+	points []contactPoint
+
+	// Positions when the contact was found, to track the penetration while pushing the bodies apart
+	startA, startB raylib.Vector2
+}
+
+// contactPoint is a point where the bodies of a contact touch, impulses off the centers turn the bodies
+type contactPoint struct {
+	offsetA, offsetB raylib.Vector2 // From the centers of the bodies to the point
+	normalMass       float32
+	tangentMass      float32
+	restitutionBias  float32
+
+	// Impulses accumulated over the iterations
+	normalImpulse  float32
+	tangentImpulse float32
+}
+
+// cachedImpulse holds the impulses of the points of a contact for warm starting the next step
+type cachedImpulse struct {
+	normal          raylib.Vector2
+	normalImpulses  []float32
+	tangentImpulses []float32
+}
+
+// getSolverBody returns the body of an entity as seen by the solver
+func getSolverBody(em *ecs.ECSManager, eID uint64) (solverBody, bool) {
+	transform, transformExists := ecs.Get[components.Transform2D](em, eID)
+	if !transformExists {
+		return solverBody{}, false
+	}
+
+	body := solverBody{transform: transform}
+
+	if rb, rbExists := ecs.Get[physicscomponents.RigidBody](em, eID); rbExists {
+		body.rigidBody = rb
+
+		if !rb.IsStatic && !rb.IsKinematic && rb.Mass != 0 {
+			body.invMass = 1 / rb.Mass
+			body.invInertia = rb.InvInertia
+		}
+	}
+
+	return body, true
+}
+
+// solveConstraints resolves the joints and the contacts between bodies together with sequential impulses
+// and stores the normal impulse of each contact in its collision. Impulses of the previous step warm start
+// the joints and the contacts still touching. Contacts where neither body can move are removed,
+// contacts of sleeping bodies are kept as they are with their impulses.
+func (cs *CollisionSystem) solveConstraints(contacts map[contactPair]events.Collision, dt float64) {
+
+	// Solve the pairs in order so the results are the same every run
+	pairs := make([]contactPair, 0, len(contacts))
+	for pair := range contacts {
+		pairs = append(pairs, pair)
+	}
+	slices.SortFunc(pairs, compareContactPairs)
+
+	impulses := make(map[contactPair]cachedImpulse, len(pairs))
+	constraints := make([]*contactConstraint, 0, len(pairs))
+	for _, pair := range pairs {
+		if !cs.isAwake(pair[0]) && !cs.isAwake(pair[1]) && (cs.isSleeping(pair[0]) || cs.isSleeping(pair[1])) {
+			if cached, cachedExists := cs.impulses[pair]; cachedExists {
+				impulses[pair] = cached
+			}
+			continue
+		}
+
+		constraint, constraintExists := cs.newContactConstraint(pair, contacts[pair])
+		if !constraintExists {
+			delete(contacts, pair)
+			continue
+		}
+
+		constraints = append(constraints, constraint)
+	}
+
+	joints := cs.newJointConstraints(dt)
+
+	// Apply the impulses of the previous step first, the iterations only correct them
+	for _, j := range joints {
+		j.warmStart()
+	}
+
+	for _, c := range constraints {
+		cached, cachedExists := cs.impulses[c.pair]
+		if !cachedExists || raylib.Vector2DotProduct(cached.normal, c.normal) < warmStartAngle || len(cached.normalImpulses) != len(c.points) {
+			continue
+		}
+
+		c.warmStart(cached)
+	}
+
+	// Joints are solved first so the contacts have the last word on what can't overlap
+	for range max(cs.VelocityIterations, 1) {
+		for _, j := range joints {
+			j.solveVelocity()
+		}
+		for _, c := range constraints {
+			c.solveVelocity()
+		}
+	}
+
+	for range cs.PositionIterations {
+		for _, j := range joints {
+			j.solvePosition()
+		}
+		for _, c := range constraints {
+			c.solvePosition()
+		}
+	}
+
+	// Report the impulses and keep them for the next step
+	for _, c := range constraints {
+		cached := cachedImpulse{
+			normal:          c.normal,
+			normalImpulses:  make([]float32, len(c.points)),
+			tangentImpulses: make([]float32, len(c.points)),
+		}
+
+		collision := contacts[c.pair]
+		collision.Impulse = 0
+		for i, point := range c.points {
+			collision.Impulse += point.normalImpulse
+			cached.normalImpulses[i] = point.normalImpulse
+			cached.tangentImpulses[i] = point.tangentImpulse
+		}
+		contacts[c.pair] = collision
+
+		impulses[c.pair] = cached
+	}
+
+	cs.impulses = impulses
+}
+
+// newContactConstraint prepares the constraint of a contact, it returns false if neither body can move
+func (cs *CollisionSystem) newContactConstraint(pair contactPair, collision events.Collision) (*contactConstraint, bool) {
+	bodyA, bodyAExists := getSolverBody(cs.ecsManager, collision.EntityA)
+	bodyB, bodyBExists := getSolverBody(cs.ecsManager, collision.EntityB)
+
+	if !bodyAExists || !bodyBExists || bodyA.invMass+bodyB.invMass == 0 {
+		return nil, false
+	}
+
+	c := &contactConstraint{
+		pair:        pair,
+		bodyA:       bodyA,
+		bodyB:       bodyB,
+		normal:      collision.Normal,
+		tangent:     raylib.NewVector2(-collision.Normal.Y, collision.Normal.X),
+		penetration: collision.Penetration,
+		normalMass:  1 / (bodyA.invMass + bodyB.invMass),
+		startA:      bodyA.transform.Position,
+		startB:      bodyB.transform.Position,
+	}
+
+	// Combine the materials of both bodies, colliders without a RigidBody use the defaults
+	restitutionA, staticFrictionA, dynamicFrictionA := getMaterial(bodyA.rigidBody)
+	restitutionB, staticFrictionB, dynamicFrictionB := getMaterial(bodyB.rigidBody)
+
+	c.staticFriction = float32(math.Sqrt(float64(staticFrictionA * staticFrictionB)))
+	c.dynamicFriction = float32(math.Sqrt(float64(dynamicFrictionA * dynamicFrictionB)))
+	restitution := min(restitutionA, restitutionB)
+
+	// Contacts without points touch halfway between the centers
+	points := collision.Points
+	if len(points) == 0 {
+		points = []raylib.Vector2{raylib.Vector2Lerp(bodyA.center(), bodyB.center(), 0.5)}
+	}
+
+	c.points = make([]contactPoint, len(points))
+	for i, point := range points {
+		cp := contactPoint{
+			offsetA: raylib.Vector2Subtract(point, bodyA.center()),
+			offsetB: raylib.Vector2Subtract(point, bodyB.center()),
+		}
+		cp.normalMass = getEffectiveMass(bodyA, bodyB, cp.offsetA, cp.offsetB, c.normal)
+		cp.tangentMass = getEffectiveMass(bodyA, bodyB, cp.offsetA, cp.offsetB, c.tangent)
+
+		// Only bounce when approaching fast enough, slow contacts come to rest instead
+		approachSpeed := -raylib.Vector2DotProduct(c.getRelativeVelocity(&cp), c.normal)
+		if approachSpeed > restitutionThreshold {
+			cp.restitutionBias = restitution * approachSpeed
+		}
+
+		c.points[i] = cp
+	}
+
+	return c, true
+}
+
+// getMaterial returns the restitution and friction coefficients of a body.
+// Colliders without a RigidBody leave the bounce to the other body and use the default friction.
+func getMaterial(rb *physicscomponents.RigidBody) (float32, float32, float32) {
+	if rb == nil {
+		return 1, physicscomponents.DefaultStaticFriction, physicscomponents.DefaultDynamicFriction
+	}
+
+	return rb.Restitution, rb.StaticFriction, rb.DynamicFriction
+}
+
+// warmStart applies the impulses the points of the contact ended the previous step with
+func (c *contactConstraint) warmStart(cached cachedImpulse) {
+	for i := range c.points {
+		point := &c.points[i]
+		point.normalImpulse = cached.normalImpulses[i]
+		point.tangentImpulse = cached.tangentImpulses[i]
+
+		impulse := raylib.Vector2Add(raylib.Vector2Scale(c.normal, point.normalImpulse), raylib.Vector2Scale(c.tangent, point.tangentImpulse))
+		c.applyImpulse(point, impulse)
+	}
+}
+
+// solveVelocity applies the friction and normal impulses that stop the bodies from moving into each other, point by point
+func (c *contactConstraint) solveVelocity() {
+	for i := range c.points {
+		point := &c.points[i]
+
+		// Friction opposes the sliding along the contact, bodies stick until the static friction is overcome
+		relativeVelocity := c.getRelativeVelocity(point)
+		tangentImpulse := point.tangentImpulse - raylib.Vector2DotProduct(relativeVelocity, c.tangent)*point.tangentMass
+
+		if maxStatic := c.staticFriction * point.normalImpulse; float32(math.Abs(float64(tangentImpulse))) > maxStatic {
+			maxDynamic := c.dynamicFriction * point.normalImpulse
+			tangentImpulse = max(-maxDynamic, min(tangentImpulse, maxDynamic))
+		}
+
+		c.applyImpulse(point, raylib.Vector2Scale(c.tangent, tangentImpulse-point.tangentImpulse))
+		point.tangentImpulse = tangentImpulse
+
+		// The normal impulse only ever pushes the bodies apart
+		relativeVelocity = c.getRelativeVelocity(point)
+		normalImpulse := point.normalImpulse + (point.restitutionBias-raylib.Vector2DotProduct(relativeVelocity, c.normal))*point.normalMass
+		normalImpulse = max(normalImpulse, 0)
+
+		c.applyImpulse(point, raylib.Vector2Scale(c.normal, normalImpulse-point.normalImpulse))
+		point.normalImpulse = normalImpulse
+	}
+}
+
+// solvePosition pushes the bodies apart by part of their remaining penetration, by inverse mass
+func (c *contactConstraint) solvePosition() {
+
+	// Track how far the bodies moved apart since the contact was found
+	movedA := raylib.Vector2Subtract(c.bodyA.transform.Position, c.startA)
+	movedB := raylib.Vector2Subtract(c.bodyB.transform.Position, c.startB)
+	penetration := c.penetration - raylib.Vector2DotProduct(raylib.Vector2Subtract(movedB, movedA), c.normal)
+
+	correction := max(penetration-slop, 0) * PercentCorrection * c.normalMass
+	if correction == 0 {
+		return
+	}
+
+	c.bodyA.move(raylib.Vector2Scale(c.normal, -correction))
+	c.bodyB.move(raylib.Vector2Scale(c.normal, correction))
+}
+
+// getRelativeVelocity returns the velocity of B relative to A at a point of the contact
+func (c *contactConstraint) getRelativeVelocity(point *contactPoint) raylib.Vector2 {
+	return raylib.Vector2Subtract(c.bodyB.velocityAt(point.offsetB), c.bodyA.velocityAt(point.offsetA))
+}
+
+// applyImpulse applies an impulse to B at a point of the contact, and the opposite to A
+func (c *contactConstraint) applyImpulse(point *contactPoint, impulse raylib.Vector2) {
+	if impulse == (raylib.Vector2{}) {
+		return
+	}
+
+	c.bodyA.applyImpulse(raylib.Vector2Negate(impulse), point.offsetA)
+	c.bodyB.applyImpulse(impulse, point.offsetB)
+}
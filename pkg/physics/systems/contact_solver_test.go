@@ -0,0 +1,175 @@
+package physicssystems
+
+import (
+	"math"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+	"github.com/webbelito/Fenrir/pkg/platform"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// newPhysicsWorld returns an ECSManager stepping a RigidBodySystem with gravity and a CollisionSystem,
+// in a world large enough for the bodies never to reach its bounds
+func newPhysicsWorld(gravity raylib.Vector2) (*ecs.ECSManager, *CollisionSystem) {
+	em := ecs.NewECSManager()
+	em.SetPlatform(platform.NewNullPlatform(10_000, 10_000))
+	em.AddLogicSystem(NewRigidBodySystem(em, gravity, 1), 1)
+
+	cs := NewCollisionSystem(em, 0, 2)
+	em.AddLogicSystem(cs, 2)
+
+	return em, cs
+}
+
+// stepFor steps the logic systems for a number of seconds
+func stepFor(em *ecs.ECSManager, seconds float64) {
+	for range int(math.Round(seconds * 60)) {
+		em.StepLogicSystems(1.0 / 60.0)
+	}
+}
+
+// stackBoxes puts count boxes of 40 pixels on a static ground at y 500, each dropped 1 pixel above the one below
+func stackBoxes(em *ecs.ECSManager, count int) (uint64, []uint64) {
+	ground := addBox(em, raylib.NewVector2(400, 510), raylib.NewVector2(800, 20), physicscomponents.NewRigidBody(0, 0, 0, false, true))
+
+	boxes := make([]uint64, count)
+	for i := range boxes {
+		boxes[i] = addBox(em, raylib.NewVector2(400, 500-20-41*float32(i)), raylib.NewVector2(40, 40), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+	}
+
+	return ground, boxes
+}
+
+// A stack of boxes on static ground settles: the boxes stay on top of each other without sinking into each other,
+// and come to rest. Sleeping is turned off so the solver alone keeps the stack still.
+func TestBoxStackSettles(t *testing.T) {
+	for _, count := range []int{3, 5} {
+		em, cs := newPhysicsWorld(raylib.NewVector2(0, 980))
+		cs.TimeToSleep = math.MaxFloat32
+		_, boxes := stackBoxes(em, count)
+
+		stepFor(em, 3)
+
+		below := float32(500)
+		for i, eID := range boxes {
+			transform, _ := ecs.Get[components.Transform2D](em, eID)
+			rb, _ := ecs.Get[physicscomponents.RigidBody](em, eID)
+
+			bottom := transform.Position.Y + 20
+			if penetration := bottom - below; penetration > 1 || penetration < -1 {
+				t.Fatalf("%d boxes: box %d bottom at %v, %v pixels into what is below it", count, i, bottom, penetration)
+			}
+			if math.Abs(float64(transform.Position.X-400)) > 1 || math.Abs(float64(transform.Rotation)) > 1 && math.Abs(float64(transform.Rotation-360)) > 1 {
+				t.Fatalf("%d boxes: box %d at %v turned %v degrees, want it upright on the stack", count, i, transform.Position, transform.Rotation)
+			}
+			if raylib.Vector2Length(rb.Velocity) > 1 || math.Abs(float64(rb.AngularVelocity)) > 1 {
+				t.Fatalf("%d boxes: box %d still moves at %v and %v degrees per second", count, i, rb.Velocity, rb.AngularVelocity)
+			}
+
+			below = transform.Position.Y - 20
+		}
+	}
+}
+
+// The impulses of the previous step warm start the contacts, so they build up over the steps
+// until each contact carries the weight of every box above it, more than the iterations of one step find
+func TestWarmStartingCarriesTheStack(t *testing.T) {
+	em, cs := newPhysicsWorld(raylib.NewVector2(0, 980))
+	cs.TimeToSleep = math.MaxFloat32
+	ground, boxes := stackBoxes(em, 5)
+
+	stepFor(em, 3)
+
+	below := ground
+	for i, eID := range boxes {
+		collision, touching := cs.contacts[newContactPair(below, eID)]
+		if !touching {
+			t.Fatalf("box %d doesn't touch what is below it", i)
+		}
+
+		// Each box weighs m * g, the impulse over a step is the weight times the step
+		want := float32(len(boxes)-i) * 980 / 60
+		if math.Abs(float64(collision.Impulse-want)) > float64(want)*0.02 {
+			t.Fatalf("contact below box %d has an impulse of %v, want the weight of the %d boxes above it, %v", i, collision.Impulse, len(boxes)-i, want)
+		}
+
+		cached, cachedExists := cs.impulses[newContactPair(below, eID)]
+		if !cachedExists || len(cached.normalImpulses) != 2 {
+			t.Fatalf("contact below box %d cached %+v, want the impulses of both points for the next step", i, cached)
+		}
+
+		below = eID
+	}
+}
+
+// Friction holds a pushed box in place until the push overcomes the static friction,
+// the box then slides against the lower dynamic friction
+func TestFrictionSwitchesFromStaticToDynamic(t *testing.T) {
+	// With a weight of 980, static friction holds up to 490 and dynamic friction brakes with 294
+	for _, test := range []struct {
+		push      float32
+		wantSpeed float32
+	}{
+		{push: 400, wantSpeed: 0},
+		{push: 600, wantSpeed: 600 - 294},
+	} {
+		em, _ := newPhysicsWorld(raylib.NewVector2(0, 980))
+		addBox(em, raylib.NewVector2(400, 510), raylib.NewVector2(2_000, 20), physicscomponents.NewRigidBody(0, 0, 0, false, true))
+		box := addBox(em, raylib.NewVector2(400, 480), raylib.NewVector2(40, 40), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+		// Let the box settle on the ground, then push it for a second
+		stepFor(em, 0.5)
+
+		rb, _ := ecs.Get[physicscomponents.RigidBody](em, box)
+		for range 60 {
+			rb.ApplyForce(raylib.NewVector2(test.push, 0))
+			em.StepLogicSystems(1.0 / 60.0)
+		}
+
+		if math.Abs(float64(rb.Velocity.X-test.wantSpeed)) > float64(max(test.wantSpeed*0.05, 1)) {
+			t.Fatalf("box pushed with %v moves at %v, want %v", test.push, rb.Velocity.X, test.wantSpeed)
+		}
+	}
+}
+
+// A ball hitting a box off its center turns the box, the side of the center it hits decides the direction,
+// and a hit through the center doesn't turn it
+func TestOffCenterHitSpinsBox(t *testing.T) {
+	for _, test := range []struct {
+		offset float32
+		want   int
+	}{
+		// Positive rotations turn clockwise on screen, where y points down
+		{offset: -15, want: 1},
+		{offset: 15, want: -1},
+		{offset: 0, want: 0},
+	} {
+		em, _ := newPhysicsWorld(raylib.Vector2{})
+		box := addBox(em, raylib.NewVector2(400, 300), raylib.NewVector2(40, 40), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+		ball := em.CreateEntity().ID
+		ballBody := physicscomponents.NewRigidBody(1, 0, 0, false, false)
+		ballBody.Velocity = raylib.NewVector2(600, 0)
+		ecs.Add(em, ball, &components.Transform2D{Position: raylib.NewVector2(300, 300+test.offset), Scale: raylib.NewVector2(1, 1)})
+		ecs.Add(em, ball, physicscomponents.NewCircleCollider(5))
+		ecs.Add(em, ball, ballBody)
+
+		stepFor(em, 0.5)
+
+		rb, _ := ecs.Get[physicscomponents.RigidBody](em, box)
+		if rb.Velocity.X <= 0 {
+			t.Fatalf("hit at %v: box moves at %v, want it pushed by the ball", test.offset, rb.Velocity)
+		}
+
+		switch {
+		case test.want > 0 && rb.AngularVelocity < 10,
+			test.want < 0 && rb.AngularVelocity > -10,
+			test.want == 0 && math.Abs(float64(rb.AngularVelocity)) > 0.01:
+			t.Fatalf("hit at %v: box turns at %v degrees per second, want the sign of %d", test.offset, rb.AngularVelocity, test.want)
+		}
+	}
+}
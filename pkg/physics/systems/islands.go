@@ -0,0 +1,224 @@
+package physicssystems
+
+import (
+	// STD
+	"cmp"
+	"math"
+	"slices"
+
+	// EVENTS
+	"github.com/webbelito/Fenrir/pkg/events"
+
+	// ECS
+	"github.com/webbelito/Fenrir/pkg/ecs"
+
+	// PHYSICS
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	// RAYLIB
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Default thresholds for putting resting bodies to sleep
+const (
+	DefaultSleepVelocity        = 5.0 // Speed in pixels per second below which a body counts as resting
+	DefaultSleepAngularVelocity = 2.0 // Angular speed in degrees per second below which a body counts as resting
+	DefaultTimeToSleep          = 0.5 // Seconds every body of an island has to rest before the island sleeps
+)
+
+// island is a group of dynamic bodies touching each other or connected by joints, in entity order.
+// The bodies of an island sleep and wake together.
+type island []uint64
+
+// buildIslands groups every dynamic body with the bodies it touches or is connected to by a joint.
+// Static bodies, kinematic bodies and colliders without a RigidBody don't join islands, so bodies resting on the same floor stay apart.
+func (cs *CollisionSystem) buildIslands(contacts map[contactPair]events.Collision) []island {
+	parents := make(map[uint64]uint64)
+
+	for eID, rb := range ecs.Query1[physicscomponents.RigidBody](cs.ecsManager) {
+		if isDynamic(rb) {
+			parents[eID] = eID
+		}
+	}
+
+	for pair := range contacts {
+		unionIslands(parents, pair[0], pair[1])
+	}
+
+	for eID, joints := range ecs.Query1[physicscomponents.Joints](cs.ecsManager) {
+		for _, joint := range joints.Entries {
+			if joint.Connected != 0 {
+				unionIslands(parents, eID, joint.Connected)
+			}
+		}
+	}
+
+	// Collect the members of every island under its root
+	members := make(map[uint64]island)
+	for eID := range parents {
+		root := findIsland(parents, eID)
+		members[root] = append(members[root], eID)
+	}
+
+	islands := make([]island, 0, len(members))
+	for _, members := range members {
+		slices.Sort(members)
+		islands = append(islands, members)
+	}
+
+	// Order the islands by their first body so they are handled in the same order every run
+	slices.SortFunc(islands, func(a island, b island) int {
+		return cmp.Compare(a[0], b[0])
+	})
+
+	return islands
+}
+
+// findIsland returns the root of the island of a body, flattening the path to it on the way
+func findIsland(parents map[uint64]uint64, eID uint64) uint64 {
+	root := eID
+	for parents[root] != root {
+		root = parents[root]
+	}
+
+	for eID != root {
+		next := parents[eID]
+		parents[eID] = root
+		eID = next
+	}
+
+	return root
+}
+
+// unionIslands merges the islands of two bodies, bodies that don't join islands are ignored
+func unionIslands(parents map[uint64]uint64, eA uint64, eB uint64) {
+	if _, aExists := parents[eA]; !aExists {
+		return
+	}
+	if _, bExists := parents[eB]; !bExists {
+		return
+	}
+
+	rootA := findIsland(parents, eA)
+	rootB := findIsland(parents, eB)
+	if rootA == rootB {
+		return
+	}
+
+	// The lower entity becomes the root so the islands are the same every run
+	if rootA < rootB {
+		parents[rootB] = rootA
+	} else {
+		parents[rootA] = rootB
+	}
+}
+
+// wakeIslands wakes every island with an awake body, or touched by a moving kinematic body.
+// The bodies of contacts that ended since the previous step and of joints to destroyed entities are woken first,
+// the bodies they rested on are gone.
+func (cs *CollisionSystem) wakeIslands(islands []island, contacts map[contactPair]events.Collision) {
+	for pair := range cs.contacts {
+		if _, isTouching := contacts[pair]; !isTouching {
+			cs.wake(pair[0])
+			cs.wake(pair[1])
+		}
+	}
+
+	for eID, joints := range ecs.Query1[physicscomponents.Joints](cs.ecsManager) {
+		for _, joint := range joints.Entries {
+			if joint.Connected != 0 && !cs.ecsManager.IsAlive(joint.Connected) {
+				cs.wake(eID)
+			}
+		}
+	}
+
+	for pair := range contacts {
+		if cs.isMovingKinematic(pair[0]) {
+			cs.wake(pair[1])
+		}
+		if cs.isMovingKinematic(pair[1]) {
+			cs.wake(pair[0])
+		}
+	}
+
+	for _, members := range islands {
+		if !slices.ContainsFunc(members, cs.isAwake) {
+			continue
+		}
+
+		for _, eID := range members {
+			cs.wake(eID)
+		}
+	}
+}
+
+// updateSleep advances the sleep timers of the bodies of the awake islands and puts the islands
+// whose bodies all rested for TimeToSleep to sleep. Sleeping is turned off when TimeToSleep is 0.
+func (cs *CollisionSystem) updateSleep(islands []island, dt float64) {
+	for _, members := range islands {
+		restTime := float32(math.MaxFloat32)
+
+		for _, eID := range members {
+			rb, rbExists := ecs.Get[physicscomponents.RigidBody](cs.ecsManager, eID)
+			if !rbExists || rb.IsSleeping {
+				continue
+			}
+
+			if rb.NeverSleep || raylib.Vector2Length(rb.Velocity) > cs.SleepVelocity || float32(math.Abs(float64(rb.AngularVelocity))) > cs.SleepAngularVelocity {
+				rb.SleepTime = 0
+			} else {
+				rb.SleepTime += float32(dt)
+			}
+
+			restTime = min(restTime, rb.SleepTime)
+		}
+
+		if cs.TimeToSleep <= 0 || restTime < cs.TimeToSleep {
+			continue
+		}
+
+		for _, eID := range members {
+			if rb, rbExists := ecs.Get[physicscomponents.RigidBody](cs.ecsManager, eID); rbExists {
+				rb.Sleep()
+			}
+		}
+	}
+}
+
+// wake wakes the body of an entity if it is sleeping
+func (cs *CollisionSystem) wake(eID uint64) {
+	if rb, rbExists := ecs.Get[physicscomponents.RigidBody](cs.ecsManager, eID); rbExists && rb.IsSleeping {
+		rb.Wake()
+	}
+}
+
+// isAwake checks if an entity has a body that moves on its own: an awake dynamic body or a moving kinematic body
+func (cs *CollisionSystem) isAwake(eID uint64) bool {
+	rb, rbExists := ecs.Get[physicscomponents.RigidBody](cs.ecsManager, eID)
+	if !rbExists || rb.IsStatic {
+		return false
+	}
+
+	if rb.IsKinematic {
+		return cs.isMovingKinematic(eID)
+	}
+
+	return !rb.IsSleeping
+}
+
+// isSleeping checks if an entity has a sleeping body
+func (cs *CollisionSystem) isSleeping(eID uint64) bool {
+	rb, rbExists := ecs.Get[physicscomponents.RigidBody](cs.ecsManager, eID)
+	return rbExists && rb.IsSleeping
+}
+
+// isMovingKinematic checks if an entity has a kinematic body with a velocity
+func (cs *CollisionSystem) isMovingKinematic(eID uint64) bool {
+	rb, rbExists := ecs.Get[physicscomponents.RigidBody](cs.ecsManager, eID)
+	return rbExists && rb.IsKinematic && !rb.IsStatic && (rb.Velocity != raylib.Vector2{} || rb.AngularVelocity != 0)
+}
+
+// isDynamic checks if a body is moved by forces and contacts
+func isDynamic(rb *physicscomponents.RigidBody) bool {
+	return !rb.IsStatic && !rb.IsKinematic && rb.Mass != 0
+}
@@ -0,0 +1,152 @@
+package physicssystems
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/events"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// sleepingStack stacks count boxes and steps until all of them sleep
+func sleepingStack(t *testing.T, count int) (*ecs.ECSManager, *CollisionSystem, []uint64) {
+	t.Helper()
+
+	em, cs := newPhysicsWorld(raylib.NewVector2(0, 980))
+	_, boxes := stackBoxes(em, count)
+
+	stepFor(em, 3)
+
+	for _, eID := range boxes {
+		if rb, _ := ecs.Get[physicscomponents.RigidBody](em, eID); !rb.IsSleeping {
+			t.Fatalf("box %d is still awake after 3 seconds, moving at %v", eID, rb.Velocity)
+		}
+	}
+
+	return em, cs, boxes
+}
+
+// Dynamic bodies touching each other or connected by a joint share an island,
+// bodies resting on the same static body don't
+func TestBuildIslandsGroupsTouchingBodies(t *testing.T) {
+	em, cs := newPhysicsWorld(raylib.Vector2{})
+
+	newBody := func(isStatic bool) uint64 {
+		mass := float32(1)
+		if isStatic {
+			mass = 0
+		}
+		return addBox(em, raylib.NewVector2(400, 300), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(mass, 0, 0, false, isStatic))
+	}
+
+	ground := newBody(true)
+	a, b, c := newBody(false), newBody(false), newBody(false)
+	pendulum, hanging := newBody(false), newBody(false)
+	alone := newBody(false)
+
+	if err := em.AddJoint(hanging, physicscomponents.Joint{Type: physicscomponents.DistanceJoint, Connected: pendulum, Length: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	contacts := map[contactPair]events.Collision{
+		newContactPair(a, b):          {},
+		newContactPair(b, c):          {},
+		newContactPair(c, ground):     {},
+		newContactPair(alone, ground): {},
+	}
+
+	islands := cs.buildIslands(contacts)
+
+	want := []island{{a, b, c}, {pendulum, hanging}, {alone}}
+	if !slices.EqualFunc(islands, want, slices.Equal) {
+		t.Fatalf("islands %v, want %v", islands, want)
+	}
+}
+
+// A box resting on the ground sleeps once it has rested for TimeToSleep, not before.
+// A body that never sleeps keeps the bodies of its island awake.
+func TestIslandsSleepAfterTimeToSleep(t *testing.T) {
+	em, cs := newPhysicsWorld(raylib.NewVector2(0, 980))
+	addBox(em, raylib.NewVector2(400, 510), raylib.NewVector2(800, 20), physicscomponents.NewRigidBody(0, 0, 0, false, true))
+	box := addBox(em, raylib.NewVector2(400, 480), raylib.NewVector2(40, 40), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	steps := 0
+	for rb, _ := ecs.Get[physicscomponents.RigidBody](em, box); !rb.IsSleeping && steps < 120; rb, _ = ecs.Get[physicscomponents.RigidBody](em, box) {
+		em.StepLogicSystems(1.0 / 60.0)
+		steps++
+	}
+
+	// The box rests from the first step on
+	wantSteps := int(cs.TimeToSleep * 60)
+	if steps < wantSteps || steps > wantSteps+2 {
+		t.Fatalf("box sleeps after %d steps, want %d", steps, wantSteps)
+	}
+
+	em, _ = newPhysicsWorld(raylib.NewVector2(0, 980))
+	_, boxes := stackBoxes(em, 3)
+
+	restless, _ := ecs.Get[physicscomponents.RigidBody](em, boxes[2])
+	restless.NeverSleep = true
+
+	stepFor(em, 3)
+
+	for _, eID := range boxes {
+		if rb, _ := ecs.Get[physicscomponents.RigidBody](em, eID); rb.IsSleeping {
+			t.Fatalf("box %d sleeps under a box that never sleeps", eID)
+		}
+	}
+}
+
+// A box dropped on a sleeping stack wakes every box of the stack when it touches the top one
+func TestSleepingIslandWakesOnContact(t *testing.T) {
+	em, _, boxes := sleepingStack(t, 3)
+	log := recordContactEvents(em)
+
+	dropped := addBox(em, raylib.NewVector2(400, 200), raylib.NewVector2(40, 40), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	landed := func() bool {
+		return slices.Contains(*log, fmt.Sprintf("enter %d %d", boxes[2], dropped)) || slices.Contains(*log, fmt.Sprintf("enter %d %d", dropped, boxes[2]))
+	}
+
+	for steps := 0; !landed(); steps++ {
+		if steps == 120 {
+			t.Fatalf("dropped box never touched the stack, events %v", *log)
+		}
+		em.StepLogicSystems(1.0 / 60.0)
+		em.GetEventsManager().Flush()
+	}
+
+	for _, eID := range boxes {
+		if rb, _ := ecs.Get[physicscomponents.RigidBody](em, eID); rb.IsSleeping {
+			t.Fatalf("box %d still sleeps after a box landed on the stack", eID)
+		}
+	}
+}
+
+// A force on the bottom box of a sleeping stack wakes the box, and the step after, the rest of its island
+func TestSleepingIslandWakesOnForce(t *testing.T) {
+	em, _, boxes := sleepingStack(t, 3)
+
+	bottom, _ := ecs.Get[physicscomponents.RigidBody](em, boxes[0])
+	bottom.ApplyForce(raylib.NewVector2(1000, 0))
+
+	if bottom.IsSleeping {
+		t.Fatal("bottom box still sleeps after a force")
+	}
+
+	em.StepLogicSystems(1.0 / 60.0)
+
+	for _, eID := range boxes {
+		if rb, _ := ecs.Get[physicscomponents.RigidBody](em, eID); rb.IsSleeping {
+			t.Fatalf("box %d still sleeps after a force on the bottom box", eID)
+		}
+	}
+
+	if rb, _ := ecs.Get[physicscomponents.RigidBody](em, boxes[0]); rb.Velocity.X <= 0 {
+		t.Fatalf("bottom box moves at %v, want it pushed right", rb.Velocity)
+	}
+}
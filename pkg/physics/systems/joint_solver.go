@@ -0,0 +1,339 @@
+package physicssystems
+
+import (
+	// STD
+	"math"
+	"slices"
+
+	// ECS
+	"github.com/webbelito/Fenrir/pkg/ecs"
+
+	// PHYSICS
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	// RAYLIB
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// jointConstraint keeps the anchors of a joint where its type wants them.
+// Joints connected to the world, or to a body without a RigidBody, only move the entity of the joint.
+type jointConstraint struct {
+	joint        *physicscomponents.Joint
+	entity       uint64
+	bodyA, bodyB solverBody
+	ecsManager   *ecs.ECSManager
+
+	// Anchors relative to the centers of the bodies and the axis between them, see prepare
+	offsetA, offsetB raylib.Vector2
+	axis             raylib.Vector2
+	distance         float32
+	mass             float32 // Mass the anchors resist moving along the axis with
+
+	// Springs only, see prepareSpring
+	gamma float32
+	bias  float32
+}
+
+// newJointConstraints prepares the constraints of every joint whose bodies can move, in entity order.
+// Joints connected to an entity that was destroyed are removed.
+func (cs *CollisionSystem) newJointConstraints(dt float64) []*jointConstraint {
+	entities := []uint64{}
+	for eID, joints := range ecs.Query1[physicscomponents.Joints](cs.ecsManager) {
+		joints.Entries = slices.DeleteFunc(joints.Entries, func(joint physicscomponents.Joint) bool {
+			return joint.Connected != 0 && !cs.ecsManager.IsAlive(joint.Connected)
+		})
+
+		if len(joints.Entries) > 0 {
+			entities = append(entities, eID)
+		}
+	}
+	slices.Sort(entities)
+
+	constraints := []*jointConstraint{}
+	for _, eID := range entities {
+		joints, _ := ecs.Get[physicscomponents.Joints](cs.ecsManager, eID)
+
+		for i := range joints.Entries {
+			constraint, constraintExists := cs.newJointConstraint(eID, &joints.Entries[i], dt)
+			if !constraintExists {
+				continue
+			}
+
+			constraints = append(constraints, constraint)
+		}
+	}
+
+	return constraints
+}
+
+// newJointConstraint prepares the constraint of a joint, it returns false if neither body can move.
+// Joints between sleeping bodies keep their impulses for when the bodies wake.
+func (cs *CollisionSystem) newJointConstraint(eID uint64, joint *physicscomponents.Joint, dt float64) (*jointConstraint, bool) {
+	if cs.isSleeping(eID) && (joint.Connected == 0 || !cs.isAwake(joint.Connected)) {
+		return nil, false
+	}
+
+	bodyA, bodyAExists := getSolverBody(cs.ecsManager, eID)
+	if !bodyAExists {
+		return nil, false
+	}
+
+	// The world never moves
+	bodyB := solverBody{}
+	if joint.Connected != 0 {
+		connectedBody, connectedBodyExists := getSolverBody(cs.ecsManager, joint.Connected)
+		if !connectedBodyExists {
+			return nil, false
+		}
+		bodyB = connectedBody
+	}
+
+	if bodyA.invMass+bodyB.invMass == 0 {
+		joint.Impulse = raylib.Vector2{}
+		joint.AngularImpulse = 0
+		return nil, false
+	}
+
+	c := &jointConstraint{
+		joint:      joint,
+		entity:     eID,
+		bodyA:      bodyA,
+		bodyB:      bodyB,
+		ecsManager: cs.ecsManager,
+	}
+
+	if !c.prepare() {
+		return nil, false
+	}
+
+	if c.isSpring() {
+		c.prepareSpring(dt)
+	}
+
+	// A slack rope doesn't pull
+	if joint.Type == physicscomponents.RopeJoint && c.distance < joint.Length {
+		joint.Impulse = raylib.Vector2{}
+	}
+
+	return c, true
+}
+
+// prepare finds the anchors relative to the centers of the bodies, the axis between them and the mass along it
+func (c *jointConstraint) prepare() bool {
+	anchor, connectedAnchor, anchorsExist := c.ecsManager.GetJointAnchors(c.entity, c.joint)
+	if !anchorsExist {
+		return false
+	}
+
+	c.offsetA = raylib.Vector2Subtract(anchor, c.bodyA.center())
+	c.offsetB = raylib.Vector2Subtract(connectedAnchor, c.bodyB.center())
+
+	offset := raylib.Vector2Subtract(connectedAnchor, anchor)
+	c.distance = raylib.Vector2Length(offset)
+	c.axis = raylib.Vector2{}
+	if c.distance != 0 {
+		c.axis = raylib.Vector2Scale(offset, 1/c.distance)
+	}
+
+	c.mass = getEffectiveMass(c.bodyA, c.bodyB, c.offsetA, c.offsetB, c.axis)
+
+	return true
+}
+
+// prepareSpring turns the frequency and damping ratio of a spring into a soft constraint,
+// which stays stable for stiff springs where applying the spring force directly would not
+func (c *jointConstraint) prepareSpring(dt float64) {
+	if c.axis == (raylib.Vector2{}) || c.mass == 0 || dt <= 0 {
+		return
+	}
+
+	omega := 2 * math.Pi * float64(c.joint.Frequency)
+	stiffness := float64(c.mass) * omega * omega
+	damping := 2 * float64(c.mass) * float64(c.joint.DampingRatio) * omega
+
+	gamma := 1 / (dt * (damping + dt*stiffness))
+	c.gamma = float32(gamma)
+	c.bias = float32(float64(c.distance-c.joint.Length) * dt * stiffness * gamma)
+	c.mass = 1 / (1/c.mass + c.gamma)
+}
+
+// isSpring checks if the joint is a spring, a spring without a frequency is as rigid as a distance joint
+func (c *jointConstraint) isSpring() bool {
+	return c.joint.Type == physicscomponents.SpringJoint && c.joint.Frequency > 0
+}
+
+// isPin checks if the joint holds the anchors on top of each other
+func (c *jointConstraint) isPin() bool {
+	return c.joint.Type == physicscomponents.RevoluteJoint || c.joint.Type == physicscomponents.WeldJoint
+}
+
+// warmStart applies the impulses of the previous step.
+// Joints along an axis only keep the part along the new axis, the rest would push the bodies sideways
+// where the joint never takes it back and add energy every step the axis turns.
+func (c *jointConstraint) warmStart() {
+	if !c.isPin() {
+		c.joint.Impulse = raylib.Vector2Scale(c.axis, raylib.Vector2DotProduct(c.joint.Impulse, c.axis))
+	}
+
+	c.applyImpulse(c.joint.Impulse)
+	c.applyAngularImpulse(c.joint.AngularImpulse)
+}
+
+// solveVelocity applies the impulses that stop the anchors from moving the way the joint doesn't allow
+func (c *jointConstraint) solveVelocity() {
+
+	// Welded bodies turn together
+	if c.joint.Type == physicscomponents.WeldJoint {
+		if invInertia := c.bodyA.invInertia + c.bodyB.invInertia; invInertia != 0 {
+			impulse := -(c.bodyB.angularVelocity() - c.bodyA.angularVelocity()) / invInertia
+			c.applyAngularImpulse(impulse)
+			c.joint.AngularImpulse += impulse
+		}
+	}
+
+	relativeVelocity := raylib.Vector2Subtract(c.bodyB.velocityAt(c.offsetB), c.bodyA.velocityAt(c.offsetA))
+
+	// Pinned anchors move together
+	if c.isPin() {
+		impulse := c.solvePoint(raylib.Vector2Negate(relativeVelocity))
+		c.applyImpulse(impulse)
+		c.joint.Impulse = raylib.Vector2Add(c.joint.Impulse, impulse)
+		return
+	}
+
+	// The other anchors only resist moving along the axis between them
+	if c.axis == (raylib.Vector2{}) {
+		return
+	}
+
+	accumulated := raylib.Vector2DotProduct(c.joint.Impulse, c.axis)
+	speed := raylib.Vector2DotProduct(relativeVelocity, c.axis)
+
+	var impulse float32
+	switch {
+	case c.isSpring():
+		impulse = -c.mass * (speed + c.bias + c.gamma*accumulated)
+	case c.joint.Type == physicscomponents.RopeJoint:
+		if c.distance < c.joint.Length {
+			return
+		}
+
+		// A rope only pulls
+		impulse = min(accumulated-c.mass*speed, 0) - accumulated
+	default:
+		impulse = -c.mass * speed
+	}
+
+	c.applyImpulse(raylib.Vector2Scale(c.axis, impulse))
+	c.joint.Impulse = raylib.Vector2Scale(c.axis, accumulated+impulse)
+}
+
+// solvePosition moves and turns the bodies by part of the error left by the velocities, springs are left to stretch
+func (c *jointConstraint) solvePosition() {
+	if c.isSpring() || !c.prepare() {
+		return
+	}
+
+	// Turn welded bodies back to the rotation they were welded at
+	if c.joint.Type == physicscomponents.WeldJoint {
+		if invInertia := c.bodyA.invInertia + c.bodyB.invInertia; invInertia != 0 {
+			angle := (c.getRotation(c.bodyB) - c.getRotation(c.bodyA) - c.joint.ReferenceRotation) * raylib.Deg2rad
+			correction := -angle * PercentCorrection / invInertia
+			c.bodyA.turn(-correction)
+			c.bodyB.turn(correction)
+
+			if !c.prepare() {
+				return
+			}
+		}
+	}
+
+	if c.isPin() {
+		separation := c.distance
+		if separation <= slop {
+			return
+		}
+
+		correction := c.solvePoint(raylib.Vector2Scale(c.axis, -separation*PercentCorrection))
+		c.applyPositionImpulse(correction)
+		return
+	}
+
+	if c.axis == (raylib.Vector2{}) {
+		return
+	}
+
+	stretch := c.distance - c.joint.Length
+	if c.joint.Type == physicscomponents.RopeJoint {
+		stretch = max(stretch, 0)
+	}
+
+	if float32(math.Abs(float64(stretch))) <= slop {
+		return
+	}
+
+	c.applyPositionImpulse(raylib.Vector2Scale(c.axis, -stretch*PercentCorrection*c.mass))
+}
+
+// solvePoint returns the impulse that changes the velocity of the connected anchor relative to the anchor by a change,
+// solving the 2x2 mass the bodies resist it with
+func (c *jointConstraint) solvePoint(change raylib.Vector2) raylib.Vector2 {
+	invMass := c.bodyA.invMass + c.bodyB.invMass
+	iA, iB := c.bodyA.invInertia, c.bodyB.invInertia
+	rA, rB := c.offsetA, c.offsetB
+
+	k11 := invMass + iA*rA.Y*rA.Y + iB*rB.Y*rB.Y
+	k12 := -iA*rA.X*rA.Y - iB*rB.X*rB.Y
+	k22 := invMass + iA*rA.X*rA.X + iB*rB.X*rB.X
+
+	determinant := k11*k22 - k12*k12
+	if determinant == 0 {
+		return raylib.Vector2{}
+	}
+
+	return raylib.NewVector2(
+		(k22*change.X-k12*change.Y)/determinant,
+		(k11*change.Y-k12*change.X)/determinant,
+	)
+}
+
+// getRotation returns the rotation of a body, the world has none
+func (c *jointConstraint) getRotation(body solverBody) float32 {
+	if body.transform == nil {
+		return 0
+	}
+
+	return body.transform.Rotation
+}
+
+// applyImpulse applies an impulse to the connected body at its anchor, and the opposite to the entity of the joint
+func (c *jointConstraint) applyImpulse(impulse raylib.Vector2) {
+	if impulse == (raylib.Vector2{}) {
+		return
+	}
+
+	c.bodyA.applyImpulse(raylib.Vector2Negate(impulse), c.offsetA)
+	c.bodyB.applyImpulse(impulse, c.offsetB)
+}
+
+// applyAngularImpulse turns the connected body by an angular impulse, and the entity of the joint the other way
+func (c *jointConstraint) applyAngularImpulse(impulse float32) {
+	if impulse == 0 {
+		return
+	}
+
+	if c.bodyA.invInertia != 0 {
+		c.bodyA.rigidBody.AngularVelocity -= impulse * c.bodyA.invInertia * raylib.Rad2deg
+	}
+	if c.bodyB.invInertia != 0 {
+		c.bodyB.rigidBody.AngularVelocity += impulse * c.bodyB.invInertia * raylib.Rad2deg
+	}
+}
+
+// applyPositionImpulse moves the connected body by an impulse at its anchor, and the entity of the joint the other way
+func (c *jointConstraint) applyPositionImpulse(impulse raylib.Vector2) {
+	c.bodyA.move(raylib.Vector2Negate(impulse))
+	c.bodyA.turn(-raylib.Vector2CrossProduct(c.offsetA, impulse))
+	c.bodyB.move(impulse)
+	c.bodyB.turn(raylib.Vector2CrossProduct(c.offsetB, impulse))
+}
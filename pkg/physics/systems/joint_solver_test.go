@@ -0,0 +1,108 @@
+package physicssystems
+
+import (
+	"math"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// checkJointHolds steps the world for a number of seconds and fails as soon as the distance between the anchors
+// of the first joint of an entity differs from want by more than tolerance
+func checkJointHolds(t *testing.T, em *ecs.ECSManager, eID uint64, seconds float64, want float32, tolerance float32) {
+	t.Helper()
+
+	for step := range int(seconds * 60) {
+		em.StepLogicSystems(1.0 / 60.0)
+
+		joints, _ := ecs.Get[physicscomponents.Joints](em, eID)
+		anchor, connectedAnchor, anchorsExist := em.GetJointAnchors(eID, &joints.Entries[0])
+		if !anchorsExist {
+			t.Fatalf("step %d: the anchors of entity %d don't exist", step, eID)
+		}
+
+		if distance := raylib.Vector2Distance(anchor, connectedAnchor); math.Abs(float64(distance-want)) > float64(tolerance) {
+			t.Fatalf("step %d: anchors of entity %d are %v apart, want %v", step, eID, distance, want)
+		}
+	}
+}
+
+// A distance joint keeps a swinging pendulum and a body hanging from it at the length of their joints
+func TestDistanceJointKeepsLength(t *testing.T) {
+	em, _ := newPhysicsWorld(raylib.NewVector2(0, 980))
+
+	// The pendulum starts level with its pivot, the second body hangs below it
+	pendulum := addBox(em, raylib.NewVector2(500, 300), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+	hanging := addBox(em, raylib.NewVector2(500, 350), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	if err := em.AddJoint(pendulum, physicscomponents.Joint{Type: physicscomponents.DistanceJoint, ConnectedAnchor: raylib.NewVector2(400, 300), Length: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := em.AddJoint(hanging, physicscomponents.Joint{Type: physicscomponents.DistanceJoint, Connected: pendulum, Length: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkJointHolds(t, em, pendulum, 3, 100, 1)
+	checkJointHolds(t, em, hanging, 1, 50, 1)
+
+	// The pendulum swings instead of being held still
+	rb, _ := ecs.Get[physicscomponents.RigidBody](em, pendulum)
+	if raylib.Vector2Length(rb.Velocity) < 50 {
+		t.Fatalf("pendulum moves at %v, want it swinging", rb.Velocity)
+	}
+}
+
+// A revolute joint keeps the anchors on top of each other while the bodies turn around the pin
+func TestRevoluteJointPinsAnchors(t *testing.T) {
+	em, _ := newPhysicsWorld(raylib.NewVector2(0, 980))
+
+	// Two bars of 60 pixels hang off each other's ends, starting level
+	first := addBox(em, raylib.NewVector2(430, 300), raylib.NewVector2(60, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+	second := addBox(em, raylib.NewVector2(490, 300), raylib.NewVector2(60, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	if err := em.AddJoint(first, physicscomponents.Joint{Type: physicscomponents.RevoluteJoint, Anchor: raylib.NewVector2(-30, 0), ConnectedAnchor: raylib.NewVector2(400, 300)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := em.AddJoint(second, physicscomponents.Joint{Type: physicscomponents.RevoluteJoint, Connected: first, Anchor: raylib.NewVector2(-30, 0), ConnectedAnchor: raylib.NewVector2(30, 0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkJointHolds(t, em, first, 2, 0, 1)
+	checkJointHolds(t, em, second, 1, 0, 1)
+
+	// The bars fell around their pins instead of staying level
+	transform, _ := ecs.Get[components.Transform2D](em, first)
+	if transform.Rotation < 10 || transform.Rotation > 350 {
+		t.Fatalf("first bar turned to %v degrees, want it swinging around the pin", transform.Rotation)
+	}
+}
+
+// Joints connected to a destroyed entity are removed, the entity is then free
+func TestJointsToDestroyedEntitiesAreRemoved(t *testing.T) {
+	em, _ := newPhysicsWorld(raylib.NewVector2(0, 980))
+
+	anchored := addBox(em, raylib.NewVector2(400, 300), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(0, 0, 0, false, true))
+	body := addBox(em, raylib.NewVector2(400, 350), raylib.NewVector2(10, 10), physicscomponents.NewRigidBody(1, 0, 0, false, false))
+
+	if err := em.AddJoint(body, physicscomponents.Joint{Type: physicscomponents.DistanceJoint, Connected: anchored, Length: 50}); err != nil {
+		t.Fatal(err)
+	}
+	checkJointHolds(t, em, body, 0.5, 50, 1)
+
+	em.DestroyEntity(anchored)
+	stepFor(em, 0.5)
+
+	if joints, _ := ecs.Get[physicscomponents.Joints](em, body); len(joints.Entries) != 0 {
+		t.Fatalf("joints = %+v, want the joint to the destroyed entity removed", joints.Entries)
+	}
+
+	// The body came to rest hanging and fell asleep, it wakes the step the joint is removed and falls freely after
+	rb, _ := ecs.Get[physicscomponents.RigidBody](em, body)
+	if want := float32(980 * 29 / 60.0); rb.IsSleeping || math.Abs(float64(rb.Velocity.Y-want)) > 1 {
+		t.Fatalf("body falls at %v, want it falling freely at %v", rb.Velocity.Y, want)
+	}
+}
@@ -0,0 +1,367 @@
+package physicssystems
+
+import (
+	// STD
+	"cmp"
+	"slices"
+	"sync"
+
+	// ECS
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+
+	// PHYSICS
+	"github.com/webbelito/Fenrir/pkg/physics"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	// RAYLIB
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PhysicsQueries answers questions about the colliders in the world: raycasts, shape casts and overlap tests.
+// The bounds of the colliders are indexed in a DynamicTree kept up to date by Sync, which the CollisionSystem calls
+// every step, while the shapes themselves are tested at their current position.
+// Every query takes a mask of the collision layers it hits, see ECSManager.GetCollisionLayers.
+// Trigger colliders are never hit.
+type PhysicsQueries struct {
+	tree       *physics.DynamicTree
+	proxies    map[uint64]colliderProxy
+	queryMutex sync.RWMutex
+	ecsManager *ecs.ECSManager
+}
+
+// colliderProxy is the DynamicTree proxy of a collider and the placement its bounds were last computed at
+type colliderProxy struct {
+	id       int32
+	position raylib.Vector2
+	rotation float32
+}
+
+// NewPhysicsQueries initializes and returns new PhysicsQueries with an empty DynamicTree growing bounds by margin
+func NewPhysicsQueries(ecsM *ecs.ECSManager, margin float32) *PhysicsQueries {
+	return &PhysicsQueries{
+		tree:       physics.NewDynamicTree(margin),
+		proxies:    make(map[uint64]colliderProxy),
+		ecsManager: ecsM,
+	}
+}
+
+// Sync updates the DynamicTree with the colliders that were added, moved or removed since the last call,
+// destroyed entities included, and returns every entity with a collider in entity order.
+// Static and sleeping bodies keep their proxy without their bounds being computed again until their Transform2D changes.
+func (pq *PhysicsQueries) Sync() []uint64 {
+	entities := getColliderEntities(pq.ecsManager)
+	slices.Sort(entities)
+
+	// Find the bounds first so the tree is locked as briefly as possible
+	live := make(map[uint64]bool, len(entities))
+	bounds := make(map[uint64]physics.Rectangle)
+	for _, eID := range entities {
+		transform, transformExists := ecs.Get[components.Transform2D](pq.ecsManager, eID)
+		if !transformExists {
+			continue
+		}
+		live[eID] = true
+
+		if proxy, proxyExists := pq.proxies[eID]; proxyExists && pq.isResting(eID) &&
+			proxy.position == transform.Position && proxy.rotation == transform.Rotation {
+			continue
+		}
+
+		if colliderBounds, boundsExist := getColliderBounds(pq.ecsManager, eID); boundsExist {
+			bounds[eID] = colliderBounds
+		} else {
+			delete(live, eID)
+		}
+	}
+
+	pq.queryMutex.Lock()
+	defer pq.queryMutex.Unlock()
+
+	// Remove the proxies of entities that lost their collider or were destroyed
+	for eID, proxy := range pq.proxies {
+		if !live[eID] {
+			pq.tree.DestroyProxy(proxy.id)
+			delete(pq.proxies, eID)
+		}
+	}
+
+	// Add the new colliders and move the others, only those leaving their grown bounds change the tree
+	for _, eID := range entities {
+		colliderBounds, boundsExist := bounds[eID]
+		if !boundsExist {
+			continue
+		}
+
+		transform, _ := ecs.Get[components.Transform2D](pq.ecsManager, eID)
+		pq.updateProxy(eID, transform, colliderBounds)
+	}
+
+	return entities
+}
+
+// SyncEntity updates the proxy of a single collider, e.g. after a continuous body was swept,
+// so the queries following it in the same step find the collider where it is now
+func (pq *PhysicsQueries) SyncEntity(eID uint64) {
+	transform, transformExists := ecs.Get[components.Transform2D](pq.ecsManager, eID)
+	colliderBounds, boundsExist := getColliderBounds(pq.ecsManager, eID)
+	if !transformExists || !boundsExist {
+		return
+	}
+
+	pq.queryMutex.Lock()
+	defer pq.queryMutex.Unlock()
+
+	pq.updateProxy(eID, transform, colliderBounds)
+}
+
+// updateProxy adds the proxy of a collider or moves it to its bounds, the query mutex must be held
+func (pq *PhysicsQueries) updateProxy(eID uint64, transform *components.Transform2D, colliderBounds physics.Rectangle) {
+	proxy, proxyExists := pq.proxies[eID]
+
+	if proxyExists {
+		pq.tree.MoveProxy(proxy.id, colliderBounds)
+	} else {
+		proxy.id = pq.tree.CreateProxy(eID, colliderBounds)
+	}
+
+	proxy.position = transform.Position
+	proxy.rotation = transform.Rotation
+	pq.proxies[eID] = proxy
+}
+
+// isResting checks if an entity has a static or sleeping body, which only moves when something else moves it
+func (pq *PhysicsQueries) isResting(eID uint64) bool {
+	rb, rbExists := ecs.Get[physicscomponents.RigidBody](pq.ecsManager, eID)
+	return rbExists && (rb.IsStatic || rb.IsSleeping)
+}
+
+// getPairs returns every pair of entities whose colliders may overlap, once each with the lower entity ID first.
+// Only the colliders that moved in the DynamicTree since the previous step are queried.
+func (pq *PhysicsQueries) getPairs() [][2]uint64 {
+	pq.queryMutex.Lock()
+	defer pq.queryMutex.Unlock()
+
+	return pq.tree.UpdatePairs()
+}
+
+// Raycast returns the first collider hit by a ray
+func (pq *PhysicsQueries) Raycast(origin raylib.Vector2, direction raylib.Vector2, maxDistance float32, mask uint32) (physics.RaycastHit, bool) {
+	hits := pq.raycast(origin, direction, maxDistance, mask, true)
+	if len(hits) == 0 {
+		return physics.RaycastHit{}, false
+	}
+
+	return hits[0], true
+}
+
+// RaycastAll returns every collider hit by a ray, closest first
+func (pq *PhysicsQueries) RaycastAll(origin raylib.Vector2, direction raylib.Vector2, maxDistance float32, mask uint32) []physics.RaycastHit {
+	return pq.raycast(origin, direction, maxDistance, mask, false)
+}
+
+// raycast walks a ray through the DynamicTree and returns the hits, closest first.
+// Only the tree nodes the ray passes through are visited. If closestOnly is set the ray is clipped at every hit,
+// so the nodes behind the closest hit so far are skipped and only the closest hits are returned.
+func (pq *PhysicsQueries) raycast(origin raylib.Vector2, direction raylib.Vector2, maxDistance float32, mask uint32, closestOnly bool) []physics.RaycastHit {
+	hits := []physics.RaycastHit{}
+
+	if raylib.Vector2Length(direction) == 0 || maxDistance < 0 {
+		return hits
+	}
+	direction = raylib.Vector2Normalize(direction)
+
+	pq.queryMutex.RLock()
+	defer pq.queryMutex.RUnlock()
+
+	pq.tree.Raycast(origin, direction, maxDistance, func(eID uint64, maxDistance float32) float32 {
+		collider, colliderExists := getCollider(pq.ecsManager, eID)
+		if !colliderExists || collider.IsTrigger || !collider.IsInMask(mask) {
+			return maxDistance
+		}
+
+		target, _, targetExists := getColliderShape(pq.ecsManager, eID)
+		if !targetExists {
+			return maxDistance
+		}
+
+		hit, hitExists := physics.Raycast(&target, origin, direction, maxDistance)
+		if !hitExists {
+			return maxDistance
+		}
+
+		hit.Entity = eID
+		hits = append(hits, hit)
+
+		if closestOnly {
+			return hit.Distance
+		}
+
+		return maxDistance
+	})
+
+	sortHits(hits)
+
+	// Hits found before the ray was clipped by a closer one are dropped
+	if closestOnly && len(hits) > 0 {
+		closest := hits[0].Distance
+		hits = slices.DeleteFunc(hits, func(hit physics.RaycastHit) bool {
+			return hit.Distance > closest
+		})
+	}
+
+	return hits
+}
+
+// ShapeCast moves a shape along a direction and returns the first collider it touches
+func (pq *PhysicsQueries) ShapeCast(shape *physics.Shape, direction raylib.Vector2, maxDistance float32, mask uint32) (physics.RaycastHit, bool) {
+	if raylib.Vector2Length(direction) == 0 || maxDistance < 0 {
+		return physics.RaycastHit{}, false
+	}
+	direction = raylib.Vector2Normalize(direction)
+
+	candidates := pq.getCandidates(getCastBounds(shape, direction, maxDistance), mask)
+
+	return pq.shapeCast(shape, candidates, direction, maxDistance)
+}
+
+// ShapeCastCollider moves the collider of an entity along a direction and returns the first collider it would collide with.
+// The entity itself and the colliders whose layers don't collide with it are ignored.
+func (pq *PhysicsQueries) ShapeCastCollider(eID uint64, direction raylib.Vector2, maxDistance float32) (physics.RaycastHit, bool) {
+	if raylib.Vector2Length(direction) == 0 || maxDistance < 0 {
+		return physics.RaycastHit{}, false
+	}
+	direction = raylib.Vector2Normalize(direction)
+
+	shape, collider, shapeExists := getColliderShape(pq.ecsManager, eID)
+	if !shapeExists {
+		return physics.RaycastHit{}, false
+	}
+
+	candidates := pq.getCandidates(getCastBounds(&shape, direction, maxDistance), collider.Mask)
+	candidates = slices.DeleteFunc(candidates, func(otherID uint64) bool {
+		other, otherExists := getCollider(pq.ecsManager, otherID)
+		return otherID == eID || !otherExists || !collider.CanCollideWith(other)
+	})
+
+	return pq.shapeCast(&shape, candidates, direction, maxDistance)
+}
+
+// shapeCast casts a shape against candidates and returns the closest hit
+func (pq *PhysicsQueries) shapeCast(shape *physics.Shape, candidates []uint64, direction raylib.Vector2, maxDistance float32) (physics.RaycastHit, bool) {
+	hits := pq.castAll(candidates, func(target *physics.Shape) (physics.RaycastHit, bool) {
+		return physics.CastShape(shape, target, direction, maxDistance)
+	})
+	if len(hits) == 0 {
+		return physics.RaycastHit{}, false
+	}
+
+	return hits[0], true
+}
+
+// OverlapBox returns the entities whose colliders overlap a box rotated by rotation degrees
+func (pq *PhysicsQueries) OverlapBox(center raylib.Vector2, size raylib.Vector2, rotation float32, mask uint32) []uint64 {
+	box := physics.NewBoxShape(center, size, rotation)
+	return pq.OverlapShape(&box, mask)
+}
+
+// OverlapCircle returns the entities whose colliders overlap a circle
+func (pq *PhysicsQueries) OverlapCircle(center raylib.Vector2, radius float32, mask uint32) []uint64 {
+	circle := physics.NewCircleShape(center, radius)
+	return pq.OverlapShape(&circle, mask)
+}
+
+// OverlapShape returns the entities whose colliders overlap a shape, in entity order
+func (pq *PhysicsQueries) OverlapShape(shape *physics.Shape, mask uint32) []uint64 {
+	overlapping := []uint64{}
+
+	for _, eID := range pq.getCandidates(shape.Bounds(), mask) {
+		target, _, targetExists := getColliderShape(pq.ecsManager, eID)
+		if !targetExists {
+			continue
+		}
+
+		if _, colliding := physics.Collide(shape, &target); colliding {
+			overlapping = append(overlapping, eID)
+		}
+	}
+
+	return overlapping
+}
+
+// castAll runs a cast against the candidates and returns the hits, closest first
+func (pq *PhysicsQueries) castAll(candidates []uint64, cast func(target *physics.Shape) (physics.RaycastHit, bool)) []physics.RaycastHit {
+	hits := []physics.RaycastHit{}
+
+	for _, eID := range candidates {
+		target, _, targetExists := getColliderShape(pq.ecsManager, eID)
+		if !targetExists {
+			continue
+		}
+
+		if hit, hitExists := cast(&target); hitExists {
+			hit.Entity = eID
+			hits = append(hits, hit)
+		}
+	}
+
+	sortHits(hits)
+
+	return hits
+}
+
+// sortHits orders hits closest first, hits at the same distance by entity so results are the same every run
+func sortHits(hits []physics.RaycastHit) {
+	slices.SortFunc(hits, func(a physics.RaycastHit, b physics.RaycastHit) int {
+		if a.Distance != b.Distance {
+			return cmp.Compare(a.Distance, b.Distance)
+		}
+
+		return cmp.Compare(a.Entity, b.Entity)
+	})
+}
+
+// getCandidates returns the entities whose colliders may overlap bounds and are on a layer of the mask, in entity order
+func (pq *PhysicsQueries) getCandidates(bounds physics.Rectangle, mask uint32) []uint64 {
+	found := pq.query(bounds)
+
+	candidates := make([]uint64, 0, len(found))
+	for _, eID := range found {
+		collider, colliderExists := getCollider(pq.ecsManager, eID)
+		if !colliderExists || collider.IsTrigger || !collider.IsInMask(mask) {
+			continue
+		}
+
+		candidates = append(candidates, eID)
+	}
+
+	slices.Sort(candidates)
+
+	return candidates
+}
+
+// query returns the entities whose colliders may overlap bounds
+func (pq *PhysicsQueries) query(bounds physics.Rectangle) []uint64 {
+	pq.queryMutex.RLock()
+	defer pq.queryMutex.RUnlock()
+
+	found := []uint64{}
+	pq.tree.Query(bounds, &found)
+
+	return found
+}
+
+// getCastBounds returns the bounds covering a shape at the start and at the end of a cast
+func getCastBounds(shape *physics.Shape, direction raylib.Vector2, maxDistance float32) physics.Rectangle {
+	start := shape.Bounds()
+	end := shape.Translate(raylib.Vector2Scale(direction, maxDistance))
+	endBounds := end.Bounds()
+
+	bounds := physics.Rectangle{
+		Position: raylib.NewVector2(min(start.Position.X, endBounds.Position.X), min(start.Position.Y, endBounds.Position.Y)),
+	}
+	bounds.Width = max(start.Position.X+start.Width, endBounds.Position.X+endBounds.Width) - bounds.Position.X
+	bounds.Height = max(start.Position.Y+start.Height, endBounds.Position.Y+endBounds.Height) - bounds.Position.Y
+
+	return bounds
+}
@@ -0,0 +1,125 @@
+package physicssystems
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/physics"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Static and sleeping bodies are not measured again every Sync, but are still found where they were moved to
+func TestSyncFollowsMovedRestingBodies(t *testing.T) {
+	em := ecs.NewECSManager()
+	queries := NewPhysicsQueries(em, 0)
+
+	wall := em.CreateEntity().ID
+	ecs.Add(em, wall, &components.Transform2D{Position: raylib.NewVector2(0, 0), Scale: raylib.NewVector2(1, 1)})
+	ecs.Add(em, wall, physicscomponents.NewBoxCollider(raylib.NewVector2(10, 10)))
+	ecs.Add(em, wall, physicscomponents.NewRigidBody(0, 0, 0, false, true))
+
+	sleeper := em.CreateEntity().ID
+	sleeperBody := physicscomponents.NewRigidBody(1, 0, 0, false, false)
+	sleeperBody.Sleep()
+	ecs.Add(em, sleeper, &components.Transform2D{Position: raylib.NewVector2(100, 0), Scale: raylib.NewVector2(1, 1)})
+	ecs.Add(em, sleeper, physicscomponents.NewCircleCollider(5))
+	ecs.Add(em, sleeper, sleeperBody)
+
+	queries.Sync()
+	if pairs := queries.getPairs(); len(pairs) != 0 {
+		t.Fatalf("pairs = %v, want none while the bodies are apart", pairs)
+	}
+
+	wallTransform, _ := ecs.Get[components.Transform2D](em, wall)
+	wallTransform.Position = raylib.NewVector2(500, 500)
+	sleeperTransform, _ := ecs.Get[components.Transform2D](em, sleeper)
+	sleeperTransform.Position = raylib.NewVector2(505, 500)
+	queries.Sync()
+
+	if found := queries.OverlapCircle(raylib.NewVector2(500, 500), 20, physicscomponents.AllCollisionLayers); !slices.Equal(found, []uint64{wall, sleeper}) {
+		t.Fatalf("found %v at the new position, want the wall and the sleeping body", found)
+	}
+	if pairs := queries.getPairs(); !slices.Equal(pairs, [][2]uint64{{wall, sleeper}}) {
+		t.Fatalf("pairs = %v, want the wall and the sleeping body", pairs)
+	}
+}
+
+// newQueryWorld creates three boxes of 20 pixels along the x axis at 100, 200 and 300, the one at 200 a trigger
+// and the one at 300 on a layer of its own, and returns the queries and the three entities
+func newQueryWorld(t *testing.T) (*PhysicsQueries, uint64, uint64, uint64) {
+	t.Helper()
+
+	em := ecs.NewECSManager()
+	queries := NewPhysicsQueries(em, 0)
+
+	addCollider := func(x float32) (uint64, *physicscomponents.BoxCollider) {
+		eID := em.CreateEntity().ID
+		ecs.Add(em, eID, &components.Transform2D{Position: raylib.NewVector2(x, 0), Scale: raylib.NewVector2(1, 1)})
+		ecs.Add(em, eID, physicscomponents.NewBoxCollider(raylib.NewVector2(20, 20)))
+		collider, _ := ecs.Get[physicscomponents.BoxCollider](em, eID)
+		return eID, collider
+	}
+
+	first, _ := addCollider(100)
+	trigger, triggerCollider := addCollider(200)
+	triggerCollider.IsTrigger = true
+	other, otherCollider := addCollider(300)
+	otherCollider.Layer = 1 << 3
+
+	queries.Sync()
+
+	return queries, first, trigger, other
+}
+
+// Raycasts hit the closest collider of their mask and never triggers, and miss colliders off the ray or past its end
+func TestRaycastHitsClosestCollider(t *testing.T) {
+	queries, first, _, other := newQueryWorld(t)
+	right := raylib.NewVector2(1, 0)
+
+	hit, hitExists := queries.Raycast(raylib.Vector2{}, right, 1_000, physicscomponents.AllCollisionLayers)
+	if !hitExists || hit.Entity != first || hit.Distance != 90 || hit.Normal != raylib.NewVector2(-1, 0) {
+		t.Fatalf("hit = %+v, %v, want the first box at 90 facing the ray", hit, hitExists)
+	}
+
+	hits := queries.RaycastAll(raylib.Vector2{}, right, 1_000, physicscomponents.AllCollisionLayers)
+	if len(hits) != 2 || hits[0].Entity != first || hits[1].Entity != other || hits[1].Distance != 290 {
+		t.Fatalf("hits = %+v, want the first and the last box, closest first", hits)
+	}
+
+	if hit, hitExists := queries.Raycast(raylib.Vector2{}, right, 1_000, 1<<3); !hitExists || hit.Entity != other {
+		t.Fatalf("hit = %+v, %v, want the box on the layer of the mask", hit, hitExists)
+	}
+
+	if hit, hitExists := queries.Raycast(raylib.Vector2{}, raylib.NewVector2(1, 1), 1_000, physicscomponents.AllCollisionLayers); hitExists {
+		t.Fatalf("diagonal ray hit %+v, want nothing", hit)
+	}
+	if hit, hitExists := queries.Raycast(raylib.Vector2{}, right, 50, physicscomponents.AllCollisionLayers); hitExists {
+		t.Fatalf("short ray hit %+v, want nothing", hit)
+	}
+}
+
+// Shape casts and overlap tests find the colliders of their mask and never triggers
+func TestShapeCastAndOverlaps(t *testing.T) {
+	queries, first, _, other := newQueryWorld(t)
+
+	circle := physics.NewCircleShape(raylib.Vector2{}, 5)
+	hit, hitExists := queries.ShapeCast(&circle, raylib.NewVector2(1, 0), 1_000, physicscomponents.AllCollisionLayers)
+	if !hitExists || hit.Entity != first || math.Abs(float64(hit.Distance-85)) > 0.1 {
+		t.Fatalf("shape cast hit = %+v, %v, want the first box at 85", hit, hitExists)
+	}
+
+	if found := queries.OverlapCircle(raylib.NewVector2(100, 0), 5, physicscomponents.AllCollisionLayers); !slices.Equal(found, []uint64{first}) {
+		t.Fatalf("circle found %v, want the first box", found)
+	}
+	if found := queries.OverlapBox(raylib.NewVector2(200, 0), raylib.NewVector2(220, 10), 0, physicscomponents.AllCollisionLayers); !slices.Equal(found, []uint64{first, other}) {
+		t.Fatalf("box found %v, want the first and the last box", found)
+	}
+	if found := queries.OverlapBox(raylib.NewVector2(200, 0), raylib.NewVector2(220, 10), 0, physicscomponents.DefaultCollisionLayer); !slices.Equal(found, []uint64{first}) {
+		t.Fatalf("box found %v with the default layer, want the first box", found)
+	}
+}
@@ -1,15 +1,32 @@
 package physicssystems
 
 import (
+	"math"
+
 	"github.com/webbelito/Fenrir/pkg/components"
 	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/events"
+	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
+	"github.com/webbelito/Fenrir/pkg/physics"
 	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
 
 	raylib "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Constants for continuous collision detection
+const (
+	DefaultMaxSubSteps = 8   // Default number of sub-steps a continuous body can be split into per step
+	continuousSkin     = 0.1 // Distance a continuous body is moved into the collider it hits, so the CollisionSystem resolves the contact
+)
+
 type RigidBodySystem struct {
-	Gravity           raylib.Vector2
+	Gravity     raylib.Vector2
+	MaxSubSteps int
+
+	// WorldBounds keeps the bodies in the world, the bodies are clamped to the screen without them
+	WorldBounds *physics.WorldBounds
+	outside     map[uint64]bool
+
 	ecsManager        *ecs.ECSManager
 	entitiesManager   *ecs.EntitiesManager
 	componentsManager *ecs.ComponentsManager
@@ -19,6 +36,8 @@ type RigidBodySystem struct {
 func NewRigidBodySystem(ecsM *ecs.ECSManager, gravity raylib.Vector2, p int) *RigidBodySystem {
 	return &RigidBodySystem{
 		Gravity:           gravity,
+		MaxSubSteps:       DefaultMaxSubSteps,
+		outside:           make(map[uint64]bool),
 		ecsManager:        ecsM,
 		entitiesManager:   ecsM.GetEntitiesManager(),
 		componentsManager: ecsM.GetComponentsManager(),
@@ -26,91 +45,324 @@ func NewRigidBodySystem(ecsM *ecs.ECSManager, gravity raylib.Vector2, p int) *Ri
 	}
 }
 
+// continuousBody is a continuous body waiting to be swept once every other body moved
+type continuousBody struct {
+	eID       uint64
+	rb        *physicscomponents.RigidBody
+	transform *components.Transform2D
+}
+
 func (rbs *RigidBodySystem) Update(dt float64) {
 
-	rigidBodyComps, rigidBodyCompsExists := rbs.componentsManager.Components[ecs.RigidBodyComponent]
+	// Continuous bodies are swept with the physics queries, without them they move like the other bodies
+	queries, queriesExist := rbs.ecsManager.GetPhysicsQueries()
 
-	if !rigidBodyCompsExists {
-		return
-	}
+	// Bodies outside event bounds during this step
+	bounds := getWorldBounds(rbs.ecsManager, rbs.WorldBounds)
+	outside := make(map[uint64]bool)
 
-	screenWidth := float32(raylib.GetScreenWidth())
-	screenHeight := float32(raylib.GetScreenHeight())
+	// Continuous bodies found during the query, the sweeps query other colliders so they run after it
+	continuous := []continuousBody{}
 
-	for entity, rigidBodyComp := range rigidBodyComps {
-		rb, rbExists := rigidBodyComp.(*physicscomponents.RigidBody)
+	rbs.componentsManager.Query([]ecs.ComponentType{
+		ecs.RigidBodyComponent,
+		ecs.Transform2DComponent,
+	}, func(entity uint64, comps []ecs.Component) {
+		rb, rbExists := comps[0].(*physicscomponents.RigidBody)
 
 		if !rbExists {
-			continue
+			return
 		}
 
 		if rb.IsStatic {
-			continue
+			return
 		}
 
-		if !rb.IsKinematic {
-			// Apply gravity to the entity (F = m * g)
-			rb.Force = raylib.Vector2Add(rb.Force, raylib.Vector2Scale(rbs.Gravity, rb.Mass))
+		// Sleeping bodies are left where they rest until a force or a new velocity wakes them
+		if rb.IsSleeping {
+			if rb.Force == (raylib.Vector2{}) && rb.Torque == 0 && rb.Velocity == (raylib.Vector2{}) && rb.AngularVelocity == 0 {
+				return
+			}
+
+			rb.Wake()
 		}
 
-		// Apply drag: F Drag = -Drag * v
-		dragForce := raylib.Vector2Scale(rb.Velocity, -rb.Drag)
-		rb.Force = raylib.Vector2Add(rb.Force, dragForce)
+		// Get the position component for the entity
+		transform, transformExists := comps[1].(*components.Transform2D)
+
+		// Refresh the inertia, the collider may have changed since the last step
+		rb.InvInertia = rbs.getInvInertia(entity, rb)
 
-		// Update acceleration based on force (a = F / m)
-		if rb.Mass != 0 {
-			rb.Acceleration = raylib.Vector2Scale(rb.Force, 1/rb.Mass)
-		} else {
-			rb.Acceleration = raylib.NewVector2(0, 0)
+		if rb.IsContinuous && queriesExist && transformExists {
+			continuous = append(continuous, continuousBody{eID: entity, rb: rb, transform: transform})
+			return
 		}
 
-		// Calculate InvMass
-		if rb.Mass != 0 {
-			rb.InvMass = 1 / rb.Mass
-		} else {
-			rb.InvMass = 0
+		rbs.integrate(rb, dt)
+
+		if transformExists {
+			rbs.move(rb, transform, dt)
+
+			// Keep the body in the world
+			rbs.applyWorldBounds(entity, rb, transform, &bounds, outside)
 		}
 
-		// Update velocity based on acceleration (v += a * dt)
-		rb.Velocity = raylib.Vector2Add(rb.Velocity, raylib.Vector2Scale(rb.Acceleration, float32(dt)))
+		// Reset force and torque for the next frame
+		rb.Force = raylib.NewVector2(0, 0)
+		rb.Torque = 0
+	})
 
-		// Get the position component for the entity
-		transform, transformExists := rbs.componentsManager.Components[ecs.Transform2DComponent][entity].(*components.Transform2D)
+	// The other bodies moved, sweep against where they are now and not where the last step left them
+	if len(continuous) > 0 {
+		queries.Sync()
+	}
 
-		// Handle Position related Updates
-		if transformExists {
+	for _, body := range continuous {
+		rbs.sweep(body, dt, queries)
+		rbs.applyWorldBounds(body.eID, body.rb, body.transform, &bounds, outside)
 
-			// Clamp the position to the screen bounds
+		// The continuous bodies swept after this one must find it where it stopped
+		queries.SyncEntity(body.eID)
 
-			// If the position is less than the size, set it to the size
-			if transform.Position.X < transform.Scale.X {
-				transform.Position.X = transform.Scale.X
-				rb.Velocity.X = 0
-				// If the position is greater than the screen width minus the size, set it to the screen width minus the size
-			} else if transform.Position.X > screenWidth-transform.Scale.X {
-				transform.Position.X = screenWidth - transform.Scale.X
-				rb.Velocity.X = 0
-			}
+		body.rb.Force = raylib.NewVector2(0, 0)
+		body.rb.Torque = 0
+	}
 
-			// If the position is less than the size, set it to the size
-			if transform.Position.Y < transform.Scale.Y {
-				transform.Position.Y = transform.Scale.Y
-				rb.Velocity.Y = 0
-				// If the position is greater than the screen height minus the size, set it to the screen height minus the size
-			} else if transform.Position.Y > screenHeight-transform.Scale.Y {
-				transform.Position.Y = screenHeight - transform.Scale.Y
-				rb.Velocity.Y = 0
-			}
+	rbs.outside = outside
+}
+
+// sweep moves a continuous body through the step, split into sub-steps so forces are integrated along the way.
+// It stops at the first collider the body hits, the CollisionSystem resolves the contact.
+func (rbs *RigidBodySystem) sweep(body continuousBody, dt float64, queries systeminterfaces.PhysicsQueriesInterface) {
+	subSteps := rbs.getSubSteps(body.eID, body.rb, dt)
+	subDt := dt / float64(subSteps)
+
+	for range subSteps {
+		rbs.integrate(body.rb, subDt)
+
+		if rbs.moveContinuous(body.eID, body.rb, body.transform, subDt, queries) {
+			return
 		}
+	}
+}
 
-		// Update position based on velocity (p += v * dt)
-		transform.Position = raylib.Vector2Add(transform.Position, raylib.Vector2Scale(rb.Velocity, float32(dt)))
+// integrate updates the velocity of a body from its forces, gravity and drag
+func (rbs *RigidBodySystem) integrate(rb *physicscomponents.RigidBody, dt float64) {
+	force := rb.Force
 
-		// Reset force for the next frame
-		rb.Force = raylib.NewVector2(0, 0)
+	if !rb.IsKinematic {
+		// Apply gravity to the entity (F = m * g)
+		force = raylib.Vector2Add(force, raylib.Vector2Scale(rbs.Gravity, rb.Mass))
+	}
+
+	// Apply drag: F Drag = -Drag * v
+	dragForce := raylib.Vector2Scale(rb.Velocity, -rb.Drag)
+	force = raylib.Vector2Add(force, dragForce)
+
+	// Update acceleration based on force (a = F / m)
+	if rb.Mass != 0 {
+		rb.Acceleration = raylib.Vector2Scale(force, 1/rb.Mass)
+	} else {
+		rb.Acceleration = raylib.NewVector2(0, 0)
+	}
+
+	// Calculate InvMass
+	if rb.Mass != 0 {
+		rb.InvMass = 1 / rb.Mass
+	} else {
+		rb.InvMass = 0
 	}
+
+	// Update velocity based on acceleration (v += a * dt)
+	rb.Velocity = raylib.Vector2Add(rb.Velocity, raylib.Vector2Scale(rb.Acceleration, float32(dt)))
+
+	// Update angular velocity based on torque (w += T / I * dt), the torque is in radians
+	rb.AngularVelocity += rb.Torque * rb.InvInertia * float32(dt) * raylib.Rad2deg
+
+	// Apply angular drag as a fraction of the angular velocity per second
+	rb.AngularVelocity /= 1 + rb.AngularDrag*float32(dt)
+}
+
+// getInvInertia returns the inverse inertia of a body, from its collider unless the body sets its Inertia
+func (rbs *RigidBodySystem) getInvInertia(eID uint64, rb *physicscomponents.RigidBody) float32 {
+	if rb.FixedRotation {
+		return 0
+	}
+
+	inertia := rb.Inertia
+	if inertia == 0 {
+		shape, _, shapeExists := getColliderShape(rbs.ecsManager, eID)
+		if !shapeExists {
+			return 0
+		}
+
+		inertia = shape.Inertia(rb.Mass)
+	}
+
+	if inertia <= 0 {
+		return 0
+	}
+
+	return 1 / inertia
+}
+
+// turn rotates a body by its angular velocity, keeping the rotation between 0 and 360 degrees
+func (rbs *RigidBodySystem) turn(rb *physicscomponents.RigidBody, transform *components.Transform2D, dt float64) {
+	if rb.AngularVelocity == 0 {
+		return
+	}
+
+	rotation := math.Mod(float64(transform.Rotation+rb.AngularVelocity*float32(dt)), 360)
+	if rotation < 0 {
+		rotation += 360
+	}
+
+	transform.Rotation = float32(rotation)
+}
+
+// move moves a body by its velocity
+func (rbs *RigidBodySystem) move(rb *physicscomponents.RigidBody, transform *components.Transform2D, dt float64) {
+
+	// Update position based on velocity (p += v * dt)
+	transform.Position = raylib.Vector2Add(transform.Position, raylib.Vector2Scale(rb.Velocity, float32(dt)))
+	rbs.turn(rb, transform, dt)
+}
+
+// moveContinuous sweeps the collider of a body along its velocity instead of moving it in one jump.
+// It returns true if the body hit a collider, it is then left just inside it.
+func (rbs *RigidBodySystem) moveContinuous(eID uint64, rb *physicscomponents.RigidBody, transform *components.Transform2D, dt float64, queries systeminterfaces.PhysicsQueriesInterface) bool {
+	// Turning is not swept, only the movement is
+	rbs.turn(rb, transform, dt)
+
+	displacement := raylib.Vector2Scale(rb.Velocity, float32(dt))
+	distance := raylib.Vector2Length(displacement)
+	if distance == 0 {
+		return false
+	}
+
+	direction := raylib.Vector2Scale(displacement, 1/distance)
+
+	hit, hitExists := queries.ShapeCastCollider(eID, direction, distance)
+	if !hitExists {
+		transform.Position = raylib.Vector2Add(transform.Position, displacement)
+		return false
+	}
+
+	transform.Position = raylib.Vector2Add(transform.Position, raylib.Vector2Scale(direction, min(hit.Distance+continuousSkin, distance)))
+	return true
+}
+
+// getSubSteps returns the number of sub-steps that keep a continuous body from moving further than half its collider per sub-step
+func (rbs *RigidBodySystem) getSubSteps(eID uint64, rb *physicscomponents.RigidBody, dt float64) int {
+	shape, _, shapeExists := getColliderShape(rbs.ecsManager, eID)
+	if !shapeExists {
+		return 1
+	}
+
+	bounds := shape.Bounds()
+	maxSubDistance := min(bounds.Width, bounds.Height) / 2
+	distance := raylib.Vector2Length(rb.Velocity) * float32(dt)
+
+	if maxSubDistance <= 0 || distance <= maxSubDistance {
+		return 1
+	}
+
+	return min(int(math.Ceil(float64(distance/maxSubDistance))), max(rbs.MaxSubSteps, 1))
+}
+
+// applyWorldBounds wraps a body around wrapping bounds, or applies the out of bounds policy to a body outside finite bounds.
+// Bodies outside event bounds are added to outside, the event is only sent the step they leave.
+func (rbs *RigidBodySystem) applyWorldBounds(eID uint64, rb *physicscomponents.RigidBody, transform *components.Transform2D, bounds *physics.WorldBounds, outside map[uint64]bool) {
+	switch bounds.Mode {
+	case physics.WrapBounds:
+		wrapped := bounds.Wrap(transform.Position)
+		if wrapped != transform.Position {
+			transform.Position = wrapped
+			rbs.ecsManager.SkipInterpolation(eID)
+		}
+		return
+	case physics.FiniteBounds:
+	default:
+		return
+	}
+
+	// Clamped bodies stay entirely inside, the others leave once their center does
+	if bounds.Policy == physics.ClampPolicy {
+		clampToBounds(rb, transform, &bounds.Rectangle)
+		return
+	}
+
+	if bounds.Rectangle.Contains(transform.Position) {
+		return
+	}
+
+	switch bounds.Policy {
+	case physics.DestroyPolicy:
+		// Other systems may be iterating the entity's archetype
+		rbs.ecsManager.GetSystemCommandBuffer(rbs).DestroyEntity(eID)
+
+	case physics.EventPolicy:
+		outside[eID] = true
+		if !rbs.outside[eID] {
+			rbs.ecsManager.GetEventsManager().Queue(events.OutOfBoundsEvent{Entity: eID, Position: transform.Position})
+		}
+	}
+}
+
+// clampToBounds keeps a body inside a rectangle, stopping it along the edges it reached
+func clampToBounds(rb *physicscomponents.RigidBody, transform *components.Transform2D, rect *physics.Rectangle) {
+	halfWidth := min(transform.Scale.X/2, rect.Width/2)
+	halfHeight := min(transform.Scale.Y/2, rect.Height/2)
+
+	// If the body is past the left or right edge, put it back against it
+	if transform.Position.X < rect.Position.X+halfWidth {
+		transform.Position.X = rect.Position.X + halfWidth
+		rb.Velocity.X = 0
+	} else if transform.Position.X > rect.Position.X+rect.Width-halfWidth {
+		transform.Position.X = rect.Position.X + rect.Width - halfWidth
+		rb.Velocity.X = 0
+	}
+
+	// If the body is past the top or bottom edge, put it back against it
+	if transform.Position.Y < rect.Position.Y+halfHeight {
+		transform.Position.Y = rect.Position.Y + halfHeight
+		rb.Velocity.Y = 0
+	} else if transform.Position.Y > rect.Position.Y+rect.Height-halfHeight {
+		transform.Position.Y = rect.Position.Y + rect.Height - halfHeight
+		rb.Velocity.Y = 0
+	}
+}
+
+// getWorldBounds returns the world bounds of a system, or bounds clamping to the screen if it has none
+func getWorldBounds(em *ecs.ECSManager, bounds *physics.WorldBounds) physics.WorldBounds {
+	if bounds != nil {
+		return *bounds
+	}
+
+	return physics.NewScreenWorldBounds(float32(em.GetPlatform().GetScreenWidth()), float32(em.GetPlatform().GetScreenHeight()))
 }
 
 func (rbs *RigidBodySystem) GetPriority() int {
 	return rbs.priority
 }
+
+// GetReadComponents returns the component types the RigidBodySystem reads, the colliders are swept for continuous bodies
+func (rbs *RigidBodySystem) GetReadComponents() []ecs.ComponentType {
+	return colliderComponents
+}
+
+// GetWriteComponents returns the component types the RigidBodySystem writes
+func (rbs *RigidBodySystem) GetWriteComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.RigidBodyComponent, ecs.Transform2DComponent}
+}
+
+// GetReadResources returns the resources the RigidBodySystem reads
+func (rbs *RigidBodySystem) GetReadResources() []ecs.Resource {
+	return []ecs.Resource{}
+}
+
+// GetWriteResources returns the resources the RigidBodySystem writes, the physics world is synced before continuous bodies
+// are swept through it and bodies leaving the world bounds are reported as events
+func (rbs *RigidBodySystem) GetWriteResources() []ecs.Resource {
+	return []ecs.Resource{ecs.PhysicsWorldResource, ecs.EventsResource}
+}
@@ -0,0 +1,166 @@
+package physicssystems
+
+import (
+	"math"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/physics"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+	"github.com/webbelito/Fenrir/pkg/platform"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// fireAtWall fires a small box at 10,000 pixels per second at a static wall 2 pixels thick for half a second,
+// and returns the final position of the box and the left side of the wall
+func fireAtWall(t *testing.T, isContinuous bool) (float32, float32) {
+	t.Helper()
+
+	em := ecs.NewECSManager()
+	em.SetPlatform(platform.NewNullPlatform(1280, 720))
+	em.AddLogicSystem(NewRigidBodySystem(em, raylib.Vector2{}, 1), 1)
+	em.AddLogicSystem(NewCollisionSystem(em, 0, 2), 2)
+
+	wall := em.CreateEntity().ID
+	ecs.Add(em, wall, &components.Transform2D{Position: raylib.NewVector2(700, 360), Scale: raylib.NewVector2(1, 1)})
+	ecs.Add(em, wall, physicscomponents.NewBoxCollider(raylib.NewVector2(2, 400)))
+	ecs.Add(em, wall, physicscomponents.NewRigidBody(0, 0, 0, false, true))
+
+	bullet := em.CreateEntity().ID
+	rb := physicscomponents.NewRigidBody(1, 0, 0, false, false)
+	rb.Velocity = raylib.NewVector2(10_000, 0)
+	rb.IsContinuous = isContinuous
+	ecs.Add(em, bullet, &components.Transform2D{Position: raylib.NewVector2(100, 360), Scale: raylib.NewVector2(1, 1)})
+	ecs.Add(em, bullet, physicscomponents.NewBoxCollider(raylib.NewVector2(10, 10)))
+	ecs.Add(em, bullet, rb)
+
+	for range 30 {
+		em.StepLogicSystems(1.0 / 60.0)
+	}
+
+	transform, _ := ecs.Get[components.Transform2D](em, bullet)
+	return transform.Position.X, 699
+}
+
+// A continuous body fired at a thin wall stops at it instead of passing through between two steps
+func TestContinuousBodyStopsAtThinWall(t *testing.T) {
+	// Without the sweep the body moves 166 pixels per step and jumps over the wall
+	if x, wallX := fireAtWall(t, false); x < wallX {
+		t.Fatalf("discrete body stopped at %v, the wall is too thick to test the sweep", x)
+	}
+
+	if x, wallX := fireAtWall(t, true); x+5 > wallX+continuousSkin+0.5 {
+		t.Fatalf("continuous body ended at %v, past the wall at %v", x, wallX)
+	}
+}
+
+// A body wrapping around the world bounds is rendered at the opposite edge, not interpolated across the world
+func TestWrappedBodySkipsInterpolation(t *testing.T) {
+	em := ecs.NewECSManager()
+	em.SetPlatform(platform.NewNullPlatform(1280, 720))
+
+	rbs := NewRigidBodySystem(em, raylib.Vector2{}, 1)
+	rbs.WorldBounds = &physics.WorldBounds{Mode: physics.WrapBounds, Rectangle: physics.Rectangle{Width: 1280, Height: 720}, Policy: physics.ClampPolicy}
+	em.AddLogicSystem(rbs, 1)
+
+	body := em.CreateEntity().ID
+	rb := physicscomponents.NewRigidBody(1, 0, 0, false, false)
+	rb.Velocity = raylib.NewVector2(600, 0)
+	ecs.Add(em, body, &components.Transform2D{Position: raylib.NewVector2(1275, 360), Scale: raylib.NewVector2(1, 1)})
+	ecs.Add(em, body, rb)
+
+	// One and a half steps, the body moves 10 pixels and wraps to the left edge
+	em.UpdateLogicSystems(1.5 / 60.0)
+
+	transform, _ := ecs.Get[components.Transform2D](em, body)
+	if transform.Position.X > 10 {
+		t.Fatalf("body at %v, want it wrapped to the left edge", transform.Position)
+	}
+	if position, _ := em.GetInterpolatedTransform(body, transform); position != transform.Position {
+		t.Fatalf("wrapped body rendered at %v, want its position %v", position, transform.Position)
+	}
+}
+
+// Two fast continuous bodies moving toward each other meet at their surfaces instead of passing into each other,
+// the second body is swept against where the first one stopped in the same step
+func TestContinuousBodiesMeetHeadOn(t *testing.T) {
+	em := ecs.NewECSManager()
+	em.SetPlatform(platform.NewNullPlatform(1280, 720))
+	em.AddLogicSystem(NewRigidBodySystem(em, raylib.Vector2{}, 1), 1)
+	em.AddLogicSystem(NewCollisionSystem(em, 0, 2), 2)
+
+	addBullet := func(x float32, velocity float32) uint64 {
+		rb := physicscomponents.NewRigidBody(1, 0, 0, false, false)
+		rb.Velocity = raylib.NewVector2(velocity, 0)
+		rb.IsContinuous = true
+		return addBox(em, raylib.NewVector2(x, 360), raylib.NewVector2(10, 10), rb)
+	}
+
+	// Each bullet moves 500 pixels per step, both reach x 600 during the first step
+	left := addBullet(100, 30_000)
+	right := addBullet(1100, -30_000)
+
+	em.StepLogicSystems(1.0 / 60.0)
+
+	leftTransform, _ := ecs.Get[components.Transform2D](em, left)
+	rightTransform, _ := ecs.Get[components.Transform2D](em, right)
+	if gap := rightTransform.Position.X - leftTransform.Position.X; gap < 10-2*continuousSkin-0.5 {
+		t.Fatalf("bullets at %v and %v, want them touching and not %v pixels into each other", leftTransform.Position.X, rightTransform.Position.X, 10-gap)
+	}
+}
+
+// The inertia of a body comes from the shape of its collider, unless the body sets its own or can't turn
+func TestInertiaComesFromCollider(t *testing.T) {
+	em := ecs.NewECSManager()
+	em.SetPlatform(platform.NewNullPlatform(1280, 720))
+	em.AddLogicSystem(NewRigidBodySystem(em, raylib.Vector2{}, 1), 1)
+
+	addBody := func(rb *physicscomponents.RigidBody) uint64 {
+		eID := em.CreateEntity().ID
+		ecs.Add(em, eID, &components.Transform2D{Position: raylib.NewVector2(400, 300), Scale: raylib.NewVector2(1, 1)})
+		ecs.Add(em, eID, rb)
+		return eID
+	}
+
+	// A box has m * (w² + h²) / 12, a circle m * r² / 2
+	box := addBody(physicscomponents.NewRigidBody(2, 0, 0, false, false))
+	ecs.Add(em, box, physicscomponents.NewBoxCollider(raylib.NewVector2(40, 20)))
+
+	circle := addBody(physicscomponents.NewRigidBody(2, 0, 0, false, false))
+	ecs.Add(em, circle, physicscomponents.NewCircleCollider(10))
+
+	customBody := physicscomponents.NewRigidBody(2, 0, 0, false, false)
+	customBody.Inertia = 50
+	custom := addBody(customBody)
+	ecs.Add(em, custom, physicscomponents.NewBoxCollider(raylib.NewVector2(40, 20)))
+
+	fixedBody := physicscomponents.NewRigidBody(2, 0, 0, false, false)
+	fixedBody.FixedRotation = true
+	fixed := addBody(fixedBody)
+	ecs.Add(em, fixed, physicscomponents.NewBoxCollider(raylib.NewVector2(40, 20)))
+
+	em.StepLogicSystems(1.0 / 60.0)
+
+	for _, test := range []struct {
+		name    string
+		eID     uint64
+		inertia float32
+	}{
+		{"box", box, 2 * (40*40 + 20*20) / 12.0},
+		{"circle", circle, 2 * 10 * 10 / 2.0},
+		{"custom", custom, 50},
+		{"fixed", fixed, 0},
+	} {
+		rb, _ := ecs.Get[physicscomponents.RigidBody](em, test.eID)
+
+		want := float32(0)
+		if test.inertia != 0 {
+			want = 1 / test.inertia
+		}
+		if math.Abs(float64(rb.InvInertia-want)) > 1e-6 {
+			t.Fatalf("%s: inverse inertia %v, want %v", test.name, rb.InvInertia, want)
+		}
+	}
+}
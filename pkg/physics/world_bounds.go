@@ -0,0 +1,95 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// WorldBoundsMode is how the edges of the world treat bodies reaching them
+type WorldBoundsMode string
+
+const (
+	// FiniteBounds applies the OutOfBoundsPolicy to bodies leaving the rectangle
+	FiniteBounds WorldBoundsMode = "finite"
+
+	// UnboundedBounds lets bodies go anywhere, the rectangle is ignored
+	UnboundedBounds WorldBoundsMode = "unbounded"
+
+	// WrapBounds moves bodies leaving the rectangle to the opposite edge
+	WrapBounds WorldBoundsMode = "wrap"
+)
+
+// OutOfBoundsPolicy is what happens to a body leaving finite world bounds
+type OutOfBoundsPolicy string
+
+const (
+	// ClampPolicy keeps the body inside the bounds and stops it along the edge it reached
+	ClampPolicy OutOfBoundsPolicy = "clamp"
+
+	// DestroyPolicy destroys the entity of the body
+	DestroyPolicy OutOfBoundsPolicy = "destroy"
+
+	// EventPolicy lets the body leave and sends an events.OutOfBoundsEvent
+	EventPolicy OutOfBoundsPolicy = "event"
+)
+
+// WorldBounds is the area of the world the physics simulates
+type WorldBounds struct {
+	Mode      WorldBoundsMode
+	Rectangle Rectangle
+	Policy    OutOfBoundsPolicy
+}
+
+// NewScreenWorldBounds returns finite bounds covering a screen, clamping the bodies inside it
+func NewScreenWorldBounds(width float32, height float32) WorldBounds {
+	return WorldBounds{
+		Mode:      FiniteBounds,
+		Rectangle: Rectangle{Width: width, Height: height},
+		Policy:    ClampPolicy,
+	}
+}
+
+// Validate checks the mode and policy are known and that bounded modes have an area
+func (wb *WorldBounds) Validate() error {
+	switch wb.Mode {
+	case FiniteBounds, WrapBounds:
+		if wb.Rectangle.Width <= 0 || wb.Rectangle.Height <= 0 {
+			return fmt.Errorf("world bounds: %s bounds need a width and height above 0", wb.Mode)
+		}
+	case UnboundedBounds:
+	default:
+		return fmt.Errorf("world bounds: unknown mode %q", wb.Mode)
+	}
+
+	switch wb.Policy {
+	case ClampPolicy, DestroyPolicy, EventPolicy:
+	default:
+		return fmt.Errorf("world bounds: unknown out of bounds policy %q", wb.Policy)
+	}
+
+	return nil
+}
+
+// Wrap moves a point outside the rectangle to the opposite edge
+func (wb *WorldBounds) Wrap(point raylib.Vector2) raylib.Vector2 {
+	return raylib.NewVector2(
+		wrap(point.X, wb.Rectangle.Position.X, wb.Rectangle.Width),
+		wrap(point.Y, wb.Rectangle.Position.Y, wb.Rectangle.Height),
+	)
+}
+
+// wrap keeps a value between start and start + length, continuing from the other end
+func wrap(value float32, start float32, length float32) float32 {
+	if length <= 0 {
+		return value
+	}
+
+	offset := math.Mod(float64(value-start), float64(length))
+	if offset < 0 {
+		offset += float64(length)
+	}
+
+	return start + float32(offset)
+}
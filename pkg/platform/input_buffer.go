@@ -0,0 +1,93 @@
+package platform
+
+import (
+	"sync"
+)
+
+// InputBuffer samples the keyboard of a platform once per frame for the fixed logic steps.
+// A frame can run no step at all, so a key press is kept until a step has seen it instead of being lost,
+// and on frames running several steps it is only reported to the first of them.
+// Keys are sampled once they have been queried, the first query reads the platform directly.
+type InputBuffer struct {
+	platform    Platform
+	watched     map[int32]bool
+	keysDown    map[int32]bool
+	keysPressed map[int32]bool
+	inputMutex  sync.RWMutex
+}
+
+// NewInputBuffer initializes and returns a new InputBuffer sampling the keys of a platform
+func NewInputBuffer(p Platform) *InputBuffer {
+	return &InputBuffer{
+		platform:    p,
+		watched:     make(map[int32]bool),
+		keysDown:    make(map[int32]bool),
+		keysPressed: make(map[int32]bool),
+	}
+}
+
+// Sample reads the state of the watched keys, it is called once per frame before the logic steps.
+// Presses not yet seen by a step are kept.
+func (ib *InputBuffer) Sample() {
+	ib.inputMutex.Lock()
+	defer ib.inputMutex.Unlock()
+
+	for key := range ib.watched {
+		ib.keysDown[key] = ib.platform.IsKeyDown(key)
+		if ib.platform.IsKeyPressed(key) {
+			ib.keysPressed[key] = true
+		}
+	}
+}
+
+// EndStep marks the sampled presses as seen, it is called after every logic step
+func (ib *InputBuffer) EndStep() {
+	ib.inputMutex.Lock()
+	defer ib.inputMutex.Unlock()
+
+	clear(ib.keysPressed)
+}
+
+// IsKeyDown checks if a key was held down when the frame was sampled
+func (ib *InputBuffer) IsKeyDown(key int32) bool {
+	ib.watch(key)
+
+	ib.inputMutex.RLock()
+	defer ib.inputMutex.RUnlock()
+
+	return ib.keysDown[key]
+}
+
+// IsKeyPressed checks if a key was pressed since the last step
+func (ib *InputBuffer) IsKeyPressed(key int32) bool {
+	ib.watch(key)
+
+	ib.inputMutex.RLock()
+	defer ib.inputMutex.RUnlock()
+
+	return ib.keysPressed[key]
+}
+
+// watch starts sampling a key, reading its current state from the platform the first time
+func (ib *InputBuffer) watch(key int32) {
+	ib.inputMutex.RLock()
+	isWatched := ib.watched[key]
+	ib.inputMutex.RUnlock()
+
+	if isWatched {
+		return
+	}
+
+	ib.inputMutex.Lock()
+	defer ib.inputMutex.Unlock()
+
+	if ib.watched[key] {
+		return
+	}
+
+	ib.watched[key] = true
+	ib.keysDown[key] = ib.platform.IsKeyDown(key)
+	if ib.platform.IsKeyPressed(key) {
+		ib.keysPressed[key] = true
+	}
+}
@@ -0,0 +1,47 @@
+package platform
+
+import (
+	"testing"
+)
+
+const testKey = 32
+
+// A key pressed on a frame that runs no step must still reach the next step, and only that one
+func TestInputBufferKeepsPressUntilStep(t *testing.T) {
+	np := NewNullPlatform(800, 600)
+	input := NewInputBuffer(np)
+
+	// The first query starts watching the key
+	if input.IsKeyPressed(testKey) {
+		t.Fatal("key pressed before it was pressed")
+	}
+
+	// Frame 1: the key is pressed but no step runs
+	np.PressKey(testKey)
+	input.Sample()
+	np.EndFrame()
+
+	// Frame 2: the press is no longer reported by the platform, two steps run
+	input.Sample()
+
+	if !input.IsKeyPressed(testKey) {
+		t.Fatal("press of a frame without a step was lost")
+	}
+	if !input.IsKeyDown(testKey) {
+		t.Fatal("held key is not down")
+	}
+	input.EndStep()
+
+	if input.IsKeyPressed(testKey) {
+		t.Fatal("press was reported to a second step")
+	}
+	if !input.IsKeyDown(testKey) {
+		t.Fatal("held key is not down in the second step")
+	}
+
+	np.ReleaseKey(testKey)
+	input.Sample()
+	if input.IsKeyDown(testKey) {
+		t.Fatal("released key is still down")
+	}
+}
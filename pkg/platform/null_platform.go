@@ -0,0 +1,152 @@
+package platform
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// NullPlatform implements Platform without a window or audio device.
+// Screen size is fixed, input is set manually and time only advances when told to,
+// and random values come from a seeded generator, which makes it suited for
+// reproducible tests and simulations on machines without a display.
+type NullPlatform struct {
+	screenWidth   int
+	screenHeight  int
+	keysDown      map[int32]bool
+	keysPressed   map[int32]bool
+	time          float64
+	fps           int32
+	random        *rand.Rand
+	platformMutex sync.RWMutex
+}
+
+// NewNullPlatform initializes and returns a new NullPlatform with the given screen size.
+// Random values are seeded with 0, use SetRandomSeed to change it.
+func NewNullPlatform(screenWidth int, screenHeight int) *NullPlatform {
+	return &NullPlatform{
+		screenWidth:  screenWidth,
+		screenHeight: screenHeight,
+		keysDown:     make(map[int32]bool),
+		keysPressed:  make(map[int32]bool),
+		random:       rand.New(rand.NewSource(0)),
+	}
+}
+
+func (np *NullPlatform) GetScreenWidth() int {
+	np.platformMutex.RLock()
+	defer np.platformMutex.RUnlock()
+
+	return np.screenWidth
+}
+
+func (np *NullPlatform) GetScreenHeight() int {
+	np.platformMutex.RLock()
+	defer np.platformMutex.RUnlock()
+
+	return np.screenHeight
+}
+
+// SetScreenSize changes the screen size reported by the platform
+func (np *NullPlatform) SetScreenSize(screenWidth int, screenHeight int) {
+	np.platformMutex.Lock()
+	defer np.platformMutex.Unlock()
+
+	np.screenWidth = screenWidth
+	np.screenHeight = screenHeight
+}
+
+func (np *NullPlatform) IsKeyDown(key int32) bool {
+	np.platformMutex.RLock()
+	defer np.platformMutex.RUnlock()
+
+	return np.keysDown[key]
+}
+
+func (np *NullPlatform) IsKeyPressed(key int32) bool {
+	np.platformMutex.RLock()
+	defer np.platformMutex.RUnlock()
+
+	return np.keysPressed[key]
+}
+
+// PressKey marks a key as pressed and held down until ReleaseKey is called.
+// The key only reports as pressed until the next call to EndFrame.
+func (np *NullPlatform) PressKey(key int32) {
+	np.platformMutex.Lock()
+	defer np.platformMutex.Unlock()
+
+	if !np.keysDown[key] {
+		np.keysPressed[key] = true
+	}
+	np.keysDown[key] = true
+}
+
+// ReleaseKey marks a key as no longer held down
+func (np *NullPlatform) ReleaseKey(key int32) {
+	np.platformMutex.Lock()
+	defer np.platformMutex.Unlock()
+
+	delete(np.keysDown, key)
+	delete(np.keysPressed, key)
+}
+
+// EndFrame clears the pressed state of all keys, like the end of a raylib frame
+func (np *NullPlatform) EndFrame() {
+	np.platformMutex.Lock()
+	defer np.platformMutex.Unlock()
+
+	clear(np.keysPressed)
+}
+
+func (np *NullPlatform) GetTime() float64 {
+	np.platformMutex.RLock()
+	defer np.platformMutex.RUnlock()
+
+	return np.time
+}
+
+// AdvanceTime moves the platform time forward by dt seconds and records the resulting frame rate
+func (np *NullPlatform) AdvanceTime(dt float64) {
+	np.platformMutex.Lock()
+	defer np.platformMutex.Unlock()
+
+	np.time += dt
+
+	if dt > 0 {
+		np.fps = int32(1 / dt)
+	}
+}
+
+func (np *NullPlatform) GetFPS() int32 {
+	np.platformMutex.RLock()
+	defer np.platformMutex.RUnlock()
+
+	return np.fps
+}
+
+func (np *NullPlatform) GetRandomValue(min int32, max int32) int32 {
+	np.platformMutex.Lock()
+	defer np.platformMutex.Unlock()
+
+	if min > max {
+		min, max = max, min
+	}
+
+	return min + np.random.Int31n(max-min+1)
+}
+
+// SetRandomSeed resets the random generator with the given seed
+func (np *NullPlatform) SetRandomSeed(seed int64) {
+	np.platformMutex.Lock()
+	defer np.platformMutex.Unlock()
+
+	np.random = rand.New(rand.NewSource(seed))
+}
+
+func (np *NullPlatform) IsWindowReady() bool {
+	return false
+}
+
+func (np *NullPlatform) IsAudioReady() bool {
+	return false
+}
@@ -0,0 +1,22 @@
+package platform
+
+// Platform is the interface for everything the engine queries from the window, input and audio devices.
+// Systems and scenes go through it instead of calling raylib directly so they can run without a window.
+type Platform interface {
+	GetScreenWidth() int
+	GetScreenHeight() int
+
+	// Keys use the raylib key codes, e.g. raylib.KeyW
+	IsKeyDown(key int32) bool
+	IsKeyPressed(key int32) bool
+
+	// GetTime returns the elapsed time in seconds since the platform was initialized
+	GetTime() float64
+	GetFPS() int32
+
+	// GetRandomValue returns a random value between min and max, both included
+	GetRandomValue(min int32, max int32) int32
+
+	IsWindowReady() bool
+	IsAudioReady() bool
+}
@@ -0,0 +1,50 @@
+package platform
+
+import (
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// RaylibPlatform implements Platform on top of the raylib window and audio device
+type RaylibPlatform struct{}
+
+// NewRaylibPlatform initializes and returns a new RaylibPlatform.
+// The raylib window should be initialized before it is queried.
+func NewRaylibPlatform() *RaylibPlatform {
+	return &RaylibPlatform{}
+}
+
+func (rp *RaylibPlatform) GetScreenWidth() int {
+	return raylib.GetScreenWidth()
+}
+
+func (rp *RaylibPlatform) GetScreenHeight() int {
+	return raylib.GetScreenHeight()
+}
+
+func (rp *RaylibPlatform) IsKeyDown(key int32) bool {
+	return raylib.IsKeyDown(key)
+}
+
+func (rp *RaylibPlatform) IsKeyPressed(key int32) bool {
+	return raylib.IsKeyPressed(key)
+}
+
+func (rp *RaylibPlatform) GetTime() float64 {
+	return raylib.GetTime()
+}
+
+func (rp *RaylibPlatform) GetFPS() int32 {
+	return raylib.GetFPS()
+}
+
+func (rp *RaylibPlatform) GetRandomValue(min int32, max int32) int32 {
+	return raylib.GetRandomValue(min, max)
+}
+
+func (rp *RaylibPlatform) IsWindowReady() bool {
+	return raylib.IsWindowReady()
+}
+
+func (rp *RaylibPlatform) IsAudioReady() bool {
+	return raylib.IsAudioDeviceReady()
+}
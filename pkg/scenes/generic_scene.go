@@ -0,0 +1,452 @@
+package scenes
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/events"
+	systeminterfaces "github.com/webbelito/Fenrir/pkg/interfaces/systeminterfaces"
+	"github.com/webbelito/Fenrir/pkg/resources"
+	"github.com/webbelito/Fenrir/pkg/systems"
+	"github.com/webbelito/Fenrir/pkg/utils"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// GenericScene is a scene built entirely from its scene file,
+// including the systems it runs, its key bindings, its entities and its environment
+type GenericScene struct {
+	sceneManager    *SceneManager
+	resourceManager *resources.ResourcesManager
+	ecsManager      *ecs.ECSManager
+	sceneData       *SceneData
+
+	entities        []*ecs.Entity
+	logicSystems    []systeminterfaces.UpdatableSystemInterface
+	renderSystems   []systeminterfaces.RenderableSystemInterface
+	uiRenderSystems []systeminterfaces.UIRenderableSystemInterface
+	keyBindings     []keyBinding
+	closableSystems []closableSystem
+
+	music        raylib.Music
+	musicLoaded  bool
+	isParallel   bool
+	cameraSystem systeminterfaces.CameraSystemInterface
+
+	// Performance Metrics
+	updateDuration time.Duration
+	renderDuration time.Duration
+	totalDuration  time.Duration
+}
+
+// keyBinding is a KeyBindingData resolved to a raylib key code
+type keyBinding struct {
+	key       int32
+	event     string
+	scenePath string
+}
+
+// cameraUser is implemented by systems that need the camera of the scene
+type cameraUser interface {
+	SetCameraSystem(cs *systems.CameraSystem)
+}
+
+// closableSystem is implemented by systems holding on to something outside the scene, e.g. event subscriptions
+type closableSystem interface {
+	Close()
+}
+
+func NewGenericScene(sm *SceneManager, em *ecs.ECSManager, sd *SceneData) *GenericScene {
+	return &GenericScene{
+		sceneManager:    sm,
+		resourceManager: resources.NewResourceManager(),
+		ecsManager:      em,
+		sceneData:       sd,
+		entities:        []*ecs.Entity{},
+		logicSystems:    []systeminterfaces.UpdatableSystemInterface{},
+		renderSystems:   []systeminterfaces.RenderableSystemInterface{},
+		uiRenderSystems: []systeminterfaces.UIRenderableSystemInterface{},
+		keyBindings:     []keyBinding{},
+	}
+}
+
+func (gs *GenericScene) Initialize() {
+
+	utils.InfoLogger.Printf("Initializing %s Scene...", gs.sceneData.SceneName)
+
+	// Create the systems declared in the scene data
+	gs.initializeSystems()
+
+	// Run non-conflicting logic systems concurrently
+	if gs.sceneData.Parallel {
+		gs.ecsManager.GetSystemsManager().SetParallel(true)
+		gs.isParallel = true
+	}
+
+	gs.initializeKeyBindings()
+
+	// Initialize Entities based on Scene Data
+	gs.initializeEntities()
+
+	// Initialize Environment settings
+	gs.initializeEnvironment()
+
+	// Spawn entities with random positions and colors
+	gs.spawnEntities(gs.sceneData.RandomEntities)
+
+	// Assign the player entity as the camera's owner
+	if gs.cameraSystem != nil {
+		for _, entity := range gs.entities {
+			if ecs.Has[components.Player](gs.ecsManager, entity.ID) {
+				gs.cameraSystem.SetOwner(entity.ID)
+				break
+			}
+		}
+	}
+}
+
+func (gs *GenericScene) Update(dt float64) {
+
+	// Update ECS Manager
+	updateStart := time.Now()
+	if !gs.sceneData.PauseWorld {
+		gs.ecsManager.UpdateLogicSystems(dt)
+	}
+
+	// Keep the music stream buffers filled
+	if gs.musicLoaded {
+		raylib.UpdateMusicStream(gs.music)
+	}
+
+	// Dispatch the events of the pressed key bindings
+	for _, binding := range gs.keyBindings {
+		if !gs.ecsManager.GetPlatform().IsKeyPressed(binding.key) {
+			continue
+		}
+
+		event, _ := events.NewSceneEvent(binding.event, binding.scenePath)
+		gs.ecsManager.GetEventsManager().Dispatch(event)
+	}
+
+	gs.updateDuration = time.Since(updateStart)
+}
+
+func (gs *GenericScene) Render() {
+
+	// Render ECS Manager
+	renderStart := time.Now()
+	gs.ecsManager.UpdateRenderSystems()
+
+	// Calculate Performance Metrics
+	gs.renderDuration = time.Since(renderStart)
+	gs.totalDuration = gs.updateDuration + gs.renderDuration
+}
+
+func (gs *GenericScene) Cleanup() {
+	// Remove all entities created by this scene
+	gs.RemoveAllEntities()
+
+	// Remove and cleanup logic systems
+	if gs.isParallel {
+		gs.ecsManager.GetSystemsManager().SetParallel(false)
+		gs.isParallel = false
+	}
+
+	for _, system := range gs.logicSystems {
+		gs.ecsManager.RemoveLogicSystem(system)
+	}
+	gs.logicSystems = nil
+
+	// Remove and cleanup render systems
+	for _, system := range gs.renderSystems {
+		gs.ecsManager.RemoveRenderSystem(system)
+	}
+	gs.renderSystems = nil
+
+	for _, system := range gs.uiRenderSystems {
+		gs.ecsManager.RemoveUIRenderSystem(system, system.GetPriority())
+	}
+	gs.uiRenderSystems = nil
+
+	// Release what the systems hold outside the scene
+	for _, system := range gs.closableSystems {
+		system.Close()
+	}
+	gs.closableSystems = nil
+
+	// Cleanup resources
+	if gs.musicLoaded {
+		raylib.StopMusicStream(gs.music)
+		raylib.UnloadMusicStream(gs.music)
+		gs.musicLoaded = false
+	}
+}
+
+func (gs *GenericScene) Pause() {
+	// TODO: Implement Pause functionality
+	// Pause game logic if necessary
+	// For example, stop certain systems or timers
+}
+
+func (gs *GenericScene) Resume() {
+	// TODO: Implement Resume functionality
+	// Resume game logic if necessary
+	// For example, resume certain systems or timers
+}
+
+func (gs *GenericScene) GetSceneData() *SceneData {
+	return gs.sceneData
+}
+
+func (gs *GenericScene) AddEntity(e *ecs.Entity) {
+	gs.entities = append(gs.entities, e)
+}
+
+// GetEntities returns the entities created by the scene
+func (gs *GenericScene) GetEntities() []*ecs.Entity {
+	return gs.entities
+}
+
+func (gs *GenericScene) RemoveAllEntities() {
+	for _, entity := range gs.entities {
+		gs.ecsManager.DestroyEntity(entity.ID)
+	}
+
+	gs.entities = []*ecs.Entity{}
+}
+
+func (gs *GenericScene) initializeSystems() {
+
+	ctx := &SystemContext{
+		ECSManager:       gs.ecsManager,
+		ResourcesManager: gs.resourceManager,
+		systems:          make(map[string]any),
+	}
+
+	// The physics systems keep the bodies in the world bounds of the scene
+	if gs.sceneData.WorldBounds != nil {
+		bounds, err := gs.sceneData.WorldBounds.WorldBounds()
+		if err != nil {
+			utils.ErrorLogger.Printf("%s Scene: %v, bodies are clamped to the screen", gs.sceneData.SceneName, err)
+		} else {
+			ctx.WorldBounds = &bounds
+		}
+	}
+
+	var cameraSystem *systems.CameraSystem
+
+	for _, systemData := range gs.sceneData.Systems {
+
+		factory, factoryExists := GetSystemFactory(systemData.Name)
+		if !factoryExists {
+			utils.ErrorLogger.Printf("%s Scene: unknown system %s", gs.sceneData.SceneName, systemData.Name)
+			continue
+		}
+
+		system, err := factory(ctx, systemData.Priority, systemData.Params)
+		if err != nil {
+			utils.ErrorLogger.Printf("%s Scene: failed to create system %s: %v", gs.sceneData.SceneName, systemData.Name, err)
+			continue
+		}
+
+		ctx.systems[systemData.Name] = system
+
+		// Add the system for every role it implements
+		if logicSystem, isLogicSystem := system.(systeminterfaces.UpdatableSystemInterface); isLogicSystem {
+			gs.ecsManager.AddLogicSystem(logicSystem, logicSystem.GetPriority())
+			gs.logicSystems = append(gs.logicSystems, logicSystem)
+		}
+
+		if renderSystem, isRenderSystem := system.(systeminterfaces.RenderableSystemInterface); isRenderSystem {
+			gs.ecsManager.AddRenderSystem(renderSystem, renderSystem.GetPriority())
+			gs.renderSystems = append(gs.renderSystems, renderSystem)
+		}
+
+		if uiRenderSystem, isUIRenderSystem := system.(systeminterfaces.UIRenderableSystemInterface); isUIRenderSystem {
+			gs.ecsManager.AddUIRenderSystem(uiRenderSystem, uiRenderSystem.GetPriority())
+			gs.uiRenderSystems = append(gs.uiRenderSystems, uiRenderSystem)
+		}
+
+		if closable, isClosable := system.(closableSystem); isClosable {
+			gs.closableSystems = append(gs.closableSystems, closable)
+		}
+
+		if cs, isCameraSystem := system.(*systems.CameraSystem); isCameraSystem {
+			cameraSystem = cs
+			gs.cameraSystem = cs
+		}
+	}
+
+	// Give the camera to the systems rendering through it
+	if cameraSystem == nil {
+		return
+	}
+
+	for _, system := range ctx.systems {
+		if user, isCameraUser := system.(cameraUser); isCameraUser {
+			user.SetCameraSystem(cameraSystem)
+		}
+	}
+}
+
+func (gs *GenericScene) initializeKeyBindings() {
+	for _, bindingData := range gs.sceneData.KeyBindings {
+
+		key, keyExists := getKeyFromString(bindingData.Key)
+		if !keyExists {
+			utils.ErrorLogger.Printf("%s Scene: unknown key %s", gs.sceneData.SceneName, bindingData.Key)
+			continue
+		}
+
+		if _, eventExists := events.NewSceneEvent(bindingData.Event, bindingData.ScenePath); !eventExists {
+			utils.ErrorLogger.Printf("%s Scene: unknown event %s", gs.sceneData.SceneName, bindingData.Event)
+			continue
+		}
+
+		gs.keyBindings = append(gs.keyBindings, keyBinding{
+			key:       key,
+			event:     bindingData.Event,
+			scenePath: bindingData.ScenePath,
+		})
+	}
+}
+
+func (gs *GenericScene) initializeEntities() {
+
+	// Name the collision layers before the colliders referencing them are loaded
+	if len(gs.sceneData.CollisionLayers) > 0 {
+		if err := gs.ecsManager.GetCollisionLayers().Set(gs.sceneData.CollisionLayers); err != nil {
+			utils.ErrorLogger.Printf("%s Scene: %v", gs.sceneData.SceneName, err)
+		}
+	}
+
+	// Create the entities and their components from the scene data
+	entities, errs := createEntities(gs.ecsManager, gs.sceneData)
+	for _, err := range errs {
+		utils.ErrorLogger.Printf("%s Scene: %v", gs.sceneData.SceneName, err)
+	}
+
+	for _, entity := range entities {
+		gs.AddEntity(entity)
+	}
+
+	// Place the children before the first frame is rendered
+	gs.ecsManager.PropagateTransforms()
+}
+
+func (gs *GenericScene) initializeEnvironment() {
+	env := gs.sceneData.Environment
+	bgColor := utils.GetColorFromString(env.BackgroundColor)
+
+	// Skip anything that needs a window or audio device, e.g. when running headless
+	if gs.ecsManager.GetPlatform().IsWindowReady() {
+		raylib.ClearBackground(bgColor)
+	}
+
+	if env.Music != "" && gs.ecsManager.GetPlatform().IsAudioReady() {
+		gs.music = raylib.LoadMusicStream(env.Music)
+		raylib.PlayMusicStream(gs.music)
+		gs.musicLoaded = true
+	}
+}
+
+// randomEntityPrefab is the prefab spawned at random positions for the scene's RandomEntities
+const randomEntityPrefab = "physics_box"
+
+func (gs *GenericScene) spawnEntities(count int) {
+
+	// Colors to choose from
+	colors := []raylib.Color{
+		raylib.Blue,
+		raylib.Green,
+		raylib.Purple,
+		raylib.Orange,
+		raylib.Pink,
+		raylib.Yellow,
+		raylib.SkyBlue,
+		raylib.Lime,
+		raylib.Gold,
+		raylib.Violet,
+		raylib.Brown,
+		raylib.LightGray,
+		raylib.DarkGray,
+	}
+
+	platform := gs.ecsManager.GetPlatform()
+
+	// Create entities
+	for i := 0; i < count; i++ {
+
+		// Select a random color from the colors slice
+		color := colors[platform.GetRandomValue(0, int32(len(colors)-1))]
+
+		spawnPos := raylib.NewVector2(float32(platform.GetRandomValue(0, int32(platform.GetScreenWidth())-1)), float32(platform.GetRandomValue(0, int32(platform.GetScreenHeight())-1)))
+
+		overrides, err := ecs.MakeOverrides(map[string]any{
+			"Transform2D": map[string]any{"position": ecs.NewVector2Data(spawnPos)},
+			"Color":       map[string]any{"color": ecs.NewColorData(color)},
+		})
+		if err != nil {
+			utils.ErrorLogger.Printf("GenericScene: %v", err)
+			return
+		}
+
+		// Create a physics box with a Transform2D, RigidBody, BoxCollider and Color
+		entity, err := gs.ecsManager.Instantiate(randomEntityPrefab, overrides)
+		if err != nil {
+			utils.ErrorLogger.Printf("GenericScene: failed to spawn %s: %v", randomEntityPrefab, err)
+			return
+		}
+
+		gs.AddEntity(entity)
+	}
+}
+
+// getKeyFromString returns the raylib key code for a key name used in scene files, e.g. "escape", "p" or "f1"
+func getKeyFromString(keyName string) (int32, bool) {
+	keyName = strings.ToLower(keyName)
+
+	switch keyName {
+	case "escape":
+		return raylib.KeyEscape, true
+	case "enter":
+		return raylib.KeyEnter, true
+	case "space":
+		return raylib.KeySpace, true
+	case "tab":
+		return raylib.KeyTab, true
+	case "backspace":
+		return raylib.KeyBackspace, true
+	case "up":
+		return raylib.KeyUp, true
+	case "down":
+		return raylib.KeyDown, true
+	case "left":
+		return raylib.KeyLeft, true
+	case "right":
+		return raylib.KeyRight, true
+	}
+
+	// Letters and digits map to their ASCII codes
+	if len(keyName) == 1 {
+		c := keyName[0]
+		switch {
+		case c >= 'a' && c <= 'z':
+			return raylib.KeyA + int32(c-'a'), true
+		case c >= '0' && c <= '9':
+			return raylib.KeyZero + int32(c-'0'), true
+		}
+	}
+
+	// Function keys are sequential from F1 to F12
+	for i := int32(1); i <= 12; i++ {
+		if keyName == "f"+strconv.Itoa(int(i)) {
+			return raylib.KeyF1 + i - 1, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,145 @@
+package scenes
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	physicssystems "github.com/webbelito/Fenrir/pkg/physics/systems"
+	"github.com/webbelito/Fenrir/pkg/platform"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Tests run in the package directory, the assets are at the repository root
+var assetsDirectory = filepath.Join("..", "..", "assets")
+
+// newHeadlessManagers returns managers running on a NullPlatform with the prefabs of the repository
+func newHeadlessManagers(t *testing.T) (*ecs.ECSManager, *platform.NullPlatform, *SceneManager) {
+	t.Helper()
+
+	em := ecs.NewECSManager()
+	np := platform.NewNullPlatform(1280, 720)
+	em.SetPlatform(np)
+	em.GetPrefabsManager().SetDirectory(filepath.Join(assetsDirectory, "prefabs"))
+
+	sm := NewSceneManager(em)
+	t.Cleanup(sm.Close)
+
+	return em, np, sm
+}
+
+// runFrames runs frames like the main loop does, without rendering
+func runFrames(t *testing.T, em *ecs.ECSManager, np *platform.NullPlatform, sm *SceneManager, frames int, frameTime float64) {
+	t.Helper()
+
+	for frame := 0; frame < frames; frame++ {
+		np.AdvanceTime(frameTime)
+		em.GetEventsManager().Flush()
+
+		if currentScene := sm.GetCurrentScene(); currentScene != nil {
+			currentScene.Update(frameTime)
+		}
+
+		if sm.ShouldChangeScene() {
+			if err := sm.ApplyPendingSceneChange(); err != nil {
+				t.Fatalf("frame %d: %v", frame, err)
+			}
+		}
+
+		np.EndFrame()
+	}
+}
+
+// The game scene loads and runs without a window: the player walks right while D is held
+func TestGameSceneRunsHeadless(t *testing.T) {
+	em, np, sm := newHeadlessManagers(t)
+
+	if err := sm.PushScene(filepath.Join(assetsDirectory, "scenes", "game_scene.json")); err != nil {
+		t.Fatalf("PushScene: %v", err)
+	}
+
+	var player uint64
+	for eID := range ecs.Query1[components.Player](em) {
+		player = eID
+	}
+	if player == 0 {
+		t.Fatal("the game scene has no player")
+	}
+
+	start, _ := ecs.Get[components.Transform2D](em, player)
+	startX := start.Position.X
+
+	np.PressKey(raylib.KeyD)
+	runFrames(t, em, np, sm, 60, 1.0/60.0)
+
+	if ticks := em.GetLoop().GetTickCount(); ticks < 59 || ticks > 61 {
+		t.Fatalf("ran %d logic steps in 60 frames, want 60", ticks)
+	}
+
+	transform, transformExists := ecs.Get[components.Transform2D](em, player)
+	if !transformExists {
+		t.Fatal("the player lost its Transform2D")
+	}
+	if transform.Position.X <= startX {
+		t.Fatalf("player x went from %v to %v while D was held, want it to move right", startX, transform.Position.X)
+	}
+}
+
+// The physics debug views are toggled by the InputSystem, the CollisionSystem only draws them when rendering
+func TestPhysicsDebugViewsToggleFromInput(t *testing.T) {
+	em, np, sm := newHeadlessManagers(t)
+
+	if err := sm.PushScene(filepath.Join(assetsDirectory, "scenes", "game_scene.json")); err != nil {
+		t.Fatalf("PushScene: %v", err)
+	}
+
+	renderer, rendererExists := em.GetPhysicsDebugRenderer()
+	if !rendererExists {
+		t.Fatal("the game scene has no physics debug renderer")
+	}
+	collisionSystem, isCollisionSystem := renderer.(*physicssystems.CollisionSystem)
+	if !isCollisionSystem {
+		t.Fatalf("physics debug renderer is a %T, want the CollisionSystem", renderer)
+	}
+
+	np.PressKey(raylib.KeyQ)
+	np.PressKey(raylib.KeyJ)
+	runFrames(t, em, np, sm, 1, 1.0/60.0)
+	np.ReleaseKey(raylib.KeyQ)
+	np.ReleaseKey(raylib.KeyJ)
+	runFrames(t, em, np, sm, 10, 1.0/60.0)
+
+	if !collisionSystem.ShouldRenderBroadPhase || !collisionSystem.ShouldRenderJoints {
+		t.Fatalf("broad phase %v, joints %v after pressing Q and J once, want both on", collisionSystem.ShouldRenderBroadPhase, collisionSystem.ShouldRenderJoints)
+	}
+}
+
+// The player is kept in the world bounds of the game scene, which is wider than the screen, instead of in the screen
+func TestGameScenePlayerStaysInWorldBounds(t *testing.T) {
+	em, np, sm := newHeadlessManagers(t)
+
+	if err := sm.PushScene(filepath.Join(assetsDirectory, "scenes", "game_scene.json")); err != nil {
+		t.Fatalf("PushScene: %v", err)
+	}
+
+	var player uint64
+	for eID := range ecs.Query1[components.Player](em) {
+		player = eID
+	}
+	if player == 0 {
+		t.Fatal("the game scene has no player")
+	}
+
+	np.PressKey(raylib.KeyD)
+	runFrames(t, em, np, sm, 600, 1.0/60.0)
+
+	transform, _ := ecs.Get[components.Transform2D](em, player)
+	if transform.Position.X <= float32(np.GetScreenWidth()) {
+		t.Fatalf("player stopped at x %v, want it past the edge of the screen", transform.Position.X)
+	}
+	if transform.Position.X > 2560 {
+		t.Fatalf("player left the world bounds at x %v", transform.Position.X)
+	}
+}
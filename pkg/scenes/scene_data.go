@@ -1,16 +1,88 @@
 package scenes
 
+import (
+	"encoding/json"
+
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/physics"
+)
+
 type SceneData struct {
-	SceneName   string          `json:"scene_name"`
+	SceneName string `json:"scene_name"`
+
+	// Systems the scene creates when it is initialized, see RegisterSystemFactory
+	Systems  []SystemData `json:"systems,omitempty"`
+	Parallel bool         `json:"parallel,omitempty"`
+
+	// PauseWorld stops the logic systems while the scene is on top, e.g. for a pause menu
+	PauseWorld  bool             `json:"pause_world,omitempty"`
+	KeyBindings []KeyBindingData `json:"key_bindings,omitempty"`
+
+	// CollisionLayers names the collision layers used by the colliders, the name at index i is the layer with bit i
+	CollisionLayers []string `json:"collision_layers,omitempty"`
+
+	// WorldBounds is the area the physics simulates, bodies are clamped to the screen without it
+	WorldBounds *WorldBoundsData `json:"world_bounds,omitempty"`
+
+	// RandomEntities is the number of boxes to spawn at random positions
+	RandomEntities int `json:"random_entities,omitempty"`
+
 	Entities    []EntityData    `json:"entities"`
 	Environment EnvironmentData `json:"environment"`
 }
 
+// SystemData declares a system by the name its factory was registered with
+type SystemData struct {
+	Name     string          `json:"name"`
+	Priority int             `json:"priority"`
+	Params   json.RawMessage `json:"params,omitempty"`
+}
+
+// KeyBindingData dispatches a scene event when a key is pressed, see events.NewSceneEvent
+type KeyBindingData struct {
+	Key       string `json:"key"`
+	Event     string `json:"event"`
+	ScenePath string `json:"scene_path,omitempty"`
+}
+
+// EntityData describes an entity in a scene file.
+// Components are keyed by the name they were registered with, see ecs.RegisterComponent.
+// An entity instantiated from a prefab lists only its overrides of the prefab components,
+// while Components replace whole components.
+// Parent is the ID of the parent entity in the same file, its LocalTransform2D is then relative to the parent.
+// Joints connect the entity to other entities in the same file, or to the world.
 type EntityData struct {
-	ID         uint64                 `json:"id"`
-	Type       string                 `json:"type"`
-	Position   PositionData           `json:"position"`
-	Components map[string]interface{} `json:"components"`
+	ID         uint64                     `json:"id"`
+	Type       string                     `json:"type,omitempty"`
+	Position   PositionData               `json:"position"`
+	Parent     uint64                     `json:"parent,omitempty"`
+	Prefab     string                     `json:"prefab,omitempty"`
+	Overrides  map[string]json.RawMessage `json:"overrides,omitempty"`
+	Components map[string]json.RawMessage `json:"components,omitempty"`
+	Joints     []JointData                `json:"joints,omitempty"`
+}
+
+// JointData describes a joint of an entity, see physicscomponents.Joint.
+// Connected is the ID of an entity in the same file, or 0 to anchor the joint in the world at ConnectedAnchor.
+// Length defaults to the distance between the anchors when the scene is loaded.
+type JointData struct {
+	Type            string          `json:"type"`
+	Connected       uint64          `json:"connected,omitempty"`
+	Anchor          ecs.Vector2Data `json:"anchor"`
+	ConnectedAnchor ecs.Vector2Data `json:"connected_anchor"`
+	Length          *float32        `json:"length,omitempty"`
+	Frequency       float32         `json:"frequency,omitempty"`
+	DampingRatio    float32         `json:"damping_ratio,omitempty"`
+}
+
+// WorldBoundsData describes the world bounds of a scene, see physics.WorldBounds.
+// Mode is finite, unbounded or wrap and Policy is clamp, destroy or event, clamp if it is left out.
+// Unbounded worlds may leave out the rectangle.
+type WorldBoundsData struct {
+	Mode     string          `json:"mode"`
+	Position ecs.Vector2Data `json:"position"`
+	Size     ecs.Vector2Data `json:"size"`
+	Policy   string          `json:"policy,omitempty"`
 }
 
 type PositionData struct {
@@ -22,3 +94,26 @@ type EnvironmentData struct {
 	BackgroundColor string `json:"background_color"`
 	Music           string `json:"music"`
 }
+
+// WorldBounds turns the data into world bounds, checking the mode and policy are known
+func (wbd *WorldBoundsData) WorldBounds() (physics.WorldBounds, error) {
+	bounds := physics.WorldBounds{
+		Mode: physics.WorldBoundsMode(wbd.Mode),
+		Rectangle: physics.Rectangle{
+			Position: wbd.Position.Vector2(),
+			Width:    wbd.Size.X,
+			Height:   wbd.Size.Y,
+		},
+		Policy: physics.OutOfBoundsPolicy(wbd.Policy),
+	}
+
+	if bounds.Policy == "" {
+		bounds.Policy = physics.ClampPolicy
+	}
+
+	if err := bounds.Validate(); err != nil {
+		return physics.WorldBounds{}, err
+	}
+
+	return bounds, nil
+}
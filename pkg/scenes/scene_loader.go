@@ -2,9 +2,33 @@ package scenes
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"sort"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
 )
 
+// SceneDataError describes a component in the scene data that could not be loaded
+type SceneDataError struct {
+	EntityIndex int
+	Path        string
+	Err         error
+}
+
+func (sde *SceneDataError) Error() string {
+	return fmt.Sprintf("entity %d: %s: %v", sde.EntityIndex, sde.Path, sde.Err)
+}
+
+func (sde *SceneDataError) Unwrap() error {
+	return sde.Err
+}
+
 func LoadSceneData(sceneFilePath string) (*SceneData, error) {
 
 	data, err := os.ReadFile(sceneFilePath)
@@ -21,3 +45,171 @@ func LoadSceneData(sceneFilePath string) (*SceneData, error) {
 	return &sceneData, nil
 
 }
+
+// ErrOverridesWithoutPrefab is returned for entities with overrides but no prefab to apply them to
+var ErrOverridesWithoutPrefab = errors.New("overrides without a prefab")
+
+// createEntities creates an entity for every entity in the scene data and adds its components
+// through the component registry. Components that fail to load are skipped and returned as SceneDataErrors.
+// Entities whose prefab can't be resolved are not created at all.
+// Every entity gets a SceneID with its ID from the file, or the next free ID if it has none.
+// Entities instantiated from a prefab also get a PrefabInstance so saving keeps them linked to the prefab,
+// and entities with a parent are attached to it once all entities are created.
+func createEntities(em *ecs.ECSManager, sd *SceneData) ([]*ecs.Entity, []error) {
+
+	entities := make([]*ecs.Entity, 0, len(sd.Entities))
+	errs := []error{}
+
+	nextSceneID := uint64(1)
+	for _, entityData := range sd.Entities {
+		nextSceneID = max(nextSceneID, entityData.ID+1)
+	}
+
+	// The entity created for every entity in the scene data, nil if it was skipped
+	created := make([]*ecs.Entity, len(sd.Entities))
+	sceneEntities := make(map[uint64]uint64, len(sd.Entities))
+
+	for i, entityData := range sd.Entities {
+
+		comps := entityData.Components
+		compSources := map[string]string{}
+
+		// Start from the prefab components with the overrides applied
+		if entityData.Prefab != "" {
+			prefabComps, err := em.GetPrefabsManager().ResolveComponents(entityData.Prefab, entityData.Overrides)
+			if err != nil {
+				errs = append(errs, &SceneDataError{EntityIndex: i, Path: fmt.Sprintf("entities[%d].prefab", i), Err: err})
+				continue
+			}
+
+			for compName := range prefabComps {
+				compSources[compName] = "prefab"
+			}
+			for compName := range entityData.Overrides {
+				compSources[compName] = "overrides"
+			}
+
+			// Components listed in full replace the prefab components
+			for compName, data := range entityData.Components {
+				prefabComps[compName] = data
+				compSources[compName] = "components"
+			}
+
+			comps = prefabComps
+		} else if len(entityData.Overrides) > 0 {
+			errs = append(errs, &SceneDataError{EntityIndex: i, Path: fmt.Sprintf("entities[%d].overrides", i), Err: ErrOverridesWithoutPrefab})
+		}
+
+		// Create a new entity
+		entity := em.CreateEntity()
+		entities = append(entities, entity)
+		created[i] = entity
+
+		sceneID := entityData.ID
+		if sceneID == 0 {
+			sceneID = nextSceneID
+			nextSceneID++
+		}
+
+		ecs.Add(em, entity.ID, &components.SceneID{ID: sceneID})
+		sceneEntities[sceneID] = entity.ID
+
+		if entityData.Prefab != "" {
+			ecs.Add(em, entity.ID, &components.PrefabInstance{Name: entityData.Prefab})
+		}
+
+		// Add the components in name order so loading is deterministic
+		compNames := make([]string, 0, len(comps))
+		for compName := range comps {
+			compNames = append(compNames, compName)
+		}
+		sort.Strings(compNames)
+
+		for _, compName := range compNames {
+			err := em.AddComponentFromJSON(entity.ID, compName, comps[compName])
+			if err != nil {
+				errs = append(errs, newSceneDataError(i, compSources[compName], compName, err))
+			}
+		}
+	}
+
+	// Attach the children once every entity exists, so parents can be declared after their children
+	for i, entityData := range sd.Entities {
+		if entityData.Parent == 0 || created[i] == nil {
+			continue
+		}
+
+		parent, parentExists := sceneEntities[entityData.Parent]
+		if !parentExists {
+			errs = append(errs, &SceneDataError{EntityIndex: i, Path: fmt.Sprintf("entities[%d].parent", i), Err: fmt.Errorf("no entity with id %d", entityData.Parent)})
+			continue
+		}
+
+		if err := em.SetParent(created[i].ID, parent); err != nil {
+			errs = append(errs, &SceneDataError{EntityIndex: i, Path: fmt.Sprintf("entities[%d].parent", i), Err: err})
+		}
+	}
+
+	// Connect the joints once every entity exists, so they can reference entities declared later
+	for i, entityData := range sd.Entities {
+		if created[i] == nil {
+			continue
+		}
+
+		for j, jointData := range entityData.Joints {
+			path := fmt.Sprintf("entities[%d].joints[%d]", i, j)
+
+			joint := physicscomponents.Joint{
+				Type:            physicscomponents.JointType(jointData.Type),
+				Anchor:          jointData.Anchor.Vector2(),
+				ConnectedAnchor: jointData.ConnectedAnchor.Vector2(),
+				Frequency:       jointData.Frequency,
+				DampingRatio:    jointData.DampingRatio,
+			}
+
+			if jointData.Connected != 0 {
+				connected, connectedExists := sceneEntities[jointData.Connected]
+				if !connectedExists {
+					errs = append(errs, &SceneDataError{EntityIndex: i, Path: path + ".connected", Err: fmt.Errorf("no entity with id %d", jointData.Connected)})
+					continue
+				}
+				joint.Connected = connected
+			}
+
+			// Without a length the joint keeps the anchors as far apart as they start
+			if jointData.Length != nil {
+				joint.Length = *jointData.Length
+			} else if anchor, connectedAnchor, anchorsExist := em.GetJointAnchors(created[i].ID, &joint); anchorsExist {
+				joint.Length = raylib.Vector2Distance(anchor, connectedAnchor)
+			}
+
+			if err := em.AddJoint(created[i].ID, joint); err != nil {
+				errs = append(errs, &SceneDataError{EntityIndex: i, Path: path, Err: err})
+			}
+		}
+	}
+
+	return entities, errs
+}
+
+// newSceneDataError wraps a component error with the entity index and the JSON path of the failing value.
+// source is the section of the entity the component came from, components if empty.
+func newSceneDataError(entityIndex int, source string, compName string, err error) *SceneDataError {
+	if source == "" {
+		source = "components"
+	}
+
+	path := fmt.Sprintf("entities[%d].%s.%s", entityIndex, source, compName)
+
+	// Point at the field that had the wrong type
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		path += "." + typeErr.Field
+	}
+
+	return &SceneDataError{
+		EntityIndex: entityIndex,
+		Path:        path,
+		Err:         err,
+	}
+}
@@ -0,0 +1,144 @@
+package scenes
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/physics"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+)
+
+// An entity whose prefab can't be resolved is not created, and overrides without a prefab are reported
+func TestCreateEntitiesSkipsBrokenEntities(t *testing.T) {
+	em, _, _ := newHeadlessManagers(t)
+
+	transform := json.RawMessage(`{"position": {"x": 1, "y": 2}, "scale": {"x": 1, "y": 1}}`)
+
+	sd := &SceneData{
+		Entities: []EntityData{
+			{ID: 1, Prefab: "no_such_prefab"},
+			{ID: 2, Components: map[string]json.RawMessage{"Transform2D": transform}, Overrides: map[string]json.RawMessage{"Transform2D": transform}},
+			{ID: 3, Parent: 1, Components: map[string]json.RawMessage{"Transform2D": transform}},
+		},
+	}
+
+	entities, errs := createEntities(em, sd)
+
+	if len(entities) != 2 || em.GetEntitiesManager().GetEntityCount() != 2 {
+		t.Fatalf("created %d entities, %d alive, want 2", len(entities), em.GetEntitiesManager().GetEntityCount())
+	}
+	for _, entity := range entities {
+		if sceneID, _ := ecs.Get[components.SceneID](em, entity.ID); sceneID.ID == 1 {
+			t.Fatal("the entity with the unknown prefab was created")
+		}
+	}
+
+	paths := map[string]error{}
+	for _, err := range errs {
+		var sceneDataErr *SceneDataError
+		if !errors.As(err, &sceneDataErr) {
+			t.Fatalf("error %v is not a SceneDataError", err)
+		}
+		paths[sceneDataErr.Path] = sceneDataErr.Err
+	}
+
+	if err := paths["entities[0].prefab"]; !errors.Is(err, ecs.ErrUnknownPrefab) {
+		t.Errorf("entities[0].prefab: %v, want %v", err, ecs.ErrUnknownPrefab)
+	}
+	if err := paths["entities[1].overrides"]; !errors.Is(err, ErrOverridesWithoutPrefab) {
+		t.Errorf("entities[1].overrides: %v, want %v", err, ErrOverridesWithoutPrefab)
+	}
+	if _, parentReported := paths["entities[2].parent"]; !parentReported {
+		t.Errorf("the parent of entities[2] that was not created is not reported, errors: %v", errs)
+	}
+	if len(errs) != 3 {
+		t.Errorf("%d errors, want 3: %v", len(errs), errs)
+	}
+}
+
+// Colliders naming an undeclared layer are rejected with a SceneDataError instead of loading with a partial mask
+func TestCreateEntitiesRejectsUnknownCollisionLayers(t *testing.T) {
+	em, _, _ := newHeadlessManagers(t)
+
+	if err := em.GetCollisionLayers().Set([]string{"default", "player"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	sd := &SceneData{
+		Entities: []EntityData{
+			{ID: 1, Components: map[string]json.RawMessage{"BoxCollider": json.RawMessage(`{"size": {"x": 1, "y": 1}, "layers": ["player", "props"]}`)}},
+		},
+	}
+
+	entities, errs := createEntities(em, sd)
+
+	var sceneDataErr *SceneDataError
+	if len(errs) != 1 || !errors.As(errs[0], &sceneDataErr) || !errors.Is(errs[0], physicscomponents.ErrUnknownCollisionLayer) {
+		t.Fatalf("errors = %v, want a SceneDataError for the unknown layer", errs)
+	}
+	if sceneDataErr.Path != "entities[0].components.BoxCollider" {
+		t.Errorf("path = %s, want entities[0].components.BoxCollider", sceneDataErr.Path)
+	}
+	if ecs.Has[physicscomponents.BoxCollider](em, entities[0].ID) {
+		t.Error("the collider with the unknown layer was added")
+	}
+}
+
+// A polygon collider that is not convex is reported instead of reaching the narrow phase
+func TestCreateEntitiesRejectsInvalidPolygons(t *testing.T) {
+	em, _, _ := newHeadlessManagers(t)
+
+	sd := &SceneData{
+		Entities: []EntityData{
+			{ID: 1, Components: map[string]json.RawMessage{"PolygonCollider": json.RawMessage(`{"points": [{"x": 0, "y": 0}, {"x": 10, "y": 5}, {"x": 0, "y": 10}, {"x": 3, "y": 5}]}`)}},
+			{ID: 2, Components: map[string]json.RawMessage{"PolygonCollider": json.RawMessage(`{"points": [{"x": 0, "y": 0}, {"x": 10, "y": 0}]}`)}},
+			{ID: 3, Components: map[string]json.RawMessage{"PolygonCollider": json.RawMessage(`{"points": [{"x": 0, "y": 0}, {"x": 10, "y": 0}, {"x": 0, "y": 10}]}`)}},
+		},
+	}
+
+	entities, errs := createEntities(em, sd)
+
+	if len(errs) != 2 || !errors.Is(errs[0], physics.ErrConcavePolygon) || !errors.Is(errs[1], physics.ErrTooFewPoints) {
+		t.Fatalf("errors = %v, want the concave and the two point polygons", errs)
+	}
+
+	var sceneDataErr *SceneDataError
+	if !errors.As(errs[0], &sceneDataErr) || sceneDataErr.Path != "entities[0].components.PolygonCollider" {
+		t.Errorf("error = %v, want a SceneDataError at entities[0].components.PolygonCollider", errs[0])
+	}
+	if ecs.Has[physicscomponents.PolygonCollider](em, entities[0].ID) || ecs.Has[physicscomponents.PolygonCollider](em, entities[1].ID) {
+		t.Error("an invalid polygon collider was added")
+	}
+	if !ecs.Has[physicscomponents.PolygonCollider](em, entities[2].ID) {
+		t.Error("the valid triangle collider was not added")
+	}
+}
+
+// Every world has its own collision layers, and changing the scene forgets the layers of the previous one
+func TestCollisionLayersBelongToTheScene(t *testing.T) {
+	em, _, sm := newHeadlessManagers(t)
+	other, _, _ := newHeadlessManagers(t)
+
+	if err := sm.PushScene(filepath.Join(assetsDirectory, "scenes", "game_scene.json")); err != nil {
+		t.Fatalf("PushScene: %v", err)
+	}
+
+	if _, layerExists := em.GetCollisionLayers().GetLayer("player"); !layerExists {
+		t.Fatal("the layers of the game scene were not declared")
+	}
+	if _, layerExists := other.GetCollisionLayers().GetLayer("player"); layerExists {
+		t.Fatal("the layers of the game scene leaked into another world")
+	}
+
+	if err := sm.ChangeScene(filepath.Join(assetsDirectory, "scenes", "main_menu.json")); err != nil {
+		t.Fatalf("ChangeScene: %v", err)
+	}
+
+	if _, layerExists := em.GetCollisionLayers().GetLayer("player"); layerExists {
+		t.Fatal("the layers of the game scene were kept after changing the scene")
+	}
+}
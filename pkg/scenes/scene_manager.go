@@ -8,12 +8,27 @@ import (
 	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
+// sceneOperation is a scene change requested while updating or rendering,
+// applied by ApplyPendingSceneChange once no scene or system is running
+type sceneOperation struct {
+	kind      sceneOperationKind
+	scenePath string
+}
+
+type sceneOperationKind int
+
+const (
+	changeSceneOperation sceneOperationKind = iota
+	pushSceneOperation
+	popSceneOperation
+)
+
 type SceneManager struct {
-	scenes           []Scene
-	ecsManager       *ecs.ECSManager
-	shouldExitGame   bool
-	pendingScenePath string
-	pendingChange    bool
+	scenes         []Scene
+	ecsManager     *ecs.ECSManager
+	shouldExitGame bool
+	pendingOps     []sceneOperation
+	subscriptions  []events.SubscriptionID
 }
 
 func NewSceneManager(ecsManager *ecs.ECSManager) *SceneManager {
@@ -23,8 +38,14 @@ func NewSceneManager(ecsManager *ecs.ECSManager) *SceneManager {
 		shouldExitGame: false,
 	}
 
-	sm.ecsManager.GetEventsManager().Subscribe("change_scene", sm.OnChangeScene)
-	sm.ecsManager.GetEventsManager().Subscribe("exit_game", sm.OnExitGame)
+	eventsManager := sm.ecsManager.GetEventsManager()
+	sm.subscriptions = []events.SubscriptionID{
+		events.Subscribe(eventsManager, sm.OnChangeScene),
+		events.Subscribe(eventsManager, sm.OnPushScene),
+		events.Subscribe(eventsManager, sm.OnPopScene),
+		events.Subscribe(eventsManager, sm.OnExitGame),
+		events.Subscribe(eventsManager, sm.OnSaveScene),
+	}
 
 	return sm
 
@@ -38,17 +59,8 @@ func (sm *SceneManager) PushScene(sceneFilePath string) error {
 		return err
 	}
 
-	var newScene Scene
-	switch sceneData.SceneName {
-	case "MainMenu":
-		newScene = NewMainMenuScene(sm, sm.ecsManager, sceneData)
-	case "Game":
-		newScene = NewGameScene(sm, sm.ecsManager, sceneData)
-	case "Pause":
-		newScene = NewPauseScene(sm, sm.ecsManager, sceneData)
-	default:
-		return fmt.Errorf("scene manager: unkown scene %s", sceneData.SceneName)
-	}
+	// Every scene is described by its scene data
+	newScene := NewGenericScene(sm, sm.ecsManager, sceneData)
 
 	if len(sm.scenes) > 0 {
 		// Optional: Pause the current scene
@@ -90,6 +102,9 @@ func (sm *SceneManager) ChangeScene(sceneFilePath string) error {
 
 	sm.scenes = []Scene{}
 
+	// The collision layers belong to the scene that declared them
+	sm.ecsManager.GetCollisionLayers().Reset()
+
 	// Load and initialize the new scene
 	return sm.PushScene(sceneFilePath)
 }
@@ -102,9 +117,54 @@ func (sm *SceneManager) GetCurrentScene() Scene {
 	return sm.scenes[len(sm.scenes)-1]
 }
 
+// SetCurrentScene requests a scene change that is applied by ApplyPendingSceneChange
 func (sm *SceneManager) SetCurrentScene(sceneFilePath string) error {
-	sm.pendingScenePath = sceneFilePath
-	sm.pendingChange = true
+	sm.pendingOps = append(sm.pendingOps, sceneOperation{kind: changeSceneOperation, scenePath: sceneFilePath})
+	return nil
+}
+
+// SaveScene writes the world of the scene at the bottom of the stack to a scene file,
+// so saving while paused still saves the game scene. The entities of the scenes pushed on top of it,
+// e.g. the pause menu, are left out. Everything else was created by the base scene or while it ran.
+func (sm *SceneManager) SaveScene(sceneFilePath string) error {
+	if len(sm.scenes) == 0 {
+		return fmt.Errorf("scene manager: no scene to save")
+	}
+
+	baseSceneData := sm.scenes[0].GetSceneData()
+
+	overlayEntities := make(map[uint64]bool)
+	for _, scene := range sm.scenes[1:] {
+		for _, entity := range scene.GetEntities() {
+			overlayEntities[entity.ID] = true
+		}
+	}
+
+	entities := []uint64{}
+	for _, entity := range sm.ecsManager.GetAllEntities() {
+		if !overlayEntities[entity.ID] {
+			entities = append(entities, entity.ID)
+		}
+	}
+
+	worldData, err := BuildSceneData(sm.ecsManager, entities, baseSceneData.SceneName, baseSceneData.Environment)
+	if err != nil {
+		return fmt.Errorf("scene manager: failed to build scene data: %w", err)
+	}
+
+	// Keep the systems and settings of the scene, but replace its entities with the world.
+	// Randomly spawned entities are part of the world now, so they aren't spawned again.
+	sceneData := *baseSceneData
+	sceneData.Entities = worldData.Entities
+	sceneData.RandomEntities = 0
+
+	err = SaveSceneData(sceneFilePath, &sceneData)
+	if err != nil {
+		return fmt.Errorf("scene manager: failed to save scene: %w", err)
+	}
+
+	utils.InfoLogger.Printf("Saved scene %s to %s\n", sceneData.SceneName, sceneFilePath)
+
 	return nil
 }
 
@@ -117,21 +177,32 @@ func (sm *SceneManager) ExitGame() {
 }
 
 func (sm *SceneManager) ShouldChangeScene() bool {
-	return sm.pendingChange
+	return len(sm.pendingOps) > 0
 }
 
+// ApplyPendingSceneChange applies the scene changes requested through events or SetCurrentScene, in order.
+// It must be called outside of any scene or system update, e.g. from the main loop.
 func (sm *SceneManager) ApplyPendingSceneChange() error {
-	if sm.pendingChange {
+	pendingOps := sm.pendingOps
+	sm.pendingOps = nil
+
+	for _, op := range pendingOps {
+
+		var err error
+		switch op.kind {
+		case changeSceneOperation:
+			err = sm.ChangeScene(op.scenePath)
+		case pushSceneOperation:
+			err = sm.PushScene(op.scenePath)
+		case popSceneOperation:
+			err = sm.PopScene()
+		}
 
-		err := sm.ChangeScene(sm.pendingScenePath)
 		if err != nil {
 			return err
 		}
-
-		sm.pendingChange = false
-		sm.pendingScenePath = ""
-
 	}
+
 	return nil
 }
 
@@ -150,27 +221,43 @@ func (sm *SceneManager) Render() {
 	}
 }
 
-func (sm *SceneManager) OnChangeScene(event events.Event) {
+func (sm *SceneManager) OnChangeScene(e events.SceneChangeEvent) {
+
+	utils.InfoLogger.Printf("SceneManager: OnChangeScene: changing scene to %s\n", e.ScenePath)
 
-	utils.InfoLogger.Println("SceneManager: OnChangeScene: event received")
+	sm.SetCurrentScene(e.ScenePath)
+}
 
-	switch e := event.(type) {
-	case events.SceneChangeEvent:
+func (sm *SceneManager) OnPushScene(e events.ScenePushEvent) {
+	sm.pendingOps = append(sm.pendingOps, sceneOperation{kind: pushSceneOperation, scenePath: e.ScenePath})
+}
 
-		utils.InfoLogger.Printf("SceneManager: OnChangeScene: changing scene to %s\n", e.ScenePath)
+func (sm *SceneManager) OnPopScene(e events.ScenePopEvent) {
+	sm.pendingOps = append(sm.pendingOps, sceneOperation{kind: popSceneOperation})
+}
 
-		err := sm.ChangeScene(e.ScenePath)
-		if err != nil {
-			utils.ErrorLogger.Println("Failed to change scene: ", err)
-		}
+func (sm *SceneManager) OnExitGame(e events.ExitGameEvent) {
+	sm.shouldExitGame = e.ShouldExitGame
+}
 
-	default:
-		utils.ErrorLogger.Println("SceneManager: OnChangeScene: unknown event type")
+func (sm *SceneManager) OnSaveScene(e events.SaveSceneEvent) {
+	err := sm.SaveScene(e.ScenePath)
+	if err != nil {
+		utils.ErrorLogger.Println("Failed to save scene: ", err)
 	}
 }
 
-func (sm *SceneManager) OnExitGame(event events.Event) {
-
-	sm.shouldExitGame = event.(events.ExitGameEvent).ShouldExitGame
+// Close cleans up every scene and unsubscribes the SceneManager from the scene events
+func (sm *SceneManager) Close() {
+	for i := len(sm.scenes) - 1; i >= 0; i-- {
+		sm.scenes[i].Cleanup()
+	}
+	sm.scenes = []Scene{}
+	sm.pendingOps = nil
+	sm.ecsManager.GetCollisionLayers().Reset()
 
+	for _, id := range sm.subscriptions {
+		sm.ecsManager.GetEventsManager().Unsubscribe(id)
+	}
+	sm.subscriptions = nil
 }
@@ -0,0 +1,171 @@
+package scenes
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	physicscomponents "github.com/webbelito/Fenrir/pkg/physics/components"
+	"github.com/webbelito/Fenrir/pkg/utils"
+)
+
+// BuildSceneData captures the given entities that have at least one registered component.
+// Entities keep the ID from their SceneID. Entities without one, or with an ID already taken,
+// are given the next free ID and a SceneID so they keep it the next time the scene is saved.
+// Entities instantiated from a prefab are saved as the prefab and their overrides,
+// and children reference their parent by its ID.
+func BuildSceneData(em *ecs.ECSManager, entities []uint64, sceneName string, env EnvironmentData) (*SceneData, error) {
+
+	entities = slices.Clone(entities)
+	sceneIDs := make(map[uint64]uint64, len(entities))
+	for _, eID := range entities {
+		if sceneID, sceneIDExists := ecs.Get[components.SceneID](em, eID); sceneIDExists {
+			sceneIDs[eID] = sceneID.ID
+		}
+	}
+
+	// Entities with a SceneID come first in SceneID order, so the first of two entities with the same ID keeps it.
+	// The others follow by index and generation, so new IDs are handed out the same way every save.
+	slices.SortFunc(entities, func(a uint64, b uint64) int {
+		sceneIDA, aExists := sceneIDs[a]
+		sceneIDB, bExists := sceneIDs[b]
+		if aExists != bExists {
+			if aExists {
+				return -1
+			}
+			return 1
+		}
+
+		return cmp.Or(
+			cmp.Compare(sceneIDA, sceneIDB),
+			cmp.Compare(ecs.EntityIndex(a), ecs.EntityIndex(b)),
+			cmp.Compare(ecs.EntityGeneration(a), ecs.EntityGeneration(b)),
+		)
+	})
+
+	// Find the IDs already in use
+	nextSceneID := uint64(1)
+	for _, sceneID := range sceneIDs {
+		nextSceneID = max(nextSceneID, sceneID+1)
+	}
+
+	usedSceneIDs := make(map[uint64]bool, len(entities))
+	savedSceneIDs := make(map[uint64]uint64, len(entities))
+	savedEntities := make([]uint64, 0, len(entities))
+	entitiesData := make([]EntityData, 0, len(entities))
+
+	for _, eID := range entities {
+
+		comps, err := em.EncodeComponents(eID)
+		if err != nil {
+			return nil, fmt.Errorf("entity %d: %w", eID, err)
+		}
+
+		// Skip entities with nothing to save
+		if len(comps) == 0 {
+			continue
+		}
+
+		sceneID, sceneIDExists := ecs.Get[components.SceneID](em, eID)
+		if !sceneIDExists || sceneID.ID == 0 || usedSceneIDs[sceneID.ID] {
+			sceneID = &components.SceneID{ID: nextSceneID}
+			nextSceneID++
+
+			ecs.Add(em, eID, sceneID)
+		}
+		usedSceneIDs[sceneID.ID] = true
+		savedSceneIDs[eID] = sceneID.ID
+
+		entityData := EntityData{
+			ID:         sceneID.ID,
+			Components: comps,
+		}
+
+		// Entities instantiated from a prefab only keep what differs from the prefab
+		prefab, overrides, err := em.EncodePrefabOverrides(eID)
+		if err != nil {
+			return nil, fmt.Errorf("entity %d: %w", eID, err)
+		}
+		if prefab != "" {
+			entityData.Prefab = prefab
+			entityData.Overrides = overrides
+			entityData.Components = nil
+		}
+
+		entitiesData = append(entitiesData, entityData)
+		savedEntities = append(savedEntities, eID)
+	}
+
+	// Reference parents by their scene ID now that every saved entity has one
+	for i, eID := range savedEntities {
+		parent, parentExists := em.GetParent(eID)
+		if !parentExists {
+			continue
+		}
+
+		parentSceneID, parentSaved := savedSceneIDs[parent]
+		if !parentSaved {
+			utils.WarnLogger.Printf("BuildSceneData: the parent of entity %d has nothing to save, the entity is saved as a root", eID)
+			continue
+		}
+
+		entitiesData[i].Parent = parentSceneID
+	}
+
+	// Reference the connected entities of the joints by their scene ID as well
+	for i, eID := range savedEntities {
+		joints, jointsExists := ecs.Get[physicscomponents.Joints](em, eID)
+		if !jointsExists {
+			continue
+		}
+
+		for _, joint := range joints.Entries {
+			jointData := JointData{
+				Type:            string(joint.Type),
+				Anchor:          ecs.NewVector2Data(joint.Anchor),
+				ConnectedAnchor: ecs.NewVector2Data(joint.ConnectedAnchor),
+				Length:          &joint.Length,
+				Frequency:       joint.Frequency,
+				DampingRatio:    joint.DampingRatio,
+			}
+
+			if joint.Connected != 0 {
+				connectedSceneID, connectedSaved := savedSceneIDs[joint.Connected]
+				if !connectedSaved {
+					utils.WarnLogger.Printf("BuildSceneData: entity %d is connected to entity %d which has nothing to save, the joint is not saved", eID, joint.Connected)
+					continue
+				}
+				jointData.Connected = connectedSceneID
+			}
+
+			entitiesData[i].Joints = append(entitiesData[i].Joints, jointData)
+		}
+	}
+
+	// Keep the file order stable between saves
+	sort.Slice(entitiesData, func(i int, j int) bool {
+		return entitiesData[i].ID < entitiesData[j].ID
+	})
+
+	return &SceneData{
+		SceneName:   sceneName,
+		Entities:    entitiesData,
+		Environment: env,
+	}, nil
+}
+
+// SaveSceneData writes the scene data to a file in the format read by LoadSceneData
+func SaveSceneData(sceneFilePath string, sceneData *SceneData) error {
+
+	data, err := json.MarshalIndent(sceneData, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(sceneFilePath, append(data, '\n'), 0644)
+}
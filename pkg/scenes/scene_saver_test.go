@@ -0,0 +1,84 @@
+package scenes
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// saveAndLoad saves the world of the scene manager and reads the file back
+func saveAndLoad(t *testing.T, sm *SceneManager, name string) *SceneData {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := sm.SaveScene(path); err != nil {
+		t.Fatalf("SaveScene: %v", err)
+	}
+
+	sceneData, err := LoadSceneData(path)
+	if err != nil {
+		t.Fatalf("LoadSceneData: %v", err)
+	}
+
+	return sceneData
+}
+
+// compareEntities fails if two saves differ in their entities, components, prefab overrides, joints or parents
+func compareEntities(t *testing.T, description string, want []EntityData, got []EntityData) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d entities, want %d", description, len(got), len(want))
+	}
+
+	for i := range want {
+		wantData, _ := json.Marshal(want[i])
+		gotData, _ := json.Marshal(got[i])
+		if string(gotData) != string(wantData) {
+			t.Fatalf("%s: entity %d differs\nwant: %s\ngot:  %s", description, want[i].ID, wantData, gotData)
+		}
+	}
+}
+
+// Saving the game scene and loading the file again must give back the same world,
+// and the pause menu on top of it must not be saved with it
+func TestSaveSceneRoundTrip(t *testing.T) {
+	_, _, sm := newHeadlessManagers(t)
+
+	if err := sm.PushScene(filepath.Join(assetsDirectory, "scenes", "game_scene.json")); err != nil {
+		t.Fatalf("PushScene: %v", err)
+	}
+	if err := sm.PushScene(filepath.Join(assetsDirectory, "scenes", "pause_scene.json")); err != nil {
+		t.Fatalf("PushScene: %v", err)
+	}
+	if len(sm.scenes[1].GetEntities()) == 0 {
+		t.Fatal("the pause scene has no entities to leave out")
+	}
+
+	paused := saveAndLoad(t, sm, "paused.json")
+
+	if err := sm.PopScene(); err != nil {
+		t.Fatalf("PopScene: %v", err)
+	}
+	compareEntities(t, "saved while paused", saveAndLoad(t, sm, "game.json").Entities, paused.Entities)
+
+	hasParent := false
+	for _, entityData := range paused.Entities {
+		hasParent = hasParent || entityData.Parent != 0
+	}
+	if !hasParent {
+		t.Fatal("the saved scene lost its hierarchy")
+	}
+
+	// Load the saved scene into a new world and save it again
+	_, _, reloadedSM := newHeadlessManagers(t)
+	reloadedPath := filepath.Join(t.TempDir(), "reloaded.json")
+	if err := SaveSceneData(reloadedPath, paused); err != nil {
+		t.Fatalf("SaveSceneData: %v", err)
+	}
+	if err := reloadedSM.PushScene(reloadedPath); err != nil {
+		t.Fatalf("PushScene: %v", err)
+	}
+
+	compareEntities(t, "reloaded", paused.Entities, saveAndLoad(t, reloadedSM, "reloaded_again.json").Entities)
+}
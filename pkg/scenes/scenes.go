@@ -10,6 +10,9 @@ type Scene interface {
 	Pause()
 	Resume()
 
+	GetSceneData() *SceneData
+
 	AddEntity(eID *ecs.Entity)
+	GetEntities() []*ecs.Entity
 	RemoveAllEntities()
 }
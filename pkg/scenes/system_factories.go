@@ -0,0 +1,204 @@
+package scenes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/editor"
+	"github.com/webbelito/Fenrir/pkg/physics"
+	physicssystems "github.com/webbelito/Fenrir/pkg/physics/systems"
+	"github.com/webbelito/Fenrir/pkg/resources"
+	"github.com/webbelito/Fenrir/pkg/systems"
+
+	raylib "github.com/gen2brain/raylib-go/raylib"
+)
+
+// SystemContext is passed to system factories when a scene creates its systems
+type SystemContext struct {
+	ECSManager       *ecs.ECSManager
+	ResourcesManager *resources.ResourcesManager
+
+	// WorldBounds of the scene, nil if it has none
+	WorldBounds *physics.WorldBounds
+
+	systems map[string]any
+}
+
+// GetSystem returns a system the scene already created, by the name it was declared with
+func (sc *SystemContext) GetSystem(name string) (any, bool) {
+	system, systemExists := sc.systems[name]
+	return system, systemExists
+}
+
+// SystemFactory creates a system from the priority and parameters declared in a scene file.
+// The returned system is added as a logic, render and/or UI render system depending on the interfaces it implements.
+type SystemFactory func(ctx *SystemContext, priority int, params json.RawMessage) (any, error)
+
+// factoryRegistry holds the registered system factories by name
+type factoryRegistry struct {
+	factories    map[string]SystemFactory
+	factoryMutex sync.RWMutex
+}
+
+var systemFactories = &factoryRegistry{factories: make(map[string]SystemFactory)}
+
+// RegisterSystemFactory registers a system factory under a name used in scene files
+func RegisterSystemFactory(name string, factory SystemFactory) {
+	systemFactories.factoryMutex.Lock()
+	defer systemFactories.factoryMutex.Unlock()
+
+	systemFactories.factories[name] = factory
+}
+
+// GetSystemFactory returns the system factory registered under a name
+func GetSystemFactory(name string) (SystemFactory, bool) {
+	systemFactories.factoryMutex.RLock()
+	defer systemFactories.factoryMutex.RUnlock()
+
+	factory, factoryExists := systemFactories.factories[name]
+	return factory, factoryExists
+}
+
+// DecodeSystemParams decodes the parameters of a system on top of its defaults
+func DecodeSystemParams[T any](params json.RawMessage, defaults T) (T, error) {
+	if len(bytes.TrimSpace(params)) == 0 {
+		return defaults, nil
+	}
+
+	err := json.Unmarshal(params, &defaults)
+	return defaults, err
+}
+
+// screenRectangle returns a rectangle covering the screen of the platform
+func screenRectangle(em *ecs.ECSManager) raylib.Rectangle {
+	return raylib.Rectangle{
+		X:      0,
+		Y:      0,
+		Width:  float32(em.GetPlatform().GetScreenWidth()),
+		Height: float32(em.GetPlatform().GetScreenHeight()),
+	}
+}
+
+// Parameters of the built-in systems
+
+type rigidBodySystemParams struct {
+	Gravity     ecs.Vector2Data `json:"gravity"`
+	MaxSubSteps int             `json:"max_sub_steps"`
+}
+
+type collisionSystemParams struct {
+	AABBMargin         float32 `json:"aabb_margin"`
+	VelocityIterations int     `json:"velocity_iterations"`
+	PositionIterations int     `json:"position_iterations"`
+
+	// A time_to_sleep of 0 keeps every body awake
+	SleepVelocity        float32 `json:"sleep_velocity"`
+	SleepAngularVelocity float32 `json:"sleep_angular_velocity"`
+	TimeToSleep          float32 `json:"time_to_sleep"`
+}
+
+func init() {
+
+	// * Core Systems
+
+	RegisterSystemFactory("EditorManager", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return editor.NewEditorManager(ctx.ECSManager, priority), nil
+	})
+
+	RegisterSystemFactory("InputSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		editorSystem, editorExists := ctx.GetSystem("EditorManager")
+		if !editorExists {
+			return nil, fmt.Errorf("InputSystem requires EditorManager to be declared before it")
+		}
+
+		return systems.NewInputSystem(ctx.ECSManager, editorSystem.(*editor.EditorManager), priority), nil
+	})
+
+	RegisterSystemFactory("MovementSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewMovementSystem(ctx.ECSManager, priority), nil
+	})
+
+	RegisterSystemFactory("TransformSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewTransformSystem(ctx.ECSManager, priority), nil
+	})
+
+	RegisterSystemFactory("RenderSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewRenderSystem(ctx.ECSManager, screenRectangle(ctx.ECSManager), ctx.ResourcesManager, priority), nil
+	})
+
+	RegisterSystemFactory("CameraSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewCameraSystem(ctx.ECSManager, priority), nil
+	})
+
+	RegisterSystemFactory("ParticleSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewParticleSystem(ctx.ECSManager, priority), nil
+	})
+
+	RegisterSystemFactory("ParticleRenderSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewParticleRenderSystem(ctx.ECSManager, priority), nil
+	})
+
+	RegisterSystemFactory("AnimationSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewAnimationSystem(ctx.ECSManager, priority), nil
+	})
+
+	RegisterSystemFactory("AudioSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewAudioSystem(ctx.ECSManager, ctx.ResourcesManager, priority), nil
+	})
+
+	RegisterSystemFactory("UISystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewUISystem(ctx.ECSManager, priority), nil
+	})
+
+	// The TriggerSystem only subscribes to the trigger events, it has no priority
+	RegisterSystemFactory("TriggerSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		return systems.NewTriggerSystem(ctx.ECSManager), nil
+	})
+
+	// * Physics Systems
+
+	RegisterSystemFactory("RigidBodySystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+
+		// Default gravity of 980 pixels per second
+		p, err := DecodeSystemParams(params, rigidBodySystemParams{
+			Gravity:     ecs.Vector2Data{X: 0, Y: 980},
+			MaxSubSteps: physicssystems.DefaultMaxSubSteps,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rigidBodySystem := physicssystems.NewRigidBodySystem(ctx.ECSManager, p.Gravity.Vector2(), priority)
+		rigidBodySystem.MaxSubSteps = p.MaxSubSteps
+		rigidBodySystem.WorldBounds = ctx.WorldBounds
+
+		return rigidBodySystem, nil
+	})
+
+	RegisterSystemFactory("CollisionSystem", func(ctx *SystemContext, priority int, params json.RawMessage) (any, error) {
+		p, err := DecodeSystemParams(params, collisionSystemParams{
+			AABBMargin:         physics.DefaultAABBMargin,
+			VelocityIterations: physicssystems.DefaultVelocityIterations,
+			PositionIterations: physicssystems.DefaultPositionIterations,
+
+			SleepVelocity:        physicssystems.DefaultSleepVelocity,
+			SleepAngularVelocity: physicssystems.DefaultSleepAngularVelocity,
+			TimeToSleep:          physicssystems.DefaultTimeToSleep,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		collisionSystem := physicssystems.NewCollisionSystem(ctx.ECSManager, p.AABBMargin, priority)
+		collisionSystem.VelocityIterations = p.VelocityIterations
+		collisionSystem.PositionIterations = p.PositionIterations
+		collisionSystem.SleepVelocity = p.SleepVelocity
+		collisionSystem.SleepAngularVelocity = p.SleepAngularVelocity
+		collisionSystem.TimeToSleep = p.TimeToSleep
+
+		return collisionSystem, nil
+	})
+}
@@ -20,18 +20,9 @@ func NewAnimationSystem(ecsM *ecs.ECSManager, p int) *AnimationSystem {
 }
 
 func (as *AnimationSystem) Update(dt float64) {
-	entities := as.ecsManager.GetComponentsManager().GetEntitiesWithComponents([]ecs.ComponentType{ecs.AnimationComponent})
+	for _, animation := range ecs.Query1[components.Animation](as.ecsManager) {
 
-	for _, entity := range entities {
-		animationComp, animationCompExists := as.ecsManager.GetComponent(entity, ecs.AnimationComponent)
-
-		if !animationCompExists {
-			continue
-		}
-
-		animation := animationComp.(*components.Animation)
-
-		if !animation.IsPlaying {
+		if !animation.IsPlaying || len(animation.Frames) == 0 {
 			continue
 		}
 
@@ -43,20 +34,22 @@ func (as *AnimationSystem) Update(dt float64) {
 				if animation.IsLooping {
 					animation.CurrentFrame = 0
 				} else {
+					animation.CurrentFrame = len(animation.Frames) - 1
 					animation.IsPlaying = false
 				}
 			}
 		}
+	}
 
-		// Update the sprite's source rectangle
-		spriteComp, spriteCompExists := as.ecsManager.GetComponent(entity, ecs.SpriteComponent)
+	// Update the sprite's source rectangle
+	for _, comps := range ecs.Query2[components.Animation, components.Sprite](as.ecsManager) {
+		animation := comps.A
+		sprite := comps.B
 
-		if !spriteCompExists {
+		if len(animation.Frames) == 0 {
 			continue
 		}
 
-		sprite := spriteComp.(*components.Sprite)
-
 		sprite.SourceRect = animation.Frames[animation.CurrentFrame]
 	}
 }
@@ -64,3 +57,13 @@ func (as *AnimationSystem) Update(dt float64) {
 func (as *AnimationSystem) GetPriority() int {
 	return as.priority
 }
+
+// GetReadComponents returns the component types the AnimationSystem reads
+func (as *AnimationSystem) GetReadComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{}
+}
+
+// GetWriteComponents returns the component types the AnimationSystem writes
+func (as *AnimationSystem) GetWriteComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.AnimationComponent, ecs.SpriteComponent}
+}
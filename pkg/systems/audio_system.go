@@ -4,24 +4,54 @@ import (
 	raylib "github.com/gen2brain/raylib-go/raylib"
 	"github.com/webbelito/Fenrir/pkg/components"
 	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/events"
 	"github.com/webbelito/Fenrir/pkg/resources"
+	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
 type AudioSystem struct {
 	ecsManager      *ecs.ECSManager
 	resourceManager *resources.ResourcesManager
+	failedSounds    map[string]bool
+	subscription    events.SubscriptionID
 	priority        int
 }
 
 func NewAudioSystem(ecsM *ecs.ECSManager, rm *resources.ResourcesManager, p int) *AudioSystem {
-	return &AudioSystem{
+	as := &AudioSystem{
 		ecsManager:      ecsM,
 		resourceManager: rm,
+		failedSounds:    make(map[string]bool),
 		priority:        p,
 	}
+
+	as.subscription = events.Subscribe(ecsM.GetEventsManager(), as.OnCollisionEnter)
+
+	return as
+}
+
+// OnCollisionEnter plays the audio sources of the colliding entities that play on collision
+func (as *AudioSystem) OnCollisionEnter(e events.CollisionEnterEvent) {
+	for _, eID := range []uint64{e.EntityA, e.EntityB} {
+		audio, audioExists := ecs.Get[components.AudioSource](as.ecsManager, eID)
+		if audioExists && audio.PlayOnCollision {
+			audio.ShouldPlay = true
+		}
+	}
+}
+
+// Close unsubscribes the AudioSystem from the collision events
+func (as *AudioSystem) Close() {
+	as.ecsManager.GetEventsManager().Unsubscribe(as.subscription)
 }
 
 func (as *AudioSystem) Update(dt float64) {
+
+	// Nothing can be played without an audio device, e.g. when running headless
+	if !as.ecsManager.GetPlatform().IsAudioReady() {
+		return
+	}
+
 	audioEntities := as.ecsManager.GetComponentsManager().GetEntitiesWithComponents([]ecs.ComponentType{ecs.AudioSourceComponent})
 	for _, entity := range audioEntities {
 		audioComp, audioCompExists := as.ecsManager.GetComponent(entity, ecs.AudioSourceComponent)
@@ -32,6 +62,24 @@ func (as *AudioSystem) Update(dt float64) {
 
 		audio := audioComp.(*components.AudioSource)
 
+		// Load the sound the first time the audio source is updated
+		if audio.Sound.FrameCount == 0 {
+
+			// Don't retry sounds that already failed to load
+			if as.failedSounds[audio.FilePath] {
+				continue
+			}
+
+			sound, err := as.resourceManager.LoadSound(audio.FilePath)
+			if err != nil {
+				utils.ErrorLogger.Printf("AudioSystem: Failed to load sound: %s", err)
+				as.failedSounds[audio.FilePath] = true
+				continue
+			}
+
+			audio.Sound = sound
+		}
+
 		if audio.ShouldPlay {
 			raylib.PlaySound(audio.Sound)
 			audio.ShouldPlay = false
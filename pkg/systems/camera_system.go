@@ -19,7 +19,7 @@ func NewCameraSystem(ecsM *ecs.ECSManager, p int) *CameraSystem {
 		camera: &components.Camera{
 			OwnerEntity: 0,
 			Target:      raylib.Vector2{X: 0, Y: 0},
-			Offset:      raylib.Vector2{X: float32(raylib.GetScreenWidth()) / 2, Y: float32(raylib.GetScreenHeight()) / 2},
+			Offset:      raylib.Vector2{X: float32(ecsM.GetPlatform().GetScreenWidth()) / 2, Y: float32(ecsM.GetPlatform().GetScreenHeight()) / 2},
 			Zoom:        1.0,
 		},
 		priority: p,
@@ -31,6 +31,12 @@ func (cs *CameraSystem) Update(dt float64) {
 		return
 	}
 
+	// Release the camera if its owner has been destroyed
+	if !cs.ecsManager.IsAlive(cs.camera.OwnerEntity) {
+		cs.camera.OwnerEntity = 0
+		return
+	}
+
 	transformComp, transformCompExists := cs.ecsManager.GetComponent(cs.camera.OwnerEntity, ecs.Transform2DComponent)
 	if !transformCompExists {
 		return
@@ -77,3 +83,13 @@ func (cs *CameraSystem) SetOwner(e uint64) {
 func (cs *CameraSystem) GetPriority() int {
 	return cs.priority
 }
+
+// GetReadComponents returns the component types the CameraSystem reads
+func (cs *CameraSystem) GetReadComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.Transform2DComponent}
+}
+
+// GetWriteComponents returns the component types the CameraSystem writes
+func (cs *CameraSystem) GetWriteComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{}
+}
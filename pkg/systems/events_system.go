@@ -3,11 +3,13 @@ package systems
 import (
 	"github.com/webbelito/Fenrir/pkg/ecs"
 	"github.com/webbelito/Fenrir/pkg/events"
+	"github.com/webbelito/Fenrir/pkg/utils"
 )
 
 type EventsListenerSystem struct {
 	ecsManager    *ecs.ECSManager
 	eventsManager *events.EventsManager
+	subscription  events.SubscriptionID
 	priority      int
 }
 
@@ -18,25 +20,30 @@ func NewEventsListenerSystem(ecsM *ecs.ECSManager, p int) *EventsListenerSystem
 		priority:      p,
 	}
 
-	els.eventsManager.Subscribe("button_clicked", els.OnButtonClick)
+	els.subscription = events.Subscribe(els.eventsManager, els.OnButtonClick)
 
 	return els
 
 }
 
-func (els *EventsListenerSystem) OnButtonClick(event events.Event) {
-
-	switch e := event.(type) {
-	case events.ButtonClickEvent:
-		switch e.ButtonText {
-		case "Start Game":
-			els.ecsManager.GetEventsManager().Dispatch("change_scene", events.SceneChangeEvent{ScenePath: "assets/scenes/game_scene.json"})
-		case "Options":
-			els.ecsManager.GetEventsManager().Dispatch("change_scene", events.SceneChangeEvent{ScenePath: "assets/scenes/options_scene.json"})
-		case "Exit":
-			els.ecsManager.GetEventsManager().Dispatch("exit_game", events.ExitGameEvent{ShouldExitGame: true})
-		}
+// OnButtonClick dispatches the scene event of the clicked button, buttons without one are ignored
+func (els *EventsListenerSystem) OnButtonClick(e events.ButtonClickEvent) {
+	if e.Event == "" {
+		return
 	}
+
+	event, eventExists := events.NewSceneEvent(e.Event, e.ScenePath)
+	if !eventExists {
+		utils.WarnLogger.Printf("EventsListenerSystem: unknown event %s on button %s", e.Event, e.ButtonText)
+		return
+	}
+
+	els.eventsManager.Dispatch(event)
+}
+
+// Close unsubscribes the EventsListenerSystem from the button clicks
+func (els *EventsListenerSystem) Close() {
+	els.eventsManager.Unsubscribe(els.subscription)
 }
 
 func (els *EventsListenerSystem) GetPriority() int {
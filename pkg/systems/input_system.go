@@ -46,8 +46,8 @@ func (is *InputSystem) Update(dt float64) {
 	// Handle rigid body spawner
 	is.handleRigidBodySpawner()
 
-	// Handle QuadTree rendering
-	is.handleQuadTreeRendering()
+	// Handle the physics debug views
+	is.handlePhysicsDebugInput()
 
 	// TODO: Change this to something proper
 	is.handlePlayerPlaySound()
@@ -55,24 +55,20 @@ func (is *InputSystem) Update(dt float64) {
 
 func (is *InputSystem) handlePlayerMovementInput() {
 
-	playerComps, playerCompsExists := is.componentsManager.Components[ecs.PlayerComponent]
+	playerEntities := is.componentsManager.GetEntitiesWithComponents([]ecs.ComponentType{ecs.PlayerComponent})
 
-	if !playerCompsExists {
+	if len(playerEntities) == 0 {
 		utils.ErrorLogger.Println("InputSystem: No player components found")
 		return
 	}
 
-	for entity := range playerComps {
-		rbComponent, rbCompExists := is.ecsManager.GetComponent(entity, ecs.RigidBodyComponent)
+	// Update the RigidBody of every player entity
+	is.componentsManager.Query([]ecs.ComponentType{ecs.PlayerComponent, ecs.RigidBodyComponent}, func(entity uint64, comps []ecs.Component) {
 
-		if !rbCompExists {
-			continue
-		}
-
-		rb, rbExists := rbComponent.(*physicscomponents.RigidBody)
+		rb, rbExists := comps[1].(*physicscomponents.RigidBody)
 
 		if !rbExists {
-			continue
+			return
 		}
 
 		// Define the movement force
@@ -81,30 +77,30 @@ func (is *InputSystem) handlePlayerMovementInput() {
 		// Initialize the movement vector
 		force := raylib.NewVector2(0, 0)
 
-		if raylib.IsKeyDown(raylib.KeyW) {
+		if is.ecsManager.GetInput().IsKeyDown(raylib.KeyW) {
 			force.Y = -movementForce
 		}
 
-		if raylib.IsKeyDown(raylib.KeyS) {
+		if is.ecsManager.GetInput().IsKeyDown(raylib.KeyS) {
 			force.Y = movementForce
 		}
 
-		if raylib.IsKeyDown(raylib.KeyA) {
+		if is.ecsManager.GetInput().IsKeyDown(raylib.KeyA) {
 			force.X = -movementForce
 		}
 
-		if raylib.IsKeyDown(raylib.KeyD) {
+		if is.ecsManager.GetInput().IsKeyDown(raylib.KeyD) {
 			force.X = movementForce
 		}
 
 		// Apply the movement force to the RigidBody's force
 		rb.Force = raylib.Vector2Add(rb.Force, force)
 
-	}
+	})
 }
 
 func (is *InputSystem) handleEditorInput() {
-	if raylib.IsKeyPressed(raylib.KeyF1) {
+	if is.ecsManager.GetInput().IsKeyPressed(raylib.KeyF1) {
 		utils.InfoLogger.Println("InputSystem: Toggling editor visibility")
 		is.editorManager.ToggleVisibility()
 	}
@@ -130,52 +126,77 @@ func (is *InputSystem) handleSpawnerInput() {
 		raylib.DarkGray,
 	}
 
-	if raylib.IsKeyPressed(raylib.KeySpace) {
+	platform := is.ecsManager.GetPlatform()
+
+	if is.ecsManager.GetInput().IsKeyPressed(raylib.KeySpace) {
 
-		// Create 500 entities with random positions, velocities, speeds and colors
+		// Queue the entities so they are created at the next sync point
+		commandBuffer := is.ecsManager.GetSystemCommandBuffer(is)
+
+		// Create 500 moving boxes with random positions, velocities, speeds and colors
 		for i := 0; i < 500; i++ {
 
 			// Select a random color from the colors slice
-			color := colors[raylib.GetRandomValue(0, int32(len(colors)-1))]
-
-			// Create an entity with a random position, velocity, speed and color
-			entity := is.ecsManager.CreateEntity()
-			is.ecsManager.AddComponent(entity.ID, ecs.Transform2DComponent, &components.Transform2D{Position: raylib.NewVector2(float32(raylib.GetRandomValue(0, int32(raylib.GetScreenWidth())-1)), float32(raylib.GetRandomValue(0, int32(raylib.GetScreenHeight())-1)))})
-			is.ecsManager.AddComponent(entity.ID, ecs.VelocityComponent, &components.Velocity{Vector: raylib.NewVector2(float32(raylib.GetRandomValue(-10, 10)), float32(raylib.GetRandomValue(-10, 10)))})
-			is.ecsManager.AddComponent(entity.ID, ecs.SpeedComponent, &components.Speed{Value: float32(raylib.GetRandomValue(50, 200))})
-			is.ecsManager.AddComponent(entity.ID, ecs.ColorComponent, &components.Color{Color: color})
+			color := colors[platform.GetRandomValue(0, int32(len(colors)-1))]
+
+			overrides, err := ecs.MakeOverrides(map[string]any{
+				"Transform2D": map[string]any{"position": ecs.Vector2Data{X: float32(platform.GetRandomValue(0, int32(platform.GetScreenWidth())-1)), Y: float32(platform.GetRandomValue(0, int32(platform.GetScreenHeight())-1))}},
+				"Velocity":    map[string]any{"vector": ecs.Vector2Data{X: float32(platform.GetRandomValue(-10, 10)), Y: float32(platform.GetRandomValue(-10, 10))}},
+				"Speed":       map[string]any{"value": platform.GetRandomValue(50, 200)},
+				"Color":       map[string]any{"color": ecs.NewColorData(color)},
+			})
+			if err != nil {
+				utils.ErrorLogger.Printf("InputSystem: %v", err)
+				return
+			}
+
+			if _, err := commandBuffer.Instantiate("moving_box", overrides); err != nil {
+				utils.ErrorLogger.Printf("InputSystem: failed to spawn moving_box: %v", err)
+				return
+			}
 		}
 	}
 }
 
 func (is *InputSystem) handleRigidBodySpawner() {
 
-	if raylib.IsKeyPressed(raylib.KeyR) {
+	platform := is.ecsManager.GetPlatform()
 
-		// Create a rigid body entity
-		rigidBodyEntity := is.ecsManager.CreateEntity()
+	if is.ecsManager.GetInput().IsKeyPressed(raylib.KeyR) {
 
-		// Add a rigid body component to the rigid body entity
-		is.ecsManager.AddComponent(rigidBodyEntity.ID, ecs.RigidBodyComponent, &physicscomponents.RigidBody{
-			Mass:         1,
-			Velocity:     raylib.NewVector2(0, 0),
-			Acceleration: raylib.NewVector2(0, 0),
-			Force:        raylib.NewVector2(0, 0),
-			IsKinematic:  false,
-			IsStatic:     false,
+		// Drop a physics box from a random position at the top of the screen
+		overrides, err := ecs.MakeOverrides(map[string]any{
+			"Transform2D": map[string]any{"position": ecs.Vector2Data{X: float32(platform.GetRandomValue(0, int32(platform.GetScreenWidth())-1)), Y: 0}},
 		})
+		if err != nil {
+			utils.ErrorLogger.Printf("InputSystem: %v", err)
+			return
+		}
 
+		if _, err := is.ecsManager.GetSystemCommandBuffer(is).Instantiate("physics_box", overrides); err != nil {
+			utils.ErrorLogger.Printf("InputSystem: failed to spawn physics_box: %v", err)
+		}
 	}
 }
 
-func (is *InputSystem) handleQuadTreeRendering() {
-	if raylib.IsKeyPressed(raylib.KeyF2) {
-		// TODO: Find a way to retrieve the collision system from the ECSManager
+// handlePhysicsDebugInput toggles the broad phase rendering with Q and the joint rendering with J
+func (is *InputSystem) handlePhysicsDebugInput() {
+	renderer, rendererExists := is.ecsManager.GetPhysicsDebugRenderer()
+	if !rendererExists {
+		return
+	}
+
+	if is.ecsManager.GetInput().IsKeyPressed(raylib.KeyQ) {
+		renderer.ToggleBroadPhaseRender()
+	}
+
+	if is.ecsManager.GetInput().IsKeyPressed(raylib.KeyJ) {
+		renderer.ToggleJointsRender()
 	}
 }
 
 func (is *InputSystem) handlePlayerPlaySound() {
-	if raylib.IsKeyPressed(raylib.KeyB) {
+	if is.ecsManager.GetInput().IsKeyPressed(raylib.KeyB) {
 
 		playerEntities := is.ecsManager.GetComponentsManager().GetEntitiesWithComponents([]ecs.ComponentType{ecs.AudioSourceComponent})
 		for _, entityID := range playerEntities {
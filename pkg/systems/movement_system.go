@@ -38,26 +38,11 @@ func (ms *MovementSystem) Update(dt float64) {
 
 func (ms *MovementSystem) MoveEntities(dt float64) {
 
-	// Get entities with a position, velocity, speed, component
-	transformComps, tCompsExist := ms.componentsManager.Components[ecs.Transform2DComponent]
-	veloComps, vCompsExists := ms.componentsManager.Components[ecs.VelocityComponent]
-	SpeedComps, sCompsExists := ms.componentsManager.Components[ecs.SpeedComponent]
-	PlayerComps := ms.componentsManager.Components[ecs.PlayerComponent]
-
-	if !tCompsExist || !vCompsExists || !sCompsExists {
-		return
-	}
-
 	// Update the position of all entities with a position, velocity and speed component
-	for entity, vel := range veloComps {
-		transform, tExists := transformComps[entity].(*components.Transform2D)
-		velocity, vExists := vel.(*components.Velocity)
-		speed, sExists := SpeedComps[entity].(*components.Speed)
-		_, pExists := PlayerComps[entity].(*components.Player)
-
-		if !tExists || !vExists || !sExists {
-			continue
-		}
+	for _, comps := range ecs.Query3[components.Transform2D, components.Velocity, components.Speed](ms.ecsManager) {
+		transform := comps.A
+		velocity := comps.B
+		speed := comps.C
 
 		// Normalize the velocity vector to ensure consistent movement speed
 		normalizedVelocity := raylib.Vector2Normalize(velocity.Vector)
@@ -65,22 +50,19 @@ func (ms *MovementSystem) MoveEntities(dt float64) {
 		// Calculate the new position based on the velocity and speed
 		deltaVelocity := raylib.Vector2Scale(normalizedVelocity, speed.Value*float32(dt))
 		transform.Position = raylib.Vector2Add(transform.Position, deltaVelocity)
-
-		// Clamp the player position to the screen bounds
-		if pExists {
-
-			// Define the screen bounds
-			screenWidth := float32(raylib.GetScreenWidth())
-			screenHeight := float32(raylib.GetScreenHeight())
-
-			// Clamp the position to the screen bounds
-			transform.Position.X = raylib.Clamp(transform.Position.X, 0, screenWidth-5)
-			transform.Position.Y = raylib.Clamp(transform.Position.Y, 0, screenHeight-5)
-
-		}
 	}
 }
 
 func (ms *MovementSystem) GetPriority() int {
 	return ms.priority
 }
+
+// GetReadComponents returns the component types the MovementSystem reads
+func (ms *MovementSystem) GetReadComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.VelocityComponent, ecs.SpeedComponent}
+}
+
+// GetWriteComponents returns the component types the MovementSystem writes
+func (ms *MovementSystem) GetWriteComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.Transform2DComponent}
+}
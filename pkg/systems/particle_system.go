@@ -38,7 +38,7 @@ func (ps *ParticleSystem) Update(dt float64) {
 
 			particle := &components.Particle{
 				Position:     raylib.Vector2{X: 500, Y: 500},
-				Velocity:     raylib.NewVector2(float32(raylib.GetRandomValue(-50, 50)), float32(raylib.GetRandomValue(-50, 50))),
+				Velocity:     raylib.NewVector2(float32(ps.ecsManager.GetPlatform().GetRandomValue(-50, 50)), float32(ps.ecsManager.GetPlatform().GetRandomValue(-50, 50))),
 				Acceleration: raylib.NewVector2(0, 0),
 				Color:        raylib.Brown,
 				Size:         5,
@@ -67,3 +67,23 @@ func (ps *ParticleSystem) Update(dt float64) {
 func (ps *ParticleSystem) GetPriority() int {
 	return ps.priority
 }
+
+// GetReadComponents returns the component types the ParticleSystem reads
+func (ps *ParticleSystem) GetReadComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{}
+}
+
+// GetWriteComponents returns the component types the ParticleSystem writes
+func (ps *ParticleSystem) GetWriteComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.ParticleEmitterComponent}
+}
+
+// GetReadResources returns the resources the ParticleSystem reads
+func (ps *ParticleSystem) GetReadResources() []ecs.Resource {
+	return []ecs.Resource{}
+}
+
+// GetWriteResources returns the resources the ParticleSystem writes, new particles get a random velocity
+func (ps *ParticleSystem) GetWriteResources() []ecs.Resource {
+	return []ecs.Resource{ecs.RandomResource}
+}
@@ -14,6 +14,7 @@ import (
 type RenderSystem struct {
 	ScreenCullingRect raylib.Rectangle
 	Entities          []EntityData
+	sprites           map[uint64]*components.Sprite
 	ecsManager        *ecs.ECSManager
 	entitiesManager   *ecs.EntitiesManager
 	componentsManager *ecs.ComponentsManager
@@ -35,6 +36,7 @@ func NewRenderSystem(ecsM *ecs.ECSManager, screenBounds raylib.Rectangle, rm *re
 	return &RenderSystem{
 		ScreenCullingRect: screenBounds,
 		Entities:          []EntityData{},
+		sprites:           make(map[uint64]*components.Sprite),
 		ecsManager:        ecsM,
 		entitiesManager:   ecsM.GetEntitiesManager(),
 		componentsManager: ecsM.GetComponentsManager(),
@@ -49,6 +51,11 @@ func (rs *RenderSystem) Render() {
 		return
 	}
 
+	if rs.cameraSystem == nil {
+		utils.ErrorLogger.Println("RenderSystem: CameraSystem is nil")
+		return
+	}
+
 	rs.RenderEntities()
 
 }
@@ -64,54 +71,43 @@ func (rs *RenderSystem) RenderEntities() {
 		Zoom:     camera.Zoom,
 	}
 
-	raylib.BeginMode2D(cam)
-
-	transformComps, transformCompsExists := rs.componentsManager.Components[ecs.Transform2DComponent]
-	colorComps, colorCompsExists := rs.componentsManager.Components[ecs.ColorComponent]
-	spriteComps := rs.componentsManager.Components[ecs.SpriteComponent]
-
-	if !transformCompsExists || !colorCompsExists {
-		return
+	// Follow the interpolated position of the owner so the camera doesn't jitter against it
+	if ownerTransform, ownerExists := ecs.Get[components.Transform2D](rs.ecsManager, camera.OwnerEntity); ownerExists {
+		cam.Target, _ = rs.ecsManager.GetInterpolatedTransform(camera.OwnerEntity, ownerTransform)
 	}
 
+	raylib.BeginMode2D(cam)
+
 	// Clear the entities slice while retaining capacity
 	rs.Entities = rs.Entities[:0]
 
-	// Collect entities that have Position , Color and Sprite components
-	entities := rs.componentsManager.GetEntitiesWithComponents([]ecs.ComponentType{
-		ecs.Transform2DComponent,
-		ecs.ColorComponent,
-	})
+	// Collect the sprites first, the entities without one are drawn as rectangles
+	clear(rs.sprites)
+	for entity, sprite := range ecs.Query1[components.Sprite](rs.ecsManager) {
+		rs.sprites[entity] = sprite
+	}
 
-	for _, entity := range entities {
-		transform, _ := transformComps[entity].(*components.Transform2D)
-		colorComp, _ := colorComps[entity].(*components.Color)
+	// Collect entities that have Position , Color and Sprite components
+	for entity, comps := range ecs.Query2[components.Transform2D, components.Color](rs.ecsManager) {
+		transform := comps.A
+		colorComp := comps.B
 
 		// Check if the entity has a Sprite component
-		spriteComp, spriteExists := spriteComps[entity].(*components.Sprite)
+		spriteComp := rs.sprites[entity]
+
+		// Interpolate between the last two logic steps
+		position, rotation := rs.ecsManager.GetInterpolatedTransform(entity, transform)
 
 		// Check if the entity is within the screen bounds
-		if !raylib.CheckCollisionPointRec(transform.Position, rs.ScreenCullingRect) {
+		if !raylib.CheckCollisionPointRec(position, rs.ScreenCullingRect) {
 			continue
 		}
 
-		// Add the entity to the slice if it does not have a sprite component
-		if !spriteExists {
-			rs.Entities = append(rs.Entities, EntityData{
-				ID:       entity,
-				Position: transform.Position,
-				Rotation: transform.Rotation,
-				Scale:    transform.Scale,
-				Color:    colorComp.Color,
-				Sprite:   nil,
-			})
-		}
-
 		// Add the entity to the slice
 		rs.Entities = append(rs.Entities, EntityData{
 			ID:       entity,
-			Position: transform.Position,
-			Rotation: transform.Rotation,
+			Position: position,
+			Rotation: rotation,
 			Scale:    transform.Scale,
 			Color:    colorComp.Color,
 			Sprite:   spriteComp,
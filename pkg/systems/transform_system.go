@@ -0,0 +1,38 @@
+package systems
+
+import (
+	"github.com/webbelito/Fenrir/pkg/ecs"
+)
+
+// TransformSystem derives the world Transform2D of child entities from their parent and LocalTransform2D.
+// It should run after the systems moving entities and before the CollisionSystem, so colliders and
+// the RenderSystem see the children where their parents are.
+type TransformSystem struct {
+	ecsManager *ecs.ECSManager
+	priority   int
+}
+
+func NewTransformSystem(ecsM *ecs.ECSManager, p int) *TransformSystem {
+	return &TransformSystem{
+		ecsManager: ecsM,
+		priority:   p,
+	}
+}
+
+func (ts *TransformSystem) Update(dt float64) {
+	ts.ecsManager.PropagateTransforms()
+}
+
+func (ts *TransformSystem) GetPriority() int {
+	return ts.priority
+}
+
+// GetReadComponents returns the component types the TransformSystem reads
+func (ts *TransformSystem) GetReadComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.HierarchyComponent, ecs.LocalTransform2DComponent}
+}
+
+// GetWriteComponents returns the component types the TransformSystem writes
+func (ts *TransformSystem) GetWriteComponents() []ecs.ComponentType {
+	return []ecs.ComponentType{ecs.Transform2DComponent}
+}
@@ -0,0 +1,46 @@
+package systems
+
+import (
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/events"
+	"github.com/webbelito/Fenrir/pkg/utils"
+)
+
+// TriggerSystem reacts to entities entering trigger colliders, e.g. dispatching the event of a SceneTrigger.
+// It only subscribes to the trigger events and doesn't need to be added as a logic system.
+type TriggerSystem struct {
+	ecsManager   *ecs.ECSManager
+	subscription events.SubscriptionID
+}
+
+func NewTriggerSystem(ecsM *ecs.ECSManager) *TriggerSystem {
+	ts := &TriggerSystem{
+		ecsManager: ecsM,
+	}
+
+	ts.subscription = events.Subscribe(ecsM.GetEventsManager(), ts.OnTriggerEnter)
+
+	return ts
+}
+
+// OnTriggerEnter dispatches the scene event of a SceneTrigger when a player enters it
+func (ts *TriggerSystem) OnTriggerEnter(e events.TriggerEnterEvent) {
+	sceneTrigger, sceneTriggerExists := ecs.Get[components.SceneTrigger](ts.ecsManager, e.Trigger)
+	if !sceneTriggerExists || !ecs.Has[components.Player](ts.ecsManager, e.Other) {
+		return
+	}
+
+	event, eventExists := events.NewSceneEvent(sceneTrigger.Event, sceneTrigger.ScenePath)
+	if !eventExists {
+		utils.WarnLogger.Printf("TriggerSystem: unknown event %s on entity %d", sceneTrigger.Event, e.Trigger)
+		return
+	}
+
+	ts.ecsManager.GetEventsManager().Dispatch(event)
+}
+
+// Close unsubscribes the TriggerSystem from the trigger events
+func (ts *TriggerSystem) Close() {
+	ts.ecsManager.GetEventsManager().Unsubscribe(ts.subscription)
+}
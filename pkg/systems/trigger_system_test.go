@@ -0,0 +1,51 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/webbelito/Fenrir/pkg/components"
+	"github.com/webbelito/Fenrir/pkg/ecs"
+	"github.com/webbelito/Fenrir/pkg/events"
+)
+
+// A player entering a SceneTrigger dispatches its scene event, other entities and other triggers don't,
+// and nothing is dispatched once the TriggerSystem is closed
+func TestTriggerSystemDispatchesSceneEvents(t *testing.T) {
+	em := ecs.NewECSManager()
+	ts := NewTriggerSystem(em)
+
+	scenes := []string{}
+	events.Subscribe(em.GetEventsManager(), func(e events.SceneChangeEvent) {
+		scenes = append(scenes, e.ScenePath)
+	})
+
+	door := em.CreateEntity().ID
+	ecs.Add(em, door, &components.SceneTrigger{Event: "change_scene", ScenePath: "next.json"})
+	plainTrigger := em.CreateEntity().ID
+
+	player := em.CreateEntity().ID
+	ecs.Add(em, player, &components.Player{Name: "Player"})
+	enemy := em.CreateEntity().ID
+
+	enter := func(trigger uint64, other uint64) {
+		em.GetEventsManager().Dispatch(events.TriggerEnterEvent{TriggerOverlap: events.TriggerOverlap{Trigger: trigger, Other: other}})
+	}
+
+	enter(door, enemy)
+	enter(plainTrigger, player)
+	em.GetEventsManager().Dispatch(events.TriggerExitEvent{TriggerOverlap: events.TriggerOverlap{Trigger: door, Other: player}})
+	if len(scenes) != 0 {
+		t.Fatalf("scene events = %v, want none", scenes)
+	}
+
+	enter(door, player)
+	if len(scenes) != 1 || scenes[0] != "next.json" {
+		t.Fatalf("scene events = %v, want the door's scene", scenes)
+	}
+
+	ts.Close()
+	enter(door, player)
+	if len(scenes) != 1 {
+		t.Fatalf("scene events = %v after Close, want no more", scenes)
+	}
+}
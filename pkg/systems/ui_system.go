@@ -2,6 +2,7 @@ package systems
 
 import (
 	"github.com/gen2brain/raylib-go/raygui"
+	raylib "github.com/gen2brain/raylib-go/raylib"
 	"github.com/webbelito/Fenrir/pkg/components"
 	"github.com/webbelito/Fenrir/pkg/ecs"
 	"github.com/webbelito/Fenrir/pkg/events"
@@ -36,6 +37,9 @@ func (us *UISystem) RenderUI() {
 
 func (us *UISystem) RenderUIComponents() {
 
+	// Render UI Overlays below everything else
+	us.RenderUIOverlays()
+
 	// Render UI Panels
 	us.RenderUIPanels()
 
@@ -47,19 +51,41 @@ func (us *UISystem) RenderUIComponents() {
 
 }
 
+func (us *UISystem) RenderUIOverlays() {
+
+	overlays := us.uiComponentsManager.GetEntitiesWithComponents([]ecs.UIComponentType{ecs.UIOverlayComponent})
+
+	for _, eID := range overlays {
+		overlay, overlayExists := ecs.GetUI[components.UIOverlay](us.ecsManager, eID)
+
+		if !overlayExists {
+			continue
+		}
+
+		if !overlay.IsVisible {
+			continue
+		}
+
+		screenWidth := int32(us.ecsManager.GetPlatform().GetScreenWidth())
+		screenHeight := int32(us.ecsManager.GetPlatform().GetScreenHeight())
+
+		raylib.DrawRectangle(0, 0, screenWidth, screenHeight, overlay.Color)
+
+	}
+
+}
+
 func (us *UISystem) RenderUIPanels() {
 
 	panels := us.uiComponentsManager.GetEntitiesWithComponents([]ecs.UIComponentType{ecs.UIPanelComponent})
 
 	for _, eID := range panels {
-		panelComp, panelCompExists := us.uiComponentsManager.GetComponent(eID, ecs.UIPanelComponent)
+		panel, panelExists := ecs.GetUI[components.UIPanel](us.ecsManager, eID)
 
-		if !panelCompExists {
+		if !panelExists {
 			continue
 		}
 
-		panel := panelComp.(*components.UIPanel)
-
 		if !panel.IsVisible {
 			continue
 		}
@@ -75,14 +101,12 @@ func (us *UISystem) RenderUIButtons() {
 	buttons := us.uiComponentsManager.GetEntitiesWithComponents([]ecs.UIComponentType{ecs.UIButtonComponent})
 
 	for _, eID := range buttons {
-		buttonComp, buttonCompExists := us.uiComponentsManager.GetComponent(eID, ecs.UIButtonComponent)
+		button, buttonExists := ecs.GetUI[components.UIButton](us.ecsManager, eID)
 
-		if !buttonCompExists {
+		if !buttonExists {
 			continue
 		}
 
-		button := buttonComp.(*components.UIButton)
-
 		if !button.IsVisible {
 			continue
 		}
@@ -100,14 +124,12 @@ func (us *UISystem) RenderUILabels() {
 	labels := us.uiComponentsManager.GetEntitiesWithComponents([]ecs.UIComponentType{ecs.UILabelComponent})
 
 	for _, eID := range labels {
-		labelComp, labelCompExists := us.uiComponentsManager.GetComponent(eID, ecs.UILabelComponent)
+		label, labelExists := ecs.GetUI[components.UILabel](us.ecsManager, eID)
 
-		if !labelCompExists {
+		if !labelExists {
 			continue
 		}
 
-		label := labelComp.(*components.UILabel)
-
 		if !label.IsVisible {
 			continue
 		}
@@ -1,59 +1,64 @@
 package utils
 
 import (
+	"fmt"
+
 	raylib "github.com/gen2brain/raylib-go/raylib"
 )
 
+// colorNames maps the color names used in scene files to raylib colors
+var colorNames = map[string]raylib.Color{
+	"white":      raylib.White,
+	"black":      raylib.Black,
+	"gray":       raylib.Gray,
+	"lightGray":  raylib.LightGray,
+	"darkGray":   raylib.DarkGray,
+	"yellow":     raylib.Yellow,
+	"gold":       raylib.Gold,
+	"orange":     raylib.Orange,
+	"pink":       raylib.Pink,
+	"red":        raylib.Red,
+	"maroon":     raylib.Maroon,
+	"green":      raylib.Green,
+	"lime":       raylib.Lime,
+	"darkGreen":  raylib.DarkGreen,
+	"skyBlue":    raylib.SkyBlue,
+	"blue":       raylib.Blue,
+	"darkBlue":   raylib.DarkBlue,
+	"purple":     raylib.Purple,
+	"violet":     raylib.Violet,
+	"darkPurple": raylib.DarkPurple,
+	"beige":      raylib.Beige,
+	"brown":      raylib.Brown,
+	"darkBrown":  raylib.DarkBrown,
+}
+
+// GetColorFromString returns the color for a color name or a hex string in the form #RRGGBBAA.
+// Unknown colors fall back to white.
 func GetColorFromString(cN string) raylib.Color {
 
-	switch cN {
-	case "white":
-		return raylib.White
-	case "black":
-		return raylib.Black
-	case "gray":
-		return raylib.Gray
-	case "lightGray":
-		return raylib.LightGray
-	case "darkGray":
-		return raylib.DarkGray
-	case "yellow":
-		return raylib.Yellow
-	case "gold":
-		return raylib.Gold
-	case "orange":
-		return raylib.Orange
-	case "pink":
-		return raylib.Pink
-	case "red":
-		return raylib.Red
-	case "maroon":
-		return raylib.Maroon
-	case "green":
-		return raylib.Green
-	case "lime":
-		return raylib.Lime
-	case "darkGreen":
-		return raylib.DarkGreen
-	case "skyBlue":
-		return raylib.SkyBlue
-	case "blue":
-		return raylib.Blue
-	case "darkBlue":
-		return raylib.DarkBlue
-	case "purple":
-		return raylib.Purple
-	case "violet":
-		return raylib.Violet
-	case "darkPurple":
-		return raylib.DarkPurple
-	case "beige":
-		return raylib.Beige
-	case "brown":
-		return raylib.Brown
-	case "darkBrown":
-		return raylib.DarkBrown
-	default:
-		return raylib.White
+	if color, colorExists := colorNames[cN]; colorExists {
+		return color
 	}
+
+	// Parse hex colors
+	var r, g, b, a uint8
+	if _, err := fmt.Sscanf(cN, "#%02x%02x%02x%02x", &r, &g, &b, &a); err == nil {
+		return raylib.NewColor(r, g, b, a)
+	}
+
+	return raylib.White
+}
+
+// GetStringFromColor returns the name of a color, or a hex string in the form #RRGGBBAA
+// if the color has no name. The result can be read back with GetColorFromString.
+func GetStringFromColor(c raylib.Color) string {
+
+	for name, color := range colorNames {
+		if color == c {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
 }